@@ -17,7 +17,7 @@ import (
 // Detail http handler
 // @Summary Returns a day diff metadata for namespace
 // @Tags diffs
-// @Description Includes identifiers, file sizes and other relevant metadata.
+// @Description Includes identifiers, file sizes, SHA-256 manifest of the archive and its ndjson files and other relevant metadata.
 // @ID v1-diffs-detail
 // @Security ApiKeyAuth
 // @Param date path string true "A datetime of diff (YYYY-MM-DD)"
@@ -0,0 +1,170 @@
+package exports
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"okapi-public-api/pkg/namespaces"
+	"okapi-public-api/schema/v3"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
+	"github.com/protsack-stephan/gin-toolkit/httperr"
+)
+
+const dateFormat = "2006-01-02"
+
+// chainRetentionDays number of days (today included) the diffs are kept for in the storage
+const chainRetentionDays = 14
+
+type chainStorage interface {
+	storage.Getter
+}
+
+// chainMeta read metadata file of the snapshot or diff, nil if it doesn't exist
+func chainMeta(store storage.Getter, path string) *schema.Project {
+	rc, err := store.Get(path)
+
+	if err != nil {
+		return nil
+	}
+
+	defer rc.Close()
+	meta := new(schema.Project)
+
+	if err := json.NewDecoder(rc).Decode(meta); err != nil {
+		return nil
+	}
+
+	return meta
+}
+
+// Chain http handler
+// @Summary Returns export snapshot and ordered list of diffs to rebuild the project namespace on a date
+// @Tags exports
+// @Description Snapshot is the one referenced in the diff metadata of the date, the latest dated export taken on or before that day. It is followed by the diffs of every day from the snapshot date up to the date, all of them reference the same snapshot (days without changes are skipped).
+// @Description Diffs are kept for 14 days, chains that need expired or missing diffs are not found, so every day missing in the chain had no changes.
+// @Description Apply the diffs in order on top of the snapshot replacing pages by identifier. Diff of the snapshot day is built after the day is over, so its pages are never older than the ones in the snapshot.
+// @Description Snapshots are downloaded from /v1/exports/snapshot/{date}/{namespace}/{project} and diffs from /v1/diffs/download/{date}/{namespace}/{project}.
+// @ID v1-exports-chain
+// @Security ApiKeyAuth
+// @Param namespace path number true "Pages namespace (currently supported 0, 6, 14)"
+// @Param project path string true "Project identifier"
+// @Param date query string false "Date to rebuild in YYYY-MM-DD, yesterday by default, can't be in the future"
+// @Success 200 {object} schema.Chain
+// @Failure 400 {object} httperr.Error
+// @Failure 404 {object} httperr.Error
+// @Router /v1/exports/chain/{namespace}/{project} [get]
+func Chain(store chainStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ns := c.Param("namespace")
+
+		if len(ns) > 0 && !namespaces.IsSupported(ns) {
+			httperr.BadRequest(c, fmt.Sprintf("Namespace '%s' not supported!", ns))
+			return
+		}
+
+		if len(ns) == 0 {
+			ns = defaultNs
+		}
+
+		dbName := c.Param("project")
+
+		if len(dbName) <= 1 || len(dbName) > 255 {
+			httperr.BadRequest(c)
+			return
+		}
+
+		date, err := time.Parse(dateFormat, c.DefaultQuery("date", time.Now().UTC().Add(-24*time.Hour).Format(dateFormat)))
+
+		if err != nil {
+			httperr.BadRequest(c, fmt.Sprintf("Date '%s' is not in YYYY-MM-DD format!", c.Query("date")))
+			return
+		}
+
+		today, _ := time.Parse(dateFormat, time.Now().UTC().Format(dateFormat))
+
+		if date.After(today) {
+			httperr.BadRequest(c, fmt.Sprintf("Date '%s' is in the future!", date.Format(dateFormat)))
+			return
+		}
+
+		// oldest day that still has diffs in the storage
+		oldest := today.Add(-24 * time.Hour * (chainRetentionDays - 1))
+
+		if date.Before(oldest) {
+			httperr.NotFound(c, fmt.Sprintf("Diffs are kept for %d days, date '%s' has expired!", chainRetentionDays, date.Format(dateFormat)))
+			return
+		}
+
+		chain := &schema.Chain{
+			Date:  date.Format(dateFormat),
+			Diffs: []*schema.ChainLink{},
+		}
+
+		diffMeta := func(day string) string {
+			return fmt.Sprintf("diff/%s/%s/%s_json_%s.json", day, dbName, dbName, ns)
+		}
+
+		last := chainMeta(store, diffMeta(chain.Date))
+
+		if last == nil {
+			httperr.NotFound(c, fmt.Sprintf("Diff of '%s' from '%s' not found!", dbName, chain.Date))
+			return
+		}
+
+		if last.Snapshot == nil {
+			httperr.NotFound(c, fmt.Sprintf("Snapshot of the '%s' diff from '%s' not found!", dbName, chain.Date))
+			return
+		}
+
+		day, err := time.Parse(dateFormat, last.Snapshot.Date)
+
+		if err != nil || day.After(date) {
+			httperr.InternalServerError(c, fmt.Sprintf("Diff of '%s' from '%s' has wrong snapshot date '%s'!", dbName, chain.Date, last.Snapshot.Date))
+			return
+		}
+
+		if day.Before(oldest) {
+			httperr.NotFound(c, fmt.Sprintf("Diffs after the snapshot of '%s' from '%s' have expired!", dbName, last.Snapshot.Date))
+			return
+		}
+
+		snapshot := chainMeta(store, fmt.Sprintf("snapshot/%s/%s/%s_%s.json", dbName, last.Snapshot.Date, dbName, ns))
+
+		if snapshot == nil {
+			httperr.NotFound(c, fmt.Sprintf("Snapshot of '%s' from '%s' not found!", dbName, last.Snapshot.Date))
+			return
+		}
+
+		chain.Snapshot = &schema.ChainLink{Date: last.Snapshot.Date, Meta: snapshot}
+
+		for ; !day.After(date); day = day.Add(24 * time.Hour) {
+			diff := day.Format(dateFormat)
+			meta := last
+
+			if diff != chain.Date {
+				meta = chainMeta(store, diffMeta(diff))
+			}
+
+			// every day is exported, missing meta means the chain can't be rebuilt
+			if meta == nil {
+				httperr.NotFound(c, fmt.Sprintf("Diff of '%s' from '%s' not found!", dbName, diff))
+				return
+			}
+
+			if meta.Snapshot == nil || meta.Snapshot.Date != chain.Snapshot.Date {
+				httperr.NotFound(c, fmt.Sprintf("Diff of '%s' from '%s' doesn't apply to the snapshot from '%s'!", dbName, diff, chain.Snapshot.Date))
+				return
+			}
+
+			// day without changes has no diff to download
+			if meta.Version != nil {
+				chain.Diffs = append(chain.Diffs, &schema.ChainLink{Date: diff, Meta: meta})
+			}
+		}
+
+		c.JSON(http.StatusOK, chain)
+	}
+}
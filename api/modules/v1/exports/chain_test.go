@@ -0,0 +1,182 @@
+package exports
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"okapi-public-api/schema/v3"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const chainTestDbName = "enwiki"
+const chainTestNs = "0"
+const chainTestMeta = `{"name":"Wikipedia","identifier":"enwiki","version":"5f4dcc3b5aa765d61d8327deb882cf99"}`
+const chainTestDiffMeta = `{"name":"Wikipedia","identifier":"enwiki","version":"5f4dcc3b5aa765d61d8327deb882cf99","snapshot":{"date":"%s"}}`
+const chainTestEmptyMeta = `{"identifier":"enwiki","snapshot":{"date":"%s"}}`
+
+var errChainTestNotFound = errors.New("key does not exist")
+
+type chainMockStorage struct {
+	files map[string]string
+}
+
+func (ms *chainMockStorage) Get(path string) (io.ReadCloser, error) {
+	if data, ok := ms.files[path]; ok {
+		return ioutil.NopCloser(strings.NewReader(data)), nil
+	}
+
+	return nil, errChainTestNotFound
+}
+
+func createChainTestServer(storage chainStorage) http.Handler {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Handle(http.MethodGet, "/:namespace/:project", Chain(storage))
+
+	return router
+}
+
+func TestChain(t *testing.T) {
+	assert := assert.New(t)
+	snapshot := func(date string) string {
+		return fmt.Sprintf("snapshot/%s/%s/%s_%s.json", chainTestDbName, date, chainTestDbName, chainTestNs)
+	}
+	diff := func(date string) string {
+		return fmt.Sprintf("diff/%s/%s/%s_json_%s.json", date, chainTestDbName, chainTestDbName, chainTestNs)
+	}
+	daysAgo := func(n int) string {
+		return time.Now().UTC().Add(time.Duration(-n) * 24 * time.Hour).Format(dateFormat)
+	}
+	get := func(srv *httptest.Server, date string) (*http.Response, error) {
+		return http.Get(fmt.Sprintf("%s/%s/%s?date=%s", srv.URL, chainTestNs, chainTestDbName, date))
+	}
+	newFiles := func() map[string]string {
+		return map[string]string{
+			snapshot(daysAgo(5)): chainTestMeta,
+			snapshot(daysAgo(3)): chainTestMeta,
+			diff(daysAgo(4)):     fmt.Sprintf(chainTestDiffMeta, daysAgo(5)),
+			diff(daysAgo(3)):     fmt.Sprintf(chainTestDiffMeta, daysAgo(3)),
+			diff(daysAgo(2)):     fmt.Sprintf(chainTestEmptyMeta, daysAgo(3)),
+			diff(daysAgo(1)):     fmt.Sprintf(chainTestDiffMeta, daysAgo(3)),
+		}
+	}
+	status := func(files map[string]string, date string) int {
+		srv := httptest.NewServer(createChainTestServer(&chainMockStorage{files: files}))
+		defer srv.Close()
+
+		res, err := get(srv, date)
+		assert.NoError(err)
+		defer res.Body.Close()
+
+		return res.StatusCode
+	}
+
+	t.Run("chain success", func(t *testing.T) {
+		srv := httptest.NewServer(createChainTestServer(&chainMockStorage{files: newFiles()}))
+		defer srv.Close()
+
+		res, err := get(srv, daysAgo(1))
+		assert.NoError(err)
+		defer res.Body.Close()
+		assert.Equal(http.StatusOK, res.StatusCode)
+
+		chain := new(schema.Chain)
+		assert.NoError(json.NewDecoder(res.Body).Decode(chain))
+		assert.Equal(daysAgo(1), chain.Date)
+		assert.Equal(daysAgo(3), chain.Snapshot.Date)
+		assert.Equal("5f4dcc3b5aa765d61d8327deb882cf99", *chain.Snapshot.Meta.Version)
+		assert.Len(chain.Diffs, 2)
+		assert.Equal(daysAgo(3), chain.Diffs[0].Date)
+		assert.Equal(daysAgo(1), chain.Diffs[1].Date)
+	})
+
+	t.Run("chain diff not found", func(t *testing.T) {
+		assert.Equal(http.StatusNotFound, status(newFiles(), daysAgo(0)))
+	})
+
+	t.Run("chain day missing", func(t *testing.T) {
+		files := newFiles()
+		delete(files, diff(daysAgo(2)))
+
+		assert.Equal(http.StatusNotFound, status(files, daysAgo(1)))
+	})
+
+	t.Run("chain diff without snapshot", func(t *testing.T) {
+		files := newFiles()
+		files[diff(daysAgo(1))] = chainTestMeta
+
+		assert.Equal(http.StatusNotFound, status(files, daysAgo(1)))
+	})
+
+	t.Run("chain diff of other snapshot", func(t *testing.T) {
+		files := newFiles()
+		files[diff(daysAgo(2))] = fmt.Sprintf(chainTestEmptyMeta, daysAgo(5))
+
+		assert.Equal(http.StatusNotFound, status(files, daysAgo(1)))
+	})
+
+	t.Run("chain snapshot not found", func(t *testing.T) {
+		files := newFiles()
+		delete(files, snapshot(daysAgo(3)))
+
+		assert.Equal(http.StatusNotFound, status(files, daysAgo(1)))
+	})
+
+	t.Run("chain snapshot expired", func(t *testing.T) {
+		files := map[string]string{
+			snapshot(daysAgo(chainRetentionDays)): chainTestMeta,
+			diff(daysAgo(1)):                      fmt.Sprintf(chainTestDiffMeta, daysAgo(chainRetentionDays)),
+		}
+
+		assert.Equal(http.StatusNotFound, status(files, daysAgo(1)))
+	})
+
+	t.Run("chain date expired", func(t *testing.T) {
+		srv := httptest.NewServer(createChainTestServer(&chainMockStorage{files: map[string]string{}}))
+		defer srv.Close()
+
+		res, err := get(srv, daysAgo(chainRetentionDays))
+		assert.NoError(err)
+		defer res.Body.Close()
+		assert.Equal(http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("chain date in the future", func(t *testing.T) {
+		srv := httptest.NewServer(createChainTestServer(&chainMockStorage{files: map[string]string{}}))
+		defer srv.Close()
+
+		res, err := get(srv, daysAgo(-1))
+		assert.NoError(err)
+		defer res.Body.Close()
+		assert.Equal(http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("chain date error", func(t *testing.T) {
+		srv := httptest.NewServer(createChainTestServer(&chainMockStorage{files: map[string]string{}}))
+		defer srv.Close()
+
+		res, err := http.Get(fmt.Sprintf("%s/%s/%s?date=yesterday", srv.URL, chainTestNs, chainTestDbName))
+		assert.NoError(err)
+		defer res.Body.Close()
+		assert.Equal(http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("chain namespace error", func(t *testing.T) {
+		srv := httptest.NewServer(createChainTestServer(&chainMockStorage{files: map[string]string{}}))
+		defer srv.Close()
+
+		res, err := http.Get(fmt.Sprintf("%s/%s/%s", srv.URL, "10", chainTestDbName))
+		assert.NoError(err)
+		defer res.Body.Close()
+		assert.Equal(http.StatusBadRequest, res.StatusCode)
+	})
+}
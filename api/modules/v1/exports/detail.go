@@ -17,11 +17,13 @@ import (
 // Detail http handler
 // @Summary Returns export metadata for namespace
 // @Tags exports
-// @Description Includes identifiers, file sizes and other relevant metadata.
+// @Description Includes identifiers, file sizes, SHA-256 manifest of the archive and its ndjson files and other relevant metadata.
 // @ID v1-exports-detail
 // @Security ApiKeyAuth
 // @Param namespace path number true "Pages namespace (currently supported 0, 6, 14)"
 // @Param project path string true "Project identifier"
+// @Param format query string false "Export format, ndjson (default) or parquet"
+// @Param profile query string false "Export profile, full (default), metadata, html, wikitext or text (ndjson format only)"
 // @Success 200 {object} schema.Project
 // @Failure 404 {object} httperr.Error
 // @Router /v1/exports/meta/{namespace}/{project} [get]
@@ -55,11 +57,18 @@ func Detail(storage storage.Getter, group string) gin.HandlerFunc {
 			return
 		}
 
-		path := fmt.Sprintf("export/%s/%s_%s.json", dbName, dbName, ns)
+		name, err := metaName(c, ns)
+
+		if err != nil {
+			httperr.BadRequest(c, err.Error())
+			return
+		}
+
+		path := fmt.Sprintf("export/%s/%s_%s", dbName, dbName, name)
 
 		for _, role := range user.GetGroups() {
 			if role == group {
-				path = fmt.Sprintf("export/%s/%s_%s_%s.json", dbName, dbName, group, ns)
+				path = fmt.Sprintf("export/%s/%s_%s_%s", dbName, dbName, group, name)
 			}
 		}
 
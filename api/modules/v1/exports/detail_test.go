@@ -97,6 +97,40 @@ func TestDetail(t *testing.T) {
 		assert.Equal(detailTestData, string(data))
 	})
 
+	t.Run("detail parquet success", func(t *testing.T) {
+		path := fmt.Sprintf("export/%s/%s_parquet_%s.json", detailTestDbName, detailTestDbName, detailTestNs)
+		store := new(detailMockStorage)
+		mw := setupDetailRBACMW("unlimited")
+		srv := httptest.NewServer(createDetailTestServer(mw, store, detailTestGroup))
+		defer srv.Close()
+		store.
+			On("Get", path).
+			Return(ioutil.NopCloser(strings.NewReader(detailTestData)), nil)
+
+		res, err := http.Get(fmt.Sprintf("%s/%s/%s?format=parquet", srv.URL, detailTestNs, detailTestDbName))
+		assert.NoError(err)
+		defer res.Body.Close()
+		assert.Equal(http.StatusOK, res.StatusCode)
+		store.AssertCalled(t, "Get", path)
+	})
+
+	t.Run("detail profile success", func(t *testing.T) {
+		path := fmt.Sprintf("export/%s/%s_wikitext_%s.json", detailTestDbName, detailTestDbName, detailTestNs)
+		store := new(detailMockStorage)
+		mw := setupDetailRBACMW("unlimited")
+		srv := httptest.NewServer(createDetailTestServer(mw, store, detailTestGroup))
+		defer srv.Close()
+		store.
+			On("Get", path).
+			Return(ioutil.NopCloser(strings.NewReader(detailTestData)), nil)
+
+		res, err := http.Get(fmt.Sprintf("%s/%s/%s?profile=wikitext", srv.URL, detailTestNs, detailTestDbName))
+		assert.NoError(err)
+		defer res.Body.Close()
+		assert.Equal(http.StatusOK, res.StatusCode)
+		store.AssertCalled(t, "Get", path)
+	})
+
 	t.Run("detail ns error", func(t *testing.T) {
 		store := new(detailMockStorage)
 		mw := setupDetailRBACMW("group_2")
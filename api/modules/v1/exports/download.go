@@ -56,11 +56,18 @@ func Download(storage downloadStorage, cType contenttype.ContentType, group stri
 			ns = defaultNs
 		}
 
-		path := fmt.Sprintf("export/%s/%s_%s_%s.tar.gz", dbName, dbName, cType, ns)
+		name, err := exportName(c, string(cType), ns)
+
+		if err != nil {
+			httperr.BadRequest(c, err.Error())
+			return
+		}
+
+		path := fmt.Sprintf("export/%s/%s_%s", dbName, dbName, name)
 
 		for _, role := range user.GetGroups() {
 			if role == group {
-				path = fmt.Sprintf("export/%s/%s_%s_%s_%s.tar.gz", dbName, dbName, group, cType, ns)
+				path = fmt.Sprintf("export/%s/%s_%s_%s", dbName, dbName, group, name)
 			}
 		}
 
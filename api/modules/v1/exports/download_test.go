@@ -116,6 +116,97 @@ func TestDownload(t *testing.T) {
 		assert.Equal(downloadTestRedirectURLGroup, res.Header.Get("Location"))
 	})
 
+	t.Run("download parquet success", func(t *testing.T) {
+		path := fmt.Sprintf("export/%s/%s_group_1_parquet_%s.parquet", downloadTestDbName, downloadTestDbName, downloadTestNs)
+		store := new(mockStorage)
+		mw := setupDownloadRBACMW("group_1")
+		srv := httptest.NewServer(createDownloadTestServer(mw, store, downloadTestGroup))
+		defer srv.Close()
+
+		store.On("Link", path).Return(
+			downloadTestRedirectURLGroup,
+			nil,
+		)
+		store.On("Stat", path).Return(nil)
+
+		client := &http.Client{
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+
+		res, err := client.Get(
+			fmt.Sprintf("%s/%s/%s?format=parquet", srv.URL, downloadTestNs, downloadTestDbName))
+		assert.NoError(err)
+		assert.Equal(http.StatusTemporaryRedirect, res.StatusCode)
+		assert.Equal(downloadTestRedirectURLGroup, res.Header.Get("Location"))
+	})
+
+	t.Run("download profile success", func(t *testing.T) {
+		path := fmt.Sprintf("export/%s/%s_group_1_%s_metadata_%s.tar.gz", downloadTestDbName, downloadTestDbName, downloadTestType, downloadTestNs)
+		store := new(mockStorage)
+		mw := setupDownloadRBACMW("group_1")
+		srv := httptest.NewServer(createDownloadTestServer(mw, store, downloadTestGroup))
+		defer srv.Close()
+
+		store.On("Link", path).Return(
+			downloadTestRedirectURLGroup,
+			nil,
+		)
+		store.On("Stat", path).Return(nil)
+
+		client := &http.Client{
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+
+		res, err := client.Get(
+			fmt.Sprintf("%s/%s/%s?profile=metadata", srv.URL, downloadTestNs, downloadTestDbName))
+		assert.NoError(err)
+		assert.Equal(http.StatusTemporaryRedirect, res.StatusCode)
+		assert.Equal(downloadTestRedirectURLGroup, res.Header.Get("Location"))
+	})
+
+	t.Run("download unknown profile error", func(t *testing.T) {
+		store := new(mockStorage)
+		mw := setupDownloadRBACMW("unlimited")
+		srv := httptest.NewServer(createDownloadTestServer(mw, store, downloadTestGroup))
+		defer srv.Close()
+
+		res, err := http.Get(fmt.Sprintf("%s/%s/%s?profile=images", srv.URL, downloadTestNs, downloadTestDbName))
+		assert.NoError(err)
+		defer res.Body.Close()
+		assert.Equal(http.StatusBadRequest, res.StatusCode)
+		store.AssertNotCalled(t, "Stat", mock.Anything)
+	})
+
+	t.Run("download parquet profile error", func(t *testing.T) {
+		store := new(mockStorage)
+		mw := setupDownloadRBACMW("unlimited")
+		srv := httptest.NewServer(createDownloadTestServer(mw, store, downloadTestGroup))
+		defer srv.Close()
+
+		res, err := http.Get(fmt.Sprintf("%s/%s/%s?format=parquet&profile=html", srv.URL, downloadTestNs, downloadTestDbName))
+		assert.NoError(err)
+		defer res.Body.Close()
+		assert.Equal(http.StatusBadRequest, res.StatusCode)
+		store.AssertNotCalled(t, "Stat", mock.Anything)
+	})
+
+	t.Run("download unknown format error", func(t *testing.T) {
+		store := new(mockStorage)
+		mw := setupDownloadRBACMW("unlimited")
+		srv := httptest.NewServer(createDownloadTestServer(mw, store, downloadTestGroup))
+		defer srv.Close()
+
+		res, err := http.Get(fmt.Sprintf("%s/%s/%s?format=csv", srv.URL, downloadTestNs, downloadTestDbName))
+		assert.NoError(err)
+		defer res.Body.Close()
+		assert.Equal(http.StatusBadRequest, res.StatusCode)
+		store.AssertNotCalled(t, "Stat", mock.Anything)
+	})
+
 	t.Run("download ns success", func(t *testing.T) {
 		path := fmt.Sprintf("export/%s/%s_%s_%s.tar.gz", downloadTestDbName, downloadTestDbName, downloadTestType, downloadTestNs)
 		store := new(mockStorage)
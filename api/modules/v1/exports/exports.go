@@ -57,6 +57,16 @@ func Init() httpmod.Module {
 				Method:  http.MethodGet,
 				Handler: Detail(store, env.Group),
 			},
+			{
+				Path:    "/chain/:namespace/:project",
+				Method:  http.MethodGet,
+				Handler: Chain(store),
+			},
+			{
+				Path:    "/snapshot/:date/:namespace/:project",
+				Method:  http.MethodGet,
+				Handler: Snapshot(store),
+			},
 		},
 	}
 }
@@ -0,0 +1,104 @@
+package exports
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Export formats selected with the `format` query parameter
+const (
+	formatNDJSON  = "ndjson"
+	formatParquet = "parquet"
+)
+
+// Export profiles selected with the `profile` query parameter, each one is a different set of page fields
+const (
+	profileFull     = "full"
+	profileMetadata = "metadata"
+	profileHTML     = "html"
+	profileWikitext = "wikitext"
+	profileText     = "text"
+)
+
+var profiles = map[string]bool{
+	profileFull:     true,
+	profileMetadata: true,
+	profileHTML:     true,
+	profileWikitext: true,
+	profileText:     true,
+}
+
+// errUnknownFormat requested export format is not produced
+var errUnknownFormat = errors.New("unknown export format, available formats: 'ndjson', 'parquet'")
+
+// errUnknownProfile requested export profile is not produced
+var errUnknownProfile = errors.New("unknown export profile, available profiles: 'full', 'metadata', 'html', 'wikitext', 'text'")
+
+// errProfileFormat profiles other than full are produced only for ndjson exports
+var errProfileFormat = errors.New("export profiles are only available for ndjson format")
+
+// exportProfile requested export profile, empty for the full profile (published without the profile in the name)
+func exportProfile(c *gin.Context) (string, error) {
+	profile := c.DefaultQuery("profile", profileFull)
+
+	if !profiles[profile] {
+		return "", errUnknownProfile
+	}
+
+	if profile == profileFull {
+		return "", nil
+	}
+
+	if c.DefaultQuery("format", formatNDJSON) != formatNDJSON {
+		return "", errProfileFormat
+	}
+
+	return profile, nil
+}
+
+// exportName name of the export file (without the project prefix) for requested format,
+// ndjson exports are tar.gz archives per content type and parquet exports hold all of the content in one file
+func exportName(c *gin.Context, kind string, ns string) (string, error) {
+	profile, err := exportProfile(c)
+
+	if err != nil {
+		return "", err
+	}
+
+	switch c.DefaultQuery("format", formatNDJSON) {
+	case formatNDJSON:
+		if len(profile) > 0 {
+			return fmt.Sprintf("%s_%s_%s.tar.gz", kind, profile, ns), nil
+		}
+
+		return fmt.Sprintf("%s_%s.tar.gz", kind, ns), nil
+	case formatParquet:
+		return fmt.Sprintf("%s_%s.parquet", formatParquet, ns), nil
+	default:
+		return "", errUnknownFormat
+	}
+}
+
+// metaName name of the export metadata file (without the project prefix) for requested format
+func metaName(c *gin.Context, ns string) (string, error) {
+	profile, err := exportProfile(c)
+
+	if err != nil {
+		return "", err
+	}
+
+	switch c.DefaultQuery("format", formatNDJSON) {
+	case formatNDJSON:
+		if len(profile) > 0 {
+			return fmt.Sprintf("%s_%s.json", profile, ns), nil
+		}
+
+		return fmt.Sprintf("%s.json", ns), nil
+	case formatParquet:
+		return fmt.Sprintf("%s_%s.json", formatParquet, ns), nil
+	default:
+		return "", errUnknownFormat
+	}
+}
@@ -23,6 +23,8 @@ import (
 // @Param date path string true "Date of the diff in YYYY-MM-DD"
 // @Param project path string true "Project identifier"
 // @Param namespace path number true "Pages namespace (currently supported 0, 6, 14)"
+// @Param format query string false "Export format, ndjson (default) or parquet"
+// @Param profile query string false "Export profile, full (default), metadata, html, wikitext or text (ndjson format only)"
 // @Success 200
 // @Failure 400 {object} httperr.Error
 // @Failure 404 {object} httperr.Error
@@ -59,11 +61,18 @@ func Head(storage storage.Stater, cType contenttype.ContentType, group string) g
 			return
 		}
 
-		path := fmt.Sprintf("export/%s/%s_%s_%s.tar.gz", dbName, dbName, cType, ns)
+		name, err := exportName(c, string(cType), ns)
+
+		if err != nil {
+			httperr.BadRequest(c, err.Error())
+			return
+		}
+
+		path := fmt.Sprintf("export/%s/%s_%s", dbName, dbName, name)
 
 		for _, role := range user.GetGroups() {
 			if role == group {
-				path = fmt.Sprintf("export/%s/%s_%s_%s_%s.tar.gz", dbName, dbName, group, cType, ns)
+				path = fmt.Sprintf("export/%s/%s_%s_%s", dbName, dbName, group, name)
 			}
 		}
 
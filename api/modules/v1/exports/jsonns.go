@@ -16,6 +16,8 @@ import (
 // @Security ApiKeyAuth
 // @Param namespace path number true "Pages namespace (currently supported 0, 6, 14)"
 // @Param project path string true "Project identifier"
+// @Param format query string false "Export format, ndjson (default) or parquet"
+// @Param profile query string false "Export profile, full (default), metadata, html, wikitext or text (ndjson format only)"
 // @Success 307 string nil "Redirects to the direct download URL"
 // @Failure 400 {object} httperr.Error
 // @Failure 404 {object} httperr.Error
@@ -0,0 +1,70 @@
+package exports
+
+import (
+	"fmt"
+	"net/http"
+	"okapi-public-api/pkg/namespaces"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/protsack-stephan/gin-toolkit/httperr"
+)
+
+// Snapshot http handler
+// @Summary Returns dated snapshot of the project export in specified namespace
+// @Tags exports
+// @Description Snapshots are kept for a limited number of days and serve as a base for the diffs listed by /v1/exports/chain/{namespace}/{project}.
+// @ID v1-exports-snapshot
+// @Security ApiKeyAuth
+// @Param date path string true "Date of the snapshot in YYYY-MM-DD"
+// @Param namespace path number true "Pages namespace (currently supported 0, 6, 14)"
+// @Param project path string true "Project identifier"
+// @Success 307 string nil "Redirects to the direct download URL"
+// @Failure 400 {object} httperr.Error
+// @Failure 404 {object} httperr.Error
+// @Router /v1/exports/snapshot/{date}/{namespace}/{project} [get]
+func Snapshot(storage downloadStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		date := c.Param("date")
+
+		if _, err := time.Parse(dateFormat, date); err != nil {
+			httperr.BadRequest(c)
+			return
+		}
+
+		dbName := c.Param("project")
+
+		if len(dbName) <= 1 || len(dbName) > 255 {
+			httperr.BadRequest(c)
+			return
+		}
+
+		ns := c.Param("namespace")
+
+		if len(ns) <= 0 {
+			ns = defaultNs
+		} else if !namespaces.IsSupported(ns) {
+			httperr.BadRequest(c, fmt.Sprintf("Namespace '%s' not supported!", ns))
+			return
+		}
+
+		path := fmt.Sprintf("snapshot/%s/%s/%s_json_%s.tar.gz", dbName, date, dbName, ns)
+
+		if _, err := storage.Stat(path); err != nil {
+			httperr.NotFound(c, fmt.Sprintf("Snapshot from '%s' for '%s' not found!", date, dbName))
+			return
+		}
+
+		url, err := storage.Link(
+			path,
+			10*time.Second,
+		)
+
+		if err != nil {
+			httperr.InternalServerError(c, err.Error())
+			return
+		}
+
+		c.Redirect(http.StatusTemporaryRedirect, url)
+	}
+}
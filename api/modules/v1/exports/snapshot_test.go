@@ -0,0 +1,77 @@
+package exports
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const snapshotTestDbName = "enwiki"
+const snapshotTestDate = "2021-09-05"
+const snapshotTestNs = "0"
+const snapshotTestRedirectURL = "http://test/snapshot/enwiki/2021-09-05/enwiki_json_0.tar.gz"
+
+func createSnapshotTestServer(storage downloadStorage) http.Handler {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Handle(http.MethodGet, "/:date/:namespace/:project", Snapshot(storage))
+
+	return router
+}
+
+func TestSnapshot(t *testing.T) {
+	assert := assert.New(t)
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	t.Run("snapshot success", func(t *testing.T) {
+		path := fmt.Sprintf("snapshot/%s/%s/%s_json_%s.tar.gz", snapshotTestDbName, snapshotTestDate, snapshotTestDbName, snapshotTestNs)
+		store := new(mockStorage)
+		srv := httptest.NewServer(createSnapshotTestServer(store))
+		defer srv.Close()
+
+		store.On("Stat", path).Return(nil)
+		store.On("Link", path).Return(snapshotTestRedirectURL, nil)
+
+		res, err := client.Get(fmt.Sprintf("%s/%s/%s/%s", srv.URL, snapshotTestDate, snapshotTestNs, snapshotTestDbName))
+		assert.NoError(err)
+		defer res.Body.Close()
+		assert.Equal(http.StatusTemporaryRedirect, res.StatusCode)
+		assert.Equal(snapshotTestRedirectURL, res.Header.Get("Location"))
+	})
+
+	t.Run("snapshot not found", func(t *testing.T) {
+		path := fmt.Sprintf("snapshot/%s/%s/%s_json_%s.tar.gz", snapshotTestDbName, snapshotTestDate, snapshotTestDbName, snapshotTestNs)
+		store := new(mockStorage)
+		srv := httptest.NewServer(createSnapshotTestServer(store))
+		defer srv.Close()
+
+		store.On("Stat", path).Return(errors.New("key does not exist"))
+
+		res, err := client.Get(fmt.Sprintf("%s/%s/%s/%s", srv.URL, snapshotTestDate, snapshotTestNs, snapshotTestDbName))
+		assert.NoError(err)
+		defer res.Body.Close()
+		assert.Equal(http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("snapshot date error", func(t *testing.T) {
+		store := new(mockStorage)
+		srv := httptest.NewServer(createSnapshotTestServer(store))
+		defer srv.Close()
+
+		res, err := client.Get(fmt.Sprintf("%s/%s/%s/%s", srv.URL, "05-09-2021", snapshotTestNs, snapshotTestDbName))
+		assert.NoError(err)
+		defer res.Body.Close()
+		assert.Equal(http.StatusBadRequest, res.StatusCode)
+		store.AssertNotCalled(t, "Stat", mock.Anything)
+	})
+}
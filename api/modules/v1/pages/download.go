@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"okapi-public-api/pkg/compress"
 	"okapi-public-api/pkg/contenttype"
+	"okapi-public-api/pkg/titlepath"
 	"okapi-public-api/schema/v3"
 	"strings"
 
@@ -31,13 +33,21 @@ func Download(storage storage.Getter, cType contenttype.ContentType) gin.Handler
 			return
 		}
 
-		rc, err := storage.Get(fmt.Sprintf("page/json/%s/%s.json", dbName, name))
+		// catch all route param starts with slash, the rest of the slashes are part of the title
+		file, err := storage.Get(fmt.Sprintf("page/json/%s/%s.json", dbName, titlepath.Encode(strings.TrimPrefix(name, "/"))))
 
 		if err != nil {
 			httperr.NotFound(c, err.Error())
 			return
 		}
 
+		rc, err := compress.NewReader(file)
+
+		if err != nil {
+			httperr.InternalServerError(c, err.Error())
+			return
+		}
+
 		defer rc.Close()
 
 		if cType == contenttype.JSON {
@@ -1,6 +1,8 @@
 package pages
 
 import (
+	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
@@ -32,6 +34,14 @@ func (s *pageStorageMock) Get(path string) (io.ReadCloser, error) {
 	return ioutil.NopCloser(strings.NewReader(downloadTestData)), args.Error(0)
 }
 
+type pageGzipStorageMock struct {
+	data []byte
+}
+
+func (s *pageGzipStorageMock) Get(_ string) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(s.data)), nil
+}
+
 func createPageServer(store storage.Getter, cType contenttype.ContentType) http.Handler {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
@@ -118,4 +128,44 @@ func TestDownload(t *testing.T) {
 		assert.NoError(err)
 		assert.Contains(string(data), error.Error())
 	})
+
+	t.Run("subpage title", func(t *testing.T) {
+		store := new(pageStorageMock)
+		store.On("Get", fmt.Sprintf("page/json/%s/%s.json", downloadTestDbName, "AC%2FDC")).Return(nil)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Handle(http.MethodGet, "/:project/*name", Download(store, contenttype.JSON))
+
+		srv := httptest.NewServer(router)
+		defer srv.Close()
+
+		res, err := http.Get(fmt.Sprintf("%s/%s/AC/DC", srv.URL, downloadTestDbName))
+		assert.NoError(err)
+
+		defer res.Body.Close()
+		assert.Equal(http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("compressed page", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		gzw := gzip.NewWriter(buf)
+		_, err := gzw.Write([]byte(downloadTestData))
+		assert.NoError(err)
+		assert.NoError(gzw.Close())
+
+		store := &pageGzipStorageMock{data: buf.Bytes()}
+		srv := httptest.NewServer(createPageServer(store, contenttype.HTML))
+		defer srv.Close()
+
+		res, err := http.Get(fmt.Sprintf("%s/%s", srv.URL, url))
+		assert.NoError(err)
+
+		defer res.Body.Close()
+		assert.Equal(http.StatusOK, res.StatusCode)
+
+		data, err := ioutil.ReadAll(res.Body)
+		assert.NoError(err)
+		assert.Equal(downloadTestHTML, string(data))
+	})
 }
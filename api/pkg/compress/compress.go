@@ -0,0 +1,47 @@
+// Package compress reading of page json files compressed by the data service.
+// Encoding is detected from the magic bytes of the data, so compressed and old uncompressed files can be mixed.
+package compress
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (rc *readCloser) Close() error {
+	var err error
+
+	for _, closer := range rc.closers {
+		if cerr := closer.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// NewReader detect the encoding of the stream and decode it, uncompressed data is read as is
+func NewReader(rc io.ReadCloser) (io.ReadCloser, error) {
+	brd := bufio.NewReader(rc)
+
+	if magic, err := brd.Peek(len(gzipMagic)); err != nil || !bytes.Equal(magic, gzipMagic) {
+		return &readCloser{brd, []io.Closer{rc}}, nil
+	}
+
+	gzr, err := gzip.NewReader(brd)
+
+	if err != nil {
+		_ = rc.Close()
+		return nil, err
+	}
+
+	return &readCloser{gzr, []io.Closer{gzr, rc}}, nil
+}
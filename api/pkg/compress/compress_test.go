@@ -0,0 +1,36 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const compressTestData = `{"name":"Earth","article_body":{"html":"<p>Earth</p>"}}`
+
+func TestNewReader(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := new(bytes.Buffer)
+	gzw := gzip.NewWriter(buf)
+	_, err := gzw.Write([]byte(compressTestData))
+	assert.NoError(err)
+	assert.NoError(gzw.Close())
+
+	for _, data := range []string{buf.String(), compressTestData} {
+		rc, err := NewReader(ioutil.NopCloser(strings.NewReader(data)))
+		assert.NoError(err)
+
+		decoded, err := ioutil.ReadAll(rc)
+		assert.NoError(err)
+		assert.NoError(rc.Close())
+		assert.Equal(compressTestData, string(decoded))
+	}
+
+	_, err = NewReader(ioutil.NopCloser(bytes.NewReader([]byte{0x1f, 0x8b, 0x00})))
+	assert.Error(err)
+}
@@ -0,0 +1,11 @@
+package schema
+
+import "time"
+
+// Quarantine decision of the project publishing policy for the revision
+type Quarantine struct {
+	Held         bool       `json:"held"`
+	Reasons      []string   `json:"reasons,omitempty"`
+	DateHeld     *time.Time `json:"date_held,omitempty"`
+	DateReleased *time.Time `json:"date_released,omitempty"`
+}
@@ -0,0 +1,20 @@
+package schema
+
+// Snapshot dated copy of the project export that diffs are applied to
+type Snapshot struct {
+	Date    string `json:"date"`
+	Version string `json:"version"`
+}
+
+// ChainLink metadata of the dated export snapshot or diff
+type ChainLink struct {
+	Date string   `json:"date"`
+	Meta *Project `json:"meta"`
+}
+
+// Chain export snapshot and ordered diffs that rebuild the project on the date
+type Chain struct {
+	Date     string       `json:"date"`
+	Snapshot *ChainLink   `json:"snapshot"`
+	Diffs    []*ChainLink `json:"diffs"`
+}
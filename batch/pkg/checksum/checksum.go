@@ -0,0 +1,44 @@
+// Package checksum sha256 sums and line counts of the files listed in export and diff manifests.
+// Keep in sync with the copy in the data service.
+package checksum
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+)
+
+// Writer computes sha256 sum, size and number of lines of the data written into it
+type Writer struct {
+	hash  hash.Hash
+	size  int64
+	lines int
+}
+
+// NewWriter create new checksum writer
+func NewWriter() *Writer {
+	return &Writer{hash: sha256.New()}
+}
+
+// Write add data to the sum
+func (w *Writer) Write(p []byte) (int, error) {
+	w.size += int64(len(p))
+	w.lines += bytes.Count(p, []byte("\n"))
+	return w.hash.Write(p)
+}
+
+// Sum hex encoded sha256 of the data
+func (w *Writer) Sum() string {
+	return hex.EncodeToString(w.hash.Sum(nil))
+}
+
+// Size number of bytes written
+func (w *Writer) Size() int64 {
+	return w.size
+}
+
+// Lines number of new line characters written
+func (w *Writer) Lines() int {
+	return w.lines
+}
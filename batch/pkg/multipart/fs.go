@@ -0,0 +1,135 @@
+package multipart
+
+import (
+	"crypto/md5" // #nosec G501
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// fsUploads directory inside of the volume where parts are kept until the upload is completed
+const fsUploads = ".multipart"
+
+// FS filesystem stand-in for the remote multipart uploads (local development and tests)
+type FS struct {
+	vol string
+}
+
+// NewFS create multipart uploader for the volume
+func NewFS(vol string) *FS {
+	return &FS{vol}
+}
+
+// Start create new multipart upload
+func (f *FS) Start(_ string) (string, error) {
+	buf := make([]byte, 16)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	id := hex.EncodeToString(buf)
+	return id, os.MkdirAll(f.dir(id), 0766)
+}
+
+// Upload save single part of the upload
+func (f *FS) Upload(_ string, id string, num int, body io.ReadSeeker) (string, error) {
+	if _, err := os.Stat(f.dir(id)); err != nil {
+		return "", ErrNoSuchUpload
+	}
+
+	data, err := ioutil.ReadAll(body)
+
+	if err != nil {
+		return "", err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(f.dir(id), strconv.Itoa(num)), data, 0644); err != nil {
+		return "", err
+	}
+
+	return etag(data), nil
+}
+
+// List get etags of uploaded parts by part number
+func (f *FS) List(_ string, id string) (map[int]string, error) {
+	files, err := ioutil.ReadDir(f.dir(id))
+
+	if err != nil {
+		return nil, ErrNoSuchUpload
+	}
+
+	etags := map[int]string{}
+
+	for _, file := range files {
+		num, err := strconv.Atoi(file.Name())
+
+		if err != nil {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(f.dir(id), file.Name()))
+
+		if err != nil {
+			return nil, err
+		}
+
+		etags[num] = etag(data)
+	}
+
+	return etags, nil
+}
+
+// Complete assemble the file out of the parts
+func (f *FS) Complete(path string, id string, parts []*Part) error {
+	dest := filepath.Join(f.vol, path)
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0766); err != nil {
+		return err
+	}
+
+	file, err := os.Create(dest)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	for _, part := range parts {
+		data, err := ioutil.ReadFile(filepath.Join(f.dir(id), strconv.Itoa(part.Number)))
+
+		if err != nil {
+			return err
+		}
+
+		if etag(data) != part.ETag {
+			return fmt.Errorf("part %d etag mismatch", part.Number)
+		}
+
+		if _, err := file.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return os.RemoveAll(f.dir(id))
+}
+
+// Abort cancel the upload and drop uploaded parts
+func (f *FS) Abort(_ string, id string) error {
+	return os.RemoveAll(f.dir(id))
+}
+
+func (f *FS) dir(id string) string {
+	return filepath.Join(f.vol, fsUploads, id)
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data) // #nosec G401
+	return fmt.Sprintf("\"%s\"", hex.EncodeToString(sum[:]))
+}
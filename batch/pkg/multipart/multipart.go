@@ -0,0 +1,216 @@
+// Package multipart streaming of large files into remote storage as multipart uploads.
+// Progress of the upload is tracked, so interrupted upload of the same (reproducible) data skips the parts that were already sent.
+// Keep in sync with the copy in the data service.
+package multipart
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
+)
+
+// MinPartSize smallest part size remote storage accepts (only the last part can be smaller)
+const MinPartSize = 5 * 1024 * 1024
+
+// DefaultPartSize size of the parts uploaded by the writer
+const DefaultPartSize = 64 * 1024 * 1024
+
+// ErrNoSuchUpload upload with such id doesn't exist (completed, aborted or expired)
+var ErrNoSuchUpload = errors.New("no such upload")
+
+// Part uploaded part of the file
+type Part struct {
+	Number int    `json:"number"`
+	ETag   string `json:"etag"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// State progress of the upload saved after each part
+type State struct {
+	UploadID string  `json:"upload_id"`
+	Parts    []*Part `json:"parts"`
+}
+
+// Uploader storage that accepts files in parts
+type Uploader interface {
+	Start(path string) (string, error)
+	Upload(path string, id string, num int, body io.ReadSeeker) (string, error)
+	List(path string, id string) (map[int]string, error)
+	Complete(path string, id string, parts []*Part) error
+	Abort(path string, id string) error
+}
+
+// Tracker storage for the upload state
+type Tracker interface {
+	storage.Getter
+	storage.Putter
+	storage.Deleter
+}
+
+// Writer buffers written data and uploads it part by part, the upload is finished on Close
+type Writer struct {
+	PartSize int
+	up       Uploader
+	tracker  Tracker
+	path     string
+	state    *State
+	buf      *bytes.Buffer
+	num      int
+	reused   int
+}
+
+// NewWriter continue tracked upload of the path or start a new one
+func NewWriter(up Uploader, tracker Tracker, path string) (*Writer, error) {
+	w := &Writer{
+		PartSize: DefaultPartSize,
+		up:       up,
+		tracker:  tracker,
+		path:     path,
+		buf:      new(bytes.Buffer),
+		num:      1,
+	}
+
+	if state, err := w.load(); err == nil && len(state.UploadID) > 0 {
+		if etags, err := up.List(path, state.UploadID); err == nil {
+			parts := []*Part{}
+
+			// only continuous run of parts still known to the storage can be reused
+			for i, part := range state.Parts {
+				if part.Number != i+1 || etags[part.Number] != part.ETag {
+					break
+				}
+
+				parts = append(parts, part)
+			}
+
+			state.Parts = parts
+			w.state = state
+			return w, nil
+		}
+	}
+
+	id, err := up.Start(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	w.state = &State{UploadID: id, Parts: []*Part{}}
+	return w, w.save()
+}
+
+// Write buffer the data and upload full parts
+func (w *Writer) Write(p []byte) (int, error) {
+	n, _ := w.buf.Write(p)
+
+	for w.buf.Len() >= w.PartSize {
+		if err := w.flush(w.buf.Next(w.PartSize)); err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// Close upload the rest of the data and complete the upload
+func (w *Writer) Close() error {
+	if w.buf.Len() > 0 || w.num == 1 {
+		if err := w.flush(w.buf.Bytes()); err != nil {
+			return err
+		}
+
+		w.buf.Reset()
+	}
+
+	if err := w.up.Complete(w.path, w.state.UploadID, w.state.Parts[:w.num-1]); err != nil {
+		return err
+	}
+
+	return w.tracker.Delete(w.trackerPath())
+}
+
+// Abort cancel the upload and forget its state
+func (w *Writer) Abort() error {
+	if err := w.up.Abort(w.path, w.state.UploadID); err != nil {
+		return err
+	}
+
+	return w.tracker.Delete(w.trackerPath())
+}
+
+// Reused number of parts that were uploaded by the previous attempt and skipped
+func (w *Writer) Reused() int {
+	return w.reused
+}
+
+func (w *Writer) flush(data []byte) error {
+	sum := sha256.Sum256(data)
+	part := &Part{
+		Number: w.num,
+		SHA256: hex.EncodeToString(sum[:]),
+		Size:   int64(len(data)),
+	}
+
+	if idx := w.num - 1; idx < len(w.state.Parts) {
+		if prev := w.state.Parts[idx]; prev.SHA256 == part.SHA256 && prev.Size == part.Size {
+			w.num++
+			w.reused++
+			return nil
+		}
+
+		// data differs from the previous attempt, so the rest of tracked parts can't be reused
+		w.state.Parts = w.state.Parts[:idx]
+	}
+
+	etag, err := w.up.Upload(w.path, w.state.UploadID, part.Number, bytes.NewReader(data))
+
+	if err != nil {
+		return err
+	}
+
+	part.ETag = etag
+	w.state.Parts = append(w.state.Parts, part)
+	w.num++
+
+	return w.save()
+}
+
+func (w *Writer) trackerPath() string {
+	return fmt.Sprintf("multipart/%s.json", w.path)
+}
+
+func (w *Writer) load() (*State, error) {
+	rc, err := w.tracker.Get(w.trackerPath())
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	state := new(State)
+	return state, json.Unmarshal(data, state)
+}
+
+func (w *Writer) save() error {
+	data, err := json.Marshal(w.state)
+
+	if err != nil {
+		return err
+	}
+
+	return w.tracker.Put(w.trackerPath(), bytes.NewReader(data))
+}
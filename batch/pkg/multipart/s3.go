@@ -0,0 +1,112 @@
+package multipart
+
+import (
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// S3 multipart uploads of the s3 bucket
+type S3 struct {
+	client s3iface.S3API
+	bucket string
+}
+
+// NewS3 create multipart uploader for the bucket
+func NewS3(client s3iface.S3API, bucket string) *S3 {
+	return &S3{client, bucket}
+}
+
+// Start create new multipart upload
+func (s *S3) Start(path string) (string, error) {
+	out, err := s.client.CreateMultipartUpload(&s3.CreateMultipartUploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(path),
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(out.UploadId), nil
+}
+
+// Upload send single part of the upload
+func (s *S3) Upload(path string, id string, num int, body io.ReadSeeker) (string, error) {
+	out, err := s.client.UploadPart(&s3.UploadPartInput{
+		Bucket:     aws.String(s.bucket),
+		Key:        aws.String(path),
+		UploadId:   aws.String(id),
+		PartNumber: aws.Int64(int64(num)),
+		Body:       body,
+	})
+
+	if err != nil {
+		return "", s.err(err)
+	}
+
+	return aws.StringValue(out.ETag), nil
+}
+
+// List get etags of uploaded parts by part number
+func (s *S3) List(path string, id string) (map[int]string, error) {
+	etags := map[int]string{}
+	input := &s3.ListPartsInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(path),
+		UploadId: aws.String(id),
+	}
+
+	err := s.client.ListPartsPages(input, func(out *s3.ListPartsOutput, _ bool) bool {
+		for _, part := range out.Parts {
+			etags[int(aws.Int64Value(part.PartNumber))] = aws.StringValue(part.ETag)
+		}
+
+		return true
+	})
+
+	return etags, s.err(err)
+}
+
+// Complete assemble the file out of the parts
+func (s *S3) Complete(path string, id string, parts []*Part) error {
+	completed := []*s3.CompletedPart{}
+
+	for _, part := range parts {
+		completed = append(completed, &s3.CompletedPart{
+			ETag:       aws.String(part.ETag),
+			PartNumber: aws.Int64(int64(part.Number)),
+		})
+	}
+
+	_, err := s.client.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(path),
+		UploadId:        aws.String(id),
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: completed},
+	})
+
+	return s.err(err)
+}
+
+// Abort cancel the upload and drop uploaded parts
+func (s *S3) Abort(path string, id string) error {
+	_, err := s.client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(path),
+		UploadId: aws.String(id),
+	})
+
+	return s.err(err)
+}
+
+func (s *S3) err(err error) error {
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchUpload {
+		return ErrNoSuchUpload
+	}
+
+	return err
+}
@@ -0,0 +1,32 @@
+package titlepath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var titlepathTestCases = map[string]string{
+	"Earth":              "Earth",
+	"AC/DC":              "AC%2FDC",
+	"Talk:Foo/Bar/Baz":   "Talk:Foo%2FBar%2FBaz",
+	"100%_Pure":          "100%25_Pure",
+	`C:\Windows`:         `C:%5CWindows`,
+	"Ünïcödé_(song)":     "Ünïcödé_(song)",
+	"Question?_Answer!+": "Question?_Answer!+",
+}
+
+func TestTitlepath(t *testing.T) {
+	assert := assert.New(t)
+
+	for title, name := range titlepathTestCases {
+		assert.Equal(name, Encode(title))
+
+		decoded, err := Decode(name)
+		assert.NoError(err)
+		assert.Equal(title, decoded)
+	}
+
+	_, err := Decode("100%_Pure")
+	assert.Error(err)
+}
@@ -1,11 +1,14 @@
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"okapi-diffs/pkg/titlepath"
+)
 
 // DateFormat for directories
 const DateFormat = "2006-01-02"
 
-// Format get formatted dir path
+// Format get formatted dir path, title is encoded so it always stays a single path segment
 func Format(dir string, dbName string, contentType string, title string, fileType string) string {
-	return fmt.Sprintf("page/%s/%s/%s/%s.%s", dir, dbName, contentType, title, fileType)
+	return fmt.Sprintf("page/%s/%s/%s/%s.%s", dir, dbName, contentType, titlepath.Encode(title), fileType)
 }
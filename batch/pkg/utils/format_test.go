@@ -16,4 +16,5 @@ const formatTestFileType = "html"
 func TestFormat(t *testing.T) {
 	assert.Equal(t, fmt.Sprintf("page/%s/%s/%s/%s.%s", formatTestDir, formatTestDbName, formatTestContentType, formatTestTitle, formatTestFileType), Format(formatTestDir, formatTestDbName, formatTestContentType, formatTestTitle, formatTestFileType))
 
+	assert.Equal(t, fmt.Sprintf("page/%s/%s/%s/%s.%s", formatTestDir, formatTestDbName, formatTestContentType, "AC%2FDC", formatTestFileType), Format(formatTestDir, formatTestDbName, formatTestContentType, "AC/DC", formatTestFileType))
 }
@@ -0,0 +1,15 @@
+package schema
+
+// Manifest checksums of the published archive and of the files inside of it
+type Manifest struct {
+	SHA256 string   `json:"sha256"`
+	Chunks []*Chunk `json:"chunks,omitempty"`
+}
+
+// Chunk file inside of the published archive
+type Chunk struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+	Lines  int    `json:"lines"`
+}
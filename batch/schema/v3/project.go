@@ -11,4 +11,6 @@ type Project struct {
 	DateModified *time.Time `json:"date_modified,omitempty"`
 	InLanguage   *Language  `json:"in_language,omitempty"`
 	Size         *Size      `json:"size,omitempty"`
+	Manifest     *Manifest  `json:"manifest,omitempty"`
+	Snapshot     *Snapshot  `json:"snapshot,omitempty"`
 }
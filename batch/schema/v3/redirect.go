@@ -0,0 +1,14 @@
+package schema
+
+import "time"
+
+// Redirect title that points to another page of the project
+type Redirect struct {
+	Name         string     `json:"name"`
+	URL          string     `json:"url,omitempty"`
+	Namespace    *Namespace `json:"namespace,omitempty"`
+	IsPartOf     *Project   `json:"is_part_of,omitempty"`
+	Target       *Page      `json:"target"`
+	Fragment     string     `json:"fragment,omitempty"`
+	DateModified *time.Time `json:"date_modified,omitempty"`
+}
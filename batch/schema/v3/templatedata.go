@@ -0,0 +1,7 @@
+package schema
+
+// TemplateData parameters of the template invocation inside the page
+type TemplateData struct {
+	Name       string            `json:"name"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
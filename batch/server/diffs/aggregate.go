@@ -46,15 +46,20 @@ func Aggregate(_ context.Context, _ *pb.AggregateRequest, store aggrStore, date
 			}
 
 			meta := new(schema.Project)
+			err = json.NewDecoder(mrc).Decode(meta)
+			_ = mrc.Close()
 
-			if err := json.NewDecoder(mrc).Decode(meta); err != nil {
+			if err != nil {
 				log.Println(err)
 				continue
 			}
 
-			diffs[nsID] = append(diffs[nsID], meta)
+			// day without changes has meta, but no diff to download
+			if meta.Version == nil {
+				continue
+			}
 
-			_ = mrc.Close()
+			diffs[nsID] = append(diffs[nsID], meta)
 		}
 
 		res.Total++
@@ -11,6 +11,7 @@ import (
 	"okapi-diffs/pkg/contentypes"
 	"okapi-diffs/schema/v3"
 	pb "okapi-diffs/server/diffs/protos"
+	"strings"
 	"testing"
 	"time"
 
@@ -142,3 +143,20 @@ func TestAggregate(t *testing.T) {
 	assert.NotZero(res.Total)
 	assert.Zero(res.Errors)
 }
+
+func TestAggregateEmptyDiff(t *testing.T) {
+	assert := assert.New(t)
+	store := new(aggregateStorageMock)
+	store.On("List", fmt.Sprintf("diff/%s/", aggregateTestDate), map[string]interface{}{"delimiter": "/"}).
+		Return([]string{aggregateTestDiffDbName}, nil)
+
+	for _, nsID := range []int{schema.NamespaceArticle, schema.NamespaceFile, schema.NamespaceCategory, schema.NamespaceTemplate} {
+		store.On("Get", fmt.Sprintf("diff/%s/%s/%s_%s_%d.json", aggregateTestDate, aggregateTestDiffDbName, aggregateTestDiffDbName, contentypes.JSON, nsID)).
+			Return(ioutil.NopCloser(strings.NewReader(fmt.Sprintf(`{"identifier":"%s"}`, aggregateTestDiffDbName))), nil)
+	}
+
+	res, err := Aggregate(context.Background(), new(pb.AggregateRequest), store, aggregateTestDate)
+	assert.NoError(err)
+	assert.NotZero(res.Total)
+	store.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+}
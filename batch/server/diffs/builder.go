@@ -1,6 +1,8 @@
 package diffs
 
 import (
+	"okapi-diffs/pkg/multipart"
+
 	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
 )
 
@@ -28,6 +30,12 @@ func (bu *Builder) RemoteStorage(store storage.Storage) *Builder {
 	return bu
 }
 
+// MultipartStorage set new remote storage for streamed multipart uploads
+func (bu *Builder) MultipartStorage(up multipart.Uploader) *Builder {
+	bu.srv.multipart = up
+	return bu
+}
+
 // Build create new server instance with custom params
 func (bu *Builder) Build() *Server {
 	return bu.srv
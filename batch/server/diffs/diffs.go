@@ -3,13 +3,16 @@ package diffs
 import (
 	"context"
 	"fmt"
+	"log"
 	"okapi-diffs/lib/aws"
 	"okapi-diffs/lib/env"
 	"okapi-diffs/pkg/contentypes"
+	"okapi-diffs/pkg/multipart"
 	"okapi-diffs/pkg/utils"
 	pb "okapi-diffs/server/diffs/protos"
 	"time"
 
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
 	"github.com/protsack-stephan/dev-toolkit/lib/fs"
 	"github.com/protsack-stephan/dev-toolkit/lib/s3"
 	"github.com/protsack-stephan/dev-toolkit/pkg/server"
@@ -25,6 +28,7 @@ type Server struct {
 	pb.UnimplementedDiffsServer
 	remoteStore storage.Storage
 	localStore  storage.Storage
+	multipart   multipart.Uploader
 	server.Sequential
 }
 
@@ -35,12 +39,18 @@ func (srv *Server) Export(ctx context.Context, req *pb.ExportRequest) (*pb.Expor
 
 	err := srv.Once(fmt.Sprintf("export/%s", req.DbName), func() (err error) {
 		store := &ExportStorage{
-			Tmp:      fmt.Sprintf("tmp/%s/%s", req.DbName, date),
-			MetaDest: fmt.Sprintf("diff/%s/%s/%s_%s_%d.json", date, req.DbName, req.DbName, contentypes.JSON, req.Ns),
-			Dest:     fmt.Sprintf("diff/%s/%s/%s_%s_%d.tar.gz", date, req.DbName, req.DbName, contentypes.JSON, req.Ns),
-			Path:     fmt.Sprintf("page/%s/%s/%s", date, req.DbName, contentypes.JSON),
-			Local:    srv.localStore,
-			Remote:   srv.remoteStore,
+			Tmp:       fmt.Sprintf("tmp/%s/%s", req.DbName, date),
+			MetaDest:  fmt.Sprintf("diff/%s/%s/%s_%s_%d.json", date, req.DbName, req.DbName, contentypes.JSON, req.Ns),
+			Dest:      fmt.Sprintf("diff/%s/%s/%s_%s_%d.tar.gz", date, req.DbName, req.DbName, contentypes.JSON, req.Ns),
+			Path:      fmt.Sprintf("page/%s/%s/%s", date, req.DbName, contentypes.JSON),
+			Local:     srv.localStore,
+			Remote:    srv.remoteStore,
+			Multipart: srv.multipart,
+			Tracker:   srv.localStore,
+		}
+
+		if store.Snapshot, err = FindSnapshot(srv.remoteStore, req.DbName, req.Ns, date); err != nil {
+			log.Println(err)
 		}
 
 		err = Export(ctx, req, store, res)
@@ -73,6 +83,7 @@ func Init(srv grpc.ServiceRegistrar) {
 		srv,
 		NewBuilder().
 			RemoteStorage(s3.NewStorage(aws.Session(), env.AWSBucket)).
+			MultipartStorage(multipart.NewS3(awss3.New(aws.Session()), env.AWSBucket)).
 			LocalStorage(fs.NewStorage(env.Vol)).
 			Build())
 }
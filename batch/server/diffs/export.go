@@ -13,6 +13,8 @@ import (
 	"log"
 	"math"
 	"net/url"
+	"okapi-diffs/pkg/checksum"
+	"okapi-diffs/pkg/multipart"
 	"okapi-diffs/schema/v3"
 	pb "okapi-diffs/server/diffs/protos"
 	"runtime"
@@ -40,7 +42,10 @@ type ExportStorage struct {
 		storage.Walker
 		storage.Deleter
 	}
-	Remote storage.Putter
+	Remote    storage.Putter
+	Multipart multipart.Uploader // remote storage to stream the archive into, used when request asks for streaming
+	Tracker   multipart.Tracker  // storage for the progress of streamed uploads
+	Snapshot  *schema.Snapshot   // export snapshot the diff applies to, referenced in the diff meta
 }
 
 type exportFile struct {
@@ -50,14 +55,31 @@ type exportFile struct {
 
 // Export generate new export file and upload it to the storage
 func Export(ctx context.Context, req *pb.ExportRequest, store *ExportStorage, res *pb.ExportResponse) error {
-	file, err := store.Local.Create(store.Dest)
+	var err error
+	var file io.WriteCloser
+	var stream *multipart.Writer
+	var out io.Writer
 
-	if err != nil {
-		return err
-	}
+	// generate body md5 hash and sha256 for the manifest
+	h := md5.New() // #nosec G401
+	sum := checksum.NewWriter()
+
+	if req.Stream && store.Multipart != nil {
+		if stream, err = multipart.NewWriter(store.Multipart, store.Tracker, store.Dest); err != nil {
+			return err
+		}
+
+		out = io.MultiWriter(stream, h, sum)
+	} else {
+		if file, err = store.Local.Create(store.Dest); err != nil {
+			return err
+		}
 
-	if file == nil {
-		return ErrExportFileIsNil
+		if file == nil {
+			return ErrExportFileIsNil
+		}
+
+		out = file
 	}
 
 	var proj *schema.Project
@@ -65,7 +87,7 @@ func Export(ctx context.Context, req *pb.ExportRequest, store *ExportStorage, re
 	write, read := new(sync.WaitGroup), new(sync.WaitGroup)
 	files := make(chan exportFile, int(req.Workers))
 	paths := make(chan string, int(req.Workers))
-	gzip := pgzip.NewWriter(file)
+	gzip := pgzip.NewWriter(out)
 
 	if err := gzip.SetConcurrency(1<<20, runtime.NumCPU()*2); err != nil {
 		return err
@@ -123,6 +145,8 @@ func Export(ctx context.Context, req *pb.ExportRequest, store *ExportStorage, re
 		}()
 	}
 
+	var chunk *schema.Chunk
+
 	// create write worker
 	write.Add(1)
 	go func() {
@@ -130,7 +154,7 @@ func Export(ctx context.Context, req *pb.ExportRequest, store *ExportStorage, re
 
 		defer write.Done()
 
-		if err = JSON(tarbal, files, store, req, res); err != nil {
+		if chunk, err = JSON(tarbal, files, store, req, res); err != nil {
 			log.Println(err)
 		}
 	}()
@@ -144,74 +168,101 @@ func Export(ctx context.Context, req *pb.ExportRequest, store *ExportStorage, re
 	close(files)
 	write.Wait()
 	_ = tarbal.Close()
-	_ = gzip.Close()
-	_ = file.Close()
+	zerr := gzip.Close()
 
-	if err != nil {
-		return err
+	if stream == nil {
+		_ = file.Close()
 	}
 
-	export, err := store.Local.Get(store.Dest)
-
 	if err != nil {
 		return err
 	}
 
-	defer func() {
-		if err := store.Local.Delete(store.Dest); err != nil {
-			log.Println(err)
+	var size int64
+
+	if stream != nil {
+		if zerr != nil {
+			return zerr
+		}
+
+		// empty diff is not published, so the upload is dropped
+		if proj == nil || res.Total == 0 {
+			err = stream.Abort()
+		} else if err = stream.Close(); err == nil {
+			size = sum.Size()
 		}
-	}()
-	defer export.Close()
 
-	if err := store.Remote.Put(store.Dest, export); err != nil {
+		if err != nil {
+			return err
+		}
+	} else if size, err = exportUpload(store, io.MultiWriter(h, sum)); err != nil {
 		return err
 	}
 
-	info, err := store.Local.Stat(store.Dest)
-
-	if err != nil {
-		return err
+	datetime := time.Now().UTC()
+	meta := schema.Project{
+		Identifier:   req.DbName,
+		DateModified: &datetime,
+		Snapshot:     store.Snapshot,
 	}
 
-	if proj == nil {
-		return nil
+	if proj != nil {
+		meta.Name = proj.Name
+		meta.Identifier = proj.Identifier
+		meta.URL = proj.URL
+		meta.InLanguage = proj.InLanguage
 	}
 
-	if res.Total == 0 {
-		return nil
+	// meta of the empty diff has no version, it only records that the day was exported against the snapshot
+	if res.Total > 0 {
+		version := fmt.Sprintf("%x", h.Sum(nil))
+		meta.Version = &version
+		meta.Size = &schema.Size{
+			Value:    math.Round((((float64)(size)/1024)/1024)*100) / 100,
+			UnitText: "MB",
+		}
+		meta.Manifest = &schema.Manifest{
+			SHA256: sum.Sum(),
+		}
+
+		if chunk != nil {
+			meta.Manifest.Chunks = []*schema.Chunk{chunk}
+		}
 	}
 
-	// generate body md5 hash
-	h := md5.New() // #nosec G401
-	if _, err = io.Copy(h, export); err != nil {
+	metadata, err := json.Marshal(meta)
+
+	if err != nil {
 		return err
 	}
 
-	size := math.Round((((float64)(info.Size())/1024)/1024)*100) / 100
-	version := fmt.Sprintf("%x", h.Sum(nil))
-	datetime := time.Now().UTC()
-	meta := schema.Project{
-		Name:         proj.Name,
-		Identifier:   proj.Identifier,
-		URL:          proj.URL,
-		Version:      &version,
-		DateModified: &datetime,
-		Size: &schema.Size{
-			Value:    size,
-			UnitText: "MB",
-		},
+	return store.Remote.Put(store.MetaDest, bytes.NewReader(metadata))
+}
+
+// exportUpload upload local archive to the remote storage passing it through the hash on the way, returns size of the archive
+func exportUpload(store *ExportStorage, hash io.Writer) (int64, error) {
+	export, err := store.Local.Get(store.Dest)
+
+	if err != nil {
+		return 0, err
 	}
 
-	if proj.InLanguage != nil {
-		meta.InLanguage = proj.InLanguage
+	defer func() {
+		if err := store.Local.Delete(store.Dest); err != nil {
+			log.Println(err)
+		}
+	}()
+	defer export.Close()
+
+	if err := store.Remote.Put(store.Dest, io.TeeReader(export, hash)); err != nil {
+		return 0, err
 	}
 
-	metadata, err := json.Marshal(meta)
+	info, err := store.Local.Stat(store.Dest)
 
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return store.Remote.Put(store.MetaDest, bytes.NewReader(metadata))
+	return info.Size(), nil
 }
@@ -1,20 +1,25 @@
 package diffs
 
 import (
+	"archive/tar"
 	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"okapi-diffs/pkg/multipart"
 	"okapi-diffs/schema/v3"
 	pb "okapi-diffs/server/diffs/protos"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/protsack-stephan/dev-toolkit/lib/fs"
 	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -26,6 +31,7 @@ const exportTestTmpPath = "tmp/export/enwiki.ndjson"
 const exportTestDest = "test/export/export.tar.gz"
 const exportTestMetaDest = "test/export/export.json"
 const exportTestPath = "2020-02-19/enwiki/json"
+const exportTestSnapshotDate = "2020-02-17"
 
 var exportTestProjectDBName = "enwiki"
 var exportTestPages = []struct {
@@ -145,6 +151,15 @@ func matchReader(body io.Reader) bool {
 		return false
 	}
 
+	// validate sha256 manifest of the diff and its ndjson file
+	if metadata.Manifest == nil || len(metadata.Manifest.SHA256) != 64 {
+		return false
+	}
+
+	if len(metadata.Manifest.Chunks) != 1 || metadata.Manifest.Chunks[0].Name != fmt.Sprintf("%s.ndjson", exportTestProjectDBName) {
+		return false
+	}
+
 	if len(*metadata.Version) == 0 {
 		return false
 	}
@@ -156,6 +171,22 @@ func matchReader(body io.Reader) bool {
 	return true
 }
 
+func matchEmptyReader(body io.Reader) bool {
+	// validate meta of the day without changes, it has no version and manifest
+	metadata := schema.Project{}
+	buff, _ := ioutil.ReadAll(body)
+
+	if err := json.Unmarshal(buff, &metadata); err != nil {
+		return false
+	}
+
+	return len(metadata.Identifier) > 0 &&
+		metadata.DateModified != nil &&
+		metadata.Version == nil &&
+		metadata.Size == nil &&
+		metadata.Manifest == nil
+}
+
 func TestExport(t *testing.T) {
 	ctx := context.Background()
 	req := &pb.ExportRequest{
@@ -199,7 +230,7 @@ func TestExport(t *testing.T) {
 
 		remote := new(exportRemoteStorageMock)
 
-		remote.On("Put", exportTestDest, export).Return(nil)
+		remote.On("Put", exportTestDest, mock.Anything).Return(nil)
 		remote.On("Put", exportTestMetaDest, mock.MatchedBy(matchReader)).Return(nil)
 
 		store := &ExportStorage{
@@ -260,8 +291,8 @@ func TestExport(t *testing.T) {
 		local.On("Delete", exportTestTmpPath).Return(nil)
 
 		remote := new(exportRemoteStorageMock)
-		remote.On("Put", exportTestDest, export).Return(nil)
-		remote.On("Put", exportTestMetaDest, mock.MatchedBy(matchReader)).Return(nil)
+		remote.On("Put", exportTestDest, mock.Anything).Return(nil)
+		remote.On("Put", exportTestMetaDest, mock.MatchedBy(matchEmptyReader)).Return(nil)
 
 		store := &ExportStorage{
 			Tmp:      exportTestTmp,
@@ -310,8 +341,8 @@ func TestExport(t *testing.T) {
 
 		remote := new(exportRemoteStorageMock)
 
-		remote.On("Put", exportTestDest, export).Return(nil)
-		remote.On("Put", exportTestMetaDest, mock.MatchedBy(matchReader)).Return(nil)
+		remote.On("Put", exportTestDest, mock.Anything).Return(nil)
+		remote.On("Put", exportTestMetaDest, mock.MatchedBy(matchEmptyReader)).Return(nil)
 
 		store := &ExportStorage{
 			Tmp:      exportTestTmp,
@@ -334,6 +365,7 @@ func TestExport(t *testing.T) {
 		assert.Equal("", string(data))
 		assert.Equal(0, int(res.Total))
 		assert.Zero(res.Errors)
+		remote.AssertCalled(t, "Put", exportTestMetaDest, mock.Anything)
 	})
 
 	t.Run("create error", func(t *testing.T) {
@@ -384,7 +416,7 @@ func TestExport(t *testing.T) {
 
 		remote := new(exportRemoteStorageMock)
 
-		remote.On("Put", exportTestDest, export).Return(nil)
+		remote.On("Put", exportTestDest, mock.Anything).Return(nil)
 		remote.On("Put", exportTestMetaDest, mock.MatchedBy(matchReader)).Return(nil)
 
 		store := &ExportStorage{
@@ -431,7 +463,7 @@ func TestExport(t *testing.T) {
 
 		remote := new(exportRemoteStorageMock)
 
-		remote.On("Put", exportTestDest, export).Return(nil)
+		remote.On("Put", exportTestDest, mock.Anything).Return(nil)
 		remote.On("Put", exportTestMetaDest, mock.MatchedBy(matchReader)).Return(nil)
 
 		store := &ExportStorage{
@@ -478,7 +510,7 @@ func TestExport(t *testing.T) {
 
 		remote := new(exportRemoteStorageMock)
 
-		remote.On("Put", exportTestDest, export).Return(nil)
+		remote.On("Put", exportTestDest, mock.Anything).Return(nil)
 		remote.On("Put", exportTestMetaDest, mock.MatchedBy(matchReader)).Return(nil)
 
 		store := &ExportStorage{
@@ -494,3 +526,56 @@ func TestExport(t *testing.T) {
 		assert.Zero(res.Errors)
 	})
 }
+
+func TestExportStream(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	vol, remoteVol := t.TempDir(), t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(vol, exportTestPath), 0766))
+	assert.NoError(os.MkdirAll(filepath.Join(vol, exportTestTmp), 0766))
+
+	for _, page := range exportTestPages {
+		assert.NoError(ioutil.WriteFile(filepath.Join(vol, exportTestPath, page.Title), []byte(page.Data), 0644))
+	}
+
+	remote := new(exportRemoteStorageMock)
+	remote.On("Put", exportTestMetaDest, mock.MatchedBy(func(body io.Reader) bool {
+		buff := new(bytes.Buffer)
+		meta := new(schema.Project)
+
+		if !matchReader(io.TeeReader(body, buff)) || json.Unmarshal(buff.Bytes(), meta) != nil {
+			return false
+		}
+
+		return meta.Snapshot != nil && meta.Snapshot.Date == exportTestSnapshotDate
+	})).Return(nil)
+	store := &ExportStorage{
+		Tmp:       exportTestTmp,
+		Dest:      exportTestDest,
+		MetaDest:  exportTestMetaDest,
+		Path:      exportTestPath,
+		Local:     fs.NewStorage(vol),
+		Remote:    remote,
+		Multipart: multipart.NewFS(remoteVol),
+		Tracker:   fs.NewStorage(vol),
+		Snapshot:  &schema.Snapshot{Date: exportTestSnapshotDate, Version: "5f4dcc3b5aa765d61d8327deb882cf99"},
+	}
+
+	res := new(pb.ExportResponse)
+	assert.NoError(Export(ctx, &pb.ExportRequest{DbName: exportTestProjectDBName, Workers: 2, Ns: 2, Stream: true}, store, res))
+	assert.Equal(int32(3), res.Total)
+	remote.AssertNotCalled(t, "Put", exportTestDest, mock.Anything)
+	remote.AssertCalled(t, "Put", exportTestMetaDest, mock.Anything)
+
+	_, err := os.Stat(filepath.Join(vol, exportTestDest))
+	assert.True(os.IsNotExist(err))
+
+	data, err := ioutil.ReadFile(filepath.Join(remoteVol, exportTestDest))
+	assert.NoError(err)
+	gzr, err := gzip.NewReader(bytes.NewReader(data))
+	assert.NoError(err)
+	tr := tar.NewReader(gzr)
+	hdr, err := tr.Next()
+	assert.NoError(err)
+	assert.Equal(fmt.Sprintf("%s.ndjson", exportTestProjectDBName), hdr.Name)
+}
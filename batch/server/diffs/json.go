@@ -9,23 +9,25 @@ import (
 	"os"
 	"time"
 
+	"okapi-diffs/pkg/checksum"
+	"okapi-diffs/schema/v3"
 	pb "okapi-diffs/server/diffs/protos"
 )
 
 // ErrExportStorage type issue with local storage
 var ErrExportStorage = errors.New("local storage should work with os.File")
 
-// JSON put all files content into one file and add to writer
-func JSON(tarbal *tar.Writer, files chan exportFile, store *ExportStorage, req *pb.ExportRequest, res *pb.ExportResponse) error {
+// JSON put all files content into one file and add to writer, returns checksum of the file for the manifest
+func JSON(tarbal *tar.Writer, files chan exportFile, store *ExportStorage, req *pb.ExportRequest, res *pb.ExportResponse) (*schema.Chunk, error) {
 	path := fmt.Sprintf("%s/%s", store.Tmp, fmt.Sprintf("%s.ndjson", req.DbName))
 	storef, err := store.Local.Create(path)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if storef == nil {
-		return ErrExportFileIsNil
+		return nil, ErrExportFileIsNil
 	}
 
 	var tmpf *os.File
@@ -33,7 +35,7 @@ func JSON(tarbal *tar.Writer, files chan exportFile, store *ExportStorage, req *
 	if val, ok := storef.(*os.File); ok {
 		tmpf = val
 	} else {
-		return ErrExportStorage
+		return nil, ErrExportStorage
 	}
 
 	defer tmpf.Close() // #nosec G307
@@ -56,7 +58,7 @@ func JSON(tarbal *tar.Writer, files chan exportFile, store *ExportStorage, req *
 	stat, err := store.Local.Stat(path)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	header := &tar.Header{
@@ -67,20 +69,28 @@ func JSON(tarbal *tar.Writer, files chan exportFile, store *ExportStorage, req *
 	}
 
 	if err := tarbal.WriteHeader(header); err != nil {
-		return err
+		return nil, err
 	}
 
 	jsonf, err := store.Local.Get(path)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer jsonf.Close()
+	sum := checksum.NewWriter()
 
-	if _, err := io.Copy(tarbal, jsonf); err != nil {
-		return err
+	if _, err := io.Copy(io.MultiWriter(tarbal, sum), jsonf); err != nil {
+		return nil, err
 	}
 
-	return store.Local.Delete(path)
+	chunk := &schema.Chunk{
+		Name:   header.Name,
+		SHA256: sum.Sum(),
+		Size:   sum.Size(),
+		Lines:  sum.Lines(),
+	}
+
+	return chunk, store.Local.Delete(path)
 }
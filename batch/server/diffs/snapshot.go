@@ -0,0 +1,59 @@
+package diffs
+
+import (
+	"encoding/json"
+	"fmt"
+	"okapi-diffs/schema/v3"
+	"sort"
+
+	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
+)
+
+type snapshotStorage interface {
+	storage.Lister
+	storage.Getter
+}
+
+// FindSnapshot latest export snapshot of the project namespace taken on or before the date of the diff,
+// nil when project has no such snapshot (snapshot paths are written by the pages service).
+// Snapshot of the diff day can be taken after some of its changes, the diff of that day is the first one
+// applied on top of it, so all the diffs from the snapshot day on reference the same snapshot.
+func FindSnapshot(store snapshotStorage, dbName string, ns int32, date string) (*schema.Snapshot, error) {
+	dates, err := store.List(fmt.Sprintf("snapshot/%s/", dbName), map[string]interface{}{"delimiter": "/"})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+
+	for _, snap := range dates {
+		if snap > date {
+			continue
+		}
+
+		rc, err := store.Get(fmt.Sprintf("snapshot/%s/%s/%s_%d.json", dbName, snap, dbName, ns))
+
+		if err != nil {
+			continue
+		}
+
+		meta := new(schema.Project)
+		err = json.NewDecoder(rc).Decode(meta)
+		_ = rc.Close()
+
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot := &schema.Snapshot{Date: snap}
+
+		if meta.Version != nil {
+			snapshot.Version = *meta.Version
+		}
+
+		return snapshot, nil
+	}
+
+	return nil, nil
+}
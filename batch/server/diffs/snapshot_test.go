@@ -0,0 +1,86 @@
+package diffs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const snapshotTestDbName = "enwiki"
+const snapshotTestDate = "2021-09-08"
+const snapshotTestMeta = `{"name":"Wikipedia","identifier":"enwiki","version":"5f4dcc3b5aa765d61d8327deb882cf99"}`
+
+type snapshotStorageMock struct {
+	mock.Mock
+}
+
+func (s *snapshotStorageMock) List(path string, options ...map[string]interface{}) ([]string, error) {
+	args := s.Called(path, options[0])
+
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (s *snapshotStorageMock) Get(path string) (io.ReadCloser, error) {
+	args := s.Called(path)
+
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func TestFindSnapshot(t *testing.T) {
+	assert := assert.New(t)
+	list := fmt.Sprintf("snapshot/%s/", snapshotTestDbName)
+	opts := map[string]interface{}{"delimiter": "/"}
+	errNotFound := errors.New("key does not exist")
+	meta := func(date string, ns int) string {
+		return fmt.Sprintf("snapshot/%s/%s/%s_%d.json", snapshotTestDbName, date, snapshotTestDbName, ns)
+	}
+
+	t.Run("latest snapshot before the diff", func(t *testing.T) {
+		store := new(snapshotStorageMock)
+		store.On("List", list, opts).Return([]string{"2021-09-01", "2021-09-05", "2021-09-07", snapshotTestDate, "2021-09-09"}, nil)
+		store.On("Get", meta(snapshotTestDate, 0)).Return(ioutil.NopCloser(strings.NewReader("")), errNotFound)
+		store.On("Get", meta("2021-09-07", 0)).Return(ioutil.NopCloser(strings.NewReader("")), errNotFound)
+		store.On("Get", meta("2021-09-05", 0)).Return(ioutil.NopCloser(strings.NewReader(snapshotTestMeta)), nil)
+
+		snapshot, err := FindSnapshot(store, snapshotTestDbName, 0, snapshotTestDate)
+		assert.NoError(err)
+		assert.NotNil(snapshot)
+		assert.Equal("2021-09-05", snapshot.Date)
+		assert.Equal("5f4dcc3b5aa765d61d8327deb882cf99", snapshot.Version)
+		store.AssertNotCalled(t, "Get", meta("2021-09-09", 0))
+	})
+
+	t.Run("snapshot of the diff day", func(t *testing.T) {
+		store := new(snapshotStorageMock)
+		store.On("List", list, opts).Return([]string{"2021-09-05", snapshotTestDate, "2021-09-09"}, nil)
+		store.On("Get", meta(snapshotTestDate, 0)).Return(ioutil.NopCloser(strings.NewReader(snapshotTestMeta)), nil)
+
+		snapshot, err := FindSnapshot(store, snapshotTestDbName, 0, snapshotTestDate)
+		assert.NoError(err)
+		assert.NotNil(snapshot)
+		assert.Equal(snapshotTestDate, snapshot.Date)
+	})
+
+	t.Run("no snapshot", func(t *testing.T) {
+		store := new(snapshotStorageMock)
+		store.On("List", list, opts).Return([]string{"2021-09-09"}, nil)
+
+		snapshot, err := FindSnapshot(store, snapshotTestDbName, 0, snapshotTestDate)
+		assert.NoError(err)
+		assert.Nil(snapshot)
+	})
+
+	t.Run("list error", func(t *testing.T) {
+		store := new(snapshotStorageMock)
+		store.On("List", list, opts).Return([]string{}, errNotFound)
+
+		_, err := FindSnapshot(store, snapshotTestDbName, 0, snapshotTestDate)
+		assert.Equal(errNotFound, err)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -56,6 +57,15 @@ var GenVol string
 // JSONVol json data volume
 var JSONVol string
 
+// DumpVol volume with dump files that pages fetch can read titles from, local dump paths are rejected if not set
+var DumpVol string
+
+// JSONStore local storage of page json files, "fs" for file per page or "segment" for packed segments
+var JSONStore = "fs"
+
+// JSONEncoding compression of the stored page json files ("gzip"), empty to keep them uncompressed
+var JSONEncoding string
+
 // KafkaBroker kafka server
 var KafkaBroker string
 
@@ -80,6 +90,18 @@ var PagevisibilityWorkers = 2
 // Group dedicated user group
 var Group string
 
+// TemplateData list of template names (or name prefixes) to extract parameters from
+var TemplateData = []string{"Infobox"}
+
+// WikidataURL wikidata API url (can be pointed to the local stub)
+var WikidataURL = "https://www.wikidata.org"
+
+// WikidataAdditionalEntities enrich additional entities as well as the main one
+var WikidataAdditionalEntities = false
+
+// ORESURL ORES scores API url (can be pointed to the local stub)
+var ORESURL = "https://ores.wikimedia.org/v3/scores"
+
 const awsURL = "AWS_URL"
 const awsRegion = "AWS_REGION"
 const awsBucket = "AWS_BUCKET"
@@ -99,6 +121,9 @@ const elasticPassword = "ELASTIC_PASSWORD"
 
 const genVol = "GEN_VOL"
 const jsonVol = "JSON_VOL"
+const dumpVol = "DUMP_VOL"
+const jsonStore = "JSON_STORE"
+const jsonEncoding = "JSON_ENCODING"
 const kafkaBroker = "KAFKA_BROKER"
 const kafkaCreds = "KAFKA_CREDS"
 
@@ -108,6 +133,13 @@ const pagevisibilityWorkers = "PAGE_VISIBILITY_WORKERS"
 
 const group = "GROUP"
 
+const templateData = "TEMPLATE_DATA"
+
+const wikidataURL = "WIKIDATA_URL"
+const wikidataAdditionalEntities = "WIKIDATA_ADDITIONAL_ENTITIES"
+
+const oresURL = "ORES_URL"
+
 const errorMessage = "env variable '%s' not found"
 
 var variables = map[*string]string{
@@ -136,6 +168,22 @@ var workers = map[*int]string{
 	&PagevisibilityWorkers: pagevisibilityWorkers,
 }
 
+var optionals = map[*string]string{
+	&WikidataURL:  wikidataURL,
+	&ORESURL:      oresURL,
+	&JSONStore:    jsonStore,
+	&JSONEncoding: jsonEncoding,
+	&DumpVol:      dumpVol,
+}
+
+var flags = map[*bool]string{
+	&WikidataAdditionalEntities: wikidataAdditionalEntities,
+}
+
+var lists = map[*[]string]string{
+	&TemplateData: templateData,
+}
+
 // Init environment params
 func Init() error {
 	var (
@@ -177,5 +225,45 @@ func Init() error {
 		*ref = val
 	}
 
+	for ref, name := range optionals {
+		if val, ok := os.LookupEnv(name); ok {
+			*ref = val
+		}
+	}
+
+	for ref, name := range flags {
+		strVal, ok := os.LookupEnv(name)
+
+		if !ok {
+			continue
+		}
+
+		val, err := strconv.ParseBool(strVal)
+
+		if err != nil {
+			return fmt.Errorf("not a boolean value for '%s': %w", name, err)
+		}
+
+		*ref = val
+	}
+
+	for ref, name := range lists {
+		strVal, ok := os.LookupEnv(name)
+
+		if !ok {
+			continue
+		}
+
+		vals := []string{}
+
+		for _, val := range strings.Split(strVal, ",") {
+			if val = strings.TrimSpace(val); len(val) > 0 {
+				vals = append(vals, val)
+			}
+		}
+
+		*ref = vals
+	}
+
 	return nil
 }
@@ -38,6 +38,15 @@ const envTestPagevisibilityWorkers = 300
 
 const envTestGroup = "group_1"
 
+var envTestTemplateData = []string{"Infobox", "Taxobox"}
+
+const envTestWikidataURL = "http://localhost:9030"
+const envTestWikidataAdditionalEntities = true
+
+const envTestORESURL = "http://localhost:9040/v3/scores"
+const envTestJSONStore = "segment"
+const envTestJSONEncoding = "gzip"
+
 func TestEnv(t *testing.T) {
 	os.Setenv(awsURL, envTestAWSURL)
 	os.Setenv(awsRegion, envTestAWSRegion)
@@ -67,6 +76,12 @@ func TestEnv(t *testing.T) {
 	os.Setenv(pagevisibilityWorkers, strconv.Itoa(envTestPagevisibilityWorkers))
 
 	os.Setenv(group, envTestGroup)
+	os.Setenv(templateData, " Infobox, Taxobox,")
+	os.Setenv(wikidataURL, envTestWikidataURL)
+	os.Setenv(wikidataAdditionalEntities, strconv.FormatBool(envTestWikidataAdditionalEntities))
+	os.Setenv(oresURL, envTestORESURL)
+	os.Setenv(jsonStore, envTestJSONStore)
+	os.Setenv(jsonEncoding, envTestJSONEncoding)
 
 	err := Init()
 	assert := assert.New(t)
@@ -103,4 +118,10 @@ func TestEnv(t *testing.T) {
 	assert.Equal(envTestPagevisibilityWorkers, PagevisibilityWorkers)
 
 	assert.Equal(envTestGroup, Group)
+	assert.Equal(envTestTemplateData, TemplateData)
+	assert.Equal(envTestWikidataURL, WikidataURL)
+	assert.Equal(envTestWikidataAdditionalEntities, WikidataAdditionalEntities)
+	assert.Equal(envTestORESURL, ORESURL)
+	assert.Equal(envTestJSONStore, JSONStore)
+	assert.Equal(envTestJSONEncoding, JSONEncoding)
 }
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v10/orm"
+	pgmigrations "github.com/protsack-stephan/go-pg-migrations-helper"
+	migrations "github.com/robinjoseph08/go-pg-migrations/v3"
+)
+
+func init() {
+	table := pgmigrations.Table{
+		Name: "revisions",
+		Constraints: map[pgmigrations.Constraint][]string{
+			pgmigrations.ConstraintPrimaryKey: {
+				pgmigrations.Columns([]string{
+					"revision",
+					"db_name",
+				}),
+			},
+		},
+		Columns: []pgmigrations.Column{
+			{
+				Name: "id",
+				Type: "bigserial not null",
+			},
+			{
+				Name: "revision",
+				Type: "int not null",
+			},
+			{
+				Name: "revision_dt",
+				Type: "timestamp with time zone not null",
+			},
+			{
+				Name: "title",
+				Type: "varchar(750) not null",
+			},
+			{
+				Name: "pid",
+				Type: "bigint not null",
+			},
+			{
+				Name: "db_name",
+				Type: fmt.Sprintf("varchar(255) not null references projects(db_name) on update %s", pgmigrations.ActionCascade),
+			},
+			{
+				Name: "editor_id",
+				Type: "int not null default 0",
+			},
+			{
+				Name: "editor_name",
+				Type: "varchar(255)",
+			},
+			{
+				Name: "comment",
+				Type: "text",
+			},
+			{
+				Name: "tags",
+				Type: "varchar(255)[] not null default '{}'",
+			},
+			{
+				Name: "scores",
+				Type: "jsonb",
+			},
+			{
+				Name: "content_hash",
+				Type: "varchar(64)",
+			},
+			{
+				Name: "updated_at",
+				Type: "timestamp with time zone not null",
+			},
+			{
+				Name: "created_at",
+				Type: "timestamp with time zone not null",
+			},
+		},
+		Indexes: []pgmigrations.Index{
+			{
+				Table:   "revisions",
+				Columns: []string{"title", "revision_dt"},
+			},
+			{
+				Table:   "revisions",
+				Columns: []string{"pid", "revision_dt"},
+			},
+		},
+		Partition: &pgmigrations.Partition{
+			Columns: []string{"db_name"},
+			By:      pgmigrations.PartitionByList,
+		},
+	}
+
+	// no default partition, projects fetch creates partition for every new project
+	// and it can't be attached while default partition holds rows of the project
+	up := func(db orm.DB) error {
+		_, err := db.Exec(table.Create())
+
+		if err != nil {
+			return err
+		}
+
+		dbNames := []string{}
+
+		if _, err := db.Query(&dbNames, "select db_name from projects"); err != nil {
+			return err
+		}
+
+		for _, dbName := range dbNames {
+			_, err = db.Exec(fmt.Sprintf(`create table if not exists "%s_%s" partition of %s for values in ('%s')`, table.Name, dbName, table.Name, dbName))
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	down := func(db orm.DB) error {
+		_, err := db.Exec(table.Drop())
+		return err
+	}
+
+	opts := migrations.MigrationOptions{}
+
+	migrations.Register("20261019090000_create_revisions_table", up, down, opts)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/go-pg/pg/v10/orm"
+	pgmigrations "github.com/protsack-stephan/go-pg-migrations-helper"
+	migrations "github.com/robinjoseph08/go-pg-migrations/v3"
+)
+
+func init() {
+	column := pgmigrations.Column{
+		Table: "pages",
+		Name:  "content_hash",
+		Type:  "varchar(64)",
+	}
+
+	up := func(db orm.DB) error {
+		_, err := db.Exec(column.Add())
+		return err
+	}
+
+	down := func(db orm.DB) error {
+		_, err := db.Exec(column.Drop())
+		return err
+	}
+
+	opts := migrations.MigrationOptions{}
+
+	migrations.Register("20261019100000_alter_pages_table", up, down, opts)
+}
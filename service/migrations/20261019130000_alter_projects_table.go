@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/go-pg/pg/v10/orm"
+	pgmigrations "github.com/protsack-stephan/go-pg-migrations-helper"
+	migrations "github.com/robinjoseph08/go-pg-migrations/v3"
+)
+
+func init() {
+	column := pgmigrations.Column{
+		Table: "projects",
+		Name:  "stable_only",
+		Type:  "boolean not null default false",
+	}
+
+	up := func(db orm.DB) error {
+		_, err := db.Exec(column.Add())
+		return err
+	}
+
+	down := func(db orm.DB) error {
+		_, err := db.Exec(column.Drop())
+		return err
+	}
+
+	opts := migrations.MigrationOptions{}
+
+	migrations.Register("20261019130000_alter_projects_table", up, down, opts)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v10/orm"
+	pgmigrations "github.com/protsack-stephan/go-pg-migrations-helper"
+	migrations "github.com/robinjoseph08/go-pg-migrations/v3"
+)
+
+func init() {
+	table := pgmigrations.Table{
+		Name: "licenses",
+		Constraints: map[pgmigrations.Constraint][]string{
+			pgmigrations.ConstraintPrimaryKey: {
+				pgmigrations.Columns([]string{"id"}),
+			},
+		},
+		Columns: []pgmigrations.Column{
+			{
+				Name: "id",
+				Type: "serial not null",
+			},
+			{
+				Name: "db_name",
+				Type: fmt.Sprintf("varchar(255) not null references projects(db_name) on update %s", pgmigrations.ActionCascade),
+			},
+			{
+				Name: "ns",
+				Type: "int",
+			},
+			{
+				Name: "name",
+				Type: "varchar(255) not null",
+			},
+			{
+				Name: "identifier",
+				Type: "varchar(255) not null",
+			},
+			{
+				Name: "url",
+				Type: "varchar(255) not null",
+			},
+			{
+				Name: "updated_at",
+				Type: "timestamp with time zone not null",
+			},
+			{
+				Name: "created_at",
+				Type: "timestamp with time zone not null",
+			},
+		},
+		Indexes: []pgmigrations.Index{
+			{
+				Table:   "licenses",
+				Columns: []string{"db_name", "ns"},
+			},
+		},
+	}
+
+	up := func(db orm.DB) error {
+		_, err := db.Exec(table.Create())
+		return err
+	}
+
+	down := func(db orm.DB) error {
+		_, err := db.Exec(table.Drop())
+		return err
+	}
+
+	opts := migrations.MigrationOptions{}
+
+	migrations.Register("20261019140000_create_licenses_table", up, down, opts)
+}
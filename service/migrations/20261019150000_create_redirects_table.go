@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v10/orm"
+	pgmigrations "github.com/protsack-stephan/go-pg-migrations-helper"
+	migrations "github.com/robinjoseph08/go-pg-migrations/v3"
+)
+
+func init() {
+	table := pgmigrations.Table{
+		Name: "redirects",
+		Constraints: map[pgmigrations.Constraint][]string{
+			pgmigrations.ConstraintPrimaryKey: {
+				pgmigrations.Columns([]string{"id"}),
+			},
+			pgmigrations.ConstraintUnique: {
+				pgmigrations.Columns([]string{
+					"db_name",
+					"title",
+				}),
+			},
+		},
+		Columns: []pgmigrations.Column{
+			{
+				Name: "id",
+				Type: "bigserial not null",
+			},
+			{
+				Name: "db_name",
+				Type: fmt.Sprintf("varchar(255) not null references projects(db_name) on update %s", pgmigrations.ActionCascade),
+			},
+			{
+				Name: "title",
+				Type: "varchar(750) not null",
+			},
+			{
+				Name: "ns_id",
+				Type: "int not null",
+			},
+			{
+				Name: "target",
+				Type: "varchar(750) not null",
+			},
+			{
+				Name: "fragment",
+				Type: "varchar(750) not null default ''",
+			},
+			{
+				Name: "updated_at",
+				Type: "timestamp with time zone not null",
+			},
+			{
+				Name: "created_at",
+				Type: "timestamp with time zone not null",
+			},
+		},
+		Indexes: []pgmigrations.Index{
+			{
+				Table:   "redirects",
+				Columns: []string{"db_name", "target"},
+			},
+		},
+	}
+
+	up := func(db orm.DB) error {
+		_, err := db.Exec(table.Create())
+		return err
+	}
+
+	down := func(db orm.DB) error {
+		_, err := db.Exec(table.Drop())
+		return err
+	}
+
+	opts := migrations.MigrationOptions{}
+
+	migrations.Register("20261019150000_create_redirects_table", up, down, opts)
+}
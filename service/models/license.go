@@ -0,0 +1,44 @@
+package models
+
+import (
+	"context"
+	"okapi-data-service/schema/v3"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// License content license of the project, namespace specific license takes precedence over the project one
+type License struct {
+	ID         int    `json:"id"`
+	DbName     string `pg:"type:varchar(255),notnull" json:"db_name"`
+	Ns         *int   `json:"ns,omitempty"`
+	Name       string `pg:"type:varchar(255),notnull" json:"name"`
+	Identifier string `pg:"type:varchar(255),notnull" json:"identifier"`
+	URL        string `pg:"type:varchar(255),notnull" json:"url"`
+	timestamp
+}
+
+// Schema convert license into schema.org representation
+func (lic *License) Schema() *schema.License {
+	return &schema.License{
+		Name:       lic.Name,
+		Identifier: lic.Identifier,
+		URL:        lic.URL,
+	}
+}
+
+var _ pg.BeforeUpdateHook = (*License)(nil)
+
+// BeforeUpdate model hook
+func (lic *License) BeforeUpdate(ctx context.Context) (context.Context, error) {
+	lic.OnUpdate()
+	return ctx, nil
+}
+
+var _ pg.BeforeInsertHook = (*License)(nil)
+
+// BeforeInsert model hook
+func (lic *License) BeforeInsert(ctx context.Context) (context.Context, error) {
+	lic.OnInsert()
+	return ctx, nil
+}
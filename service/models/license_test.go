@@ -0,0 +1,47 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLicenseSchema(t *testing.T) {
+	assert := assert.New(t)
+	lic := &License{
+		DbName:     "enwikinews",
+		Name:       "Attribution 2.5 Generic",
+		Identifier: "CC-BY-2.5",
+		URL:        "https://creativecommons.org/licenses/by/2.5/",
+	}
+
+	license := lic.Schema()
+	assert.Equal(lic.Name, license.Name)
+	assert.Equal(lic.Identifier, license.Identifier)
+	assert.Equal(lic.URL, license.URL)
+}
+
+func TestLicenseBeforeInsert(t *testing.T) {
+	lic := new(License)
+	createdAt := lic.CreatedAt
+	updatedAt := lic.UpdatedAt
+
+	_, err := lic.BeforeInsert(context.Background())
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, createdAt, lic.CreatedAt)
+	assert.NotEqual(t, updatedAt, lic.UpdatedAt)
+}
+
+func TestLicenseBeforeUpdate(t *testing.T) {
+	lic := new(License)
+	createdAt := lic.CreatedAt
+	updatedAt := lic.UpdatedAt
+
+	_, err := lic.BeforeUpdate(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, createdAt, lic.CreatedAt)
+	assert.NotEqual(t, updatedAt, lic.UpdatedAt)
+}
@@ -36,21 +36,22 @@ func NewPage(title string, data *mediawiki.PageData, proj *Project, prev *Page)
 
 // Page database table representation
 type Page struct {
-	ID         int        `json:"id"`
-	Title      string     `pg:"type:varchar(750),notnull" json:"title"`
-	NsID       int        `pg:",use_zero" json:"ns_id"`
-	QID        string     `pg:"type:varchar(500)"`
-	PID        int        `pg:"type:bigint" json:"pid"`
-	Revision   int        `pg:",use_zero" json:"revision"`
-	RevisionDt time.Time  `pg:"type:timestamp" json:"revision_dt"`
-	Failed     bool       `pg:",use_zero" json:"failed"`
-	Lang       string     `pg:"type:varchar(25),notnull" json:"lang"`
-	DbName     string     `pg:"type:varchar(255),notnull" json:"db_name"`
-	SiteURL    string     `pg:"type:varchar(1000),notnull" json:"site_url"`
-	Path       string     `pg:"type:varchar(1000)" json:"path"`
-	Language   *Language  `pg:"rel:has-one" json:"language,omitempty"`
-	Project    *Project   `pg:"rel:has-one" json:"project,omitempty"`
-	Namespace  *Namespace `pg:"rel:has-one" json:"namespace,omitempty"`
+	ID          int        `json:"id"`
+	Title       string     `pg:"type:varchar(750),notnull" json:"title"`
+	NsID        int        `pg:",use_zero" json:"ns_id"`
+	QID         string     `pg:"type:varchar(500)"`
+	PID         int        `pg:"type:bigint" json:"pid"`
+	Revision    int        `pg:",use_zero" json:"revision"`
+	RevisionDt  time.Time  `pg:"type:timestamp" json:"revision_dt"`
+	Failed      bool       `pg:",use_zero" json:"failed"`
+	Lang        string     `pg:"type:varchar(25),notnull" json:"lang"`
+	DbName      string     `pg:"type:varchar(255),notnull" json:"db_name"`
+	SiteURL     string     `pg:"type:varchar(1000),notnull" json:"site_url"`
+	Path        string     `pg:"type:varchar(1000)" json:"path"`
+	ContentHash string     `pg:"type:varchar(64)" json:"content_hash"`
+	Language    *Language  `pg:"rel:has-one" json:"language,omitempty"`
+	Project     *Project   `pg:"rel:has-one" json:"project,omitempty"`
+	Namespace   *Namespace `pg:"rel:has-one" json:"namespace,omitempty"`
 	timestamp
 }
 
@@ -2,20 +2,23 @@ package models
 
 import (
 	"context"
+	"okapi-data-service/pkg/quarantine"
 
 	"github.com/go-pg/pg/v10"
 )
 
 // Project database table representation
 type Project struct {
-	ID       int       `pg:",pk" json:"id"`
-	DbName   string    `pg:"type:varchar(255),unique,notnull" json:"db_name"`
-	SiteName string    `pg:"type:varchar(255),notnull" json:"site_name"`
-	SiteCode string    `pg:"type:varchar(255),notnull" json:"site_code"`
-	SiteURL  string    `pg:"type:varchar(255),notnull" json:"site_url"`
-	Lang     string    `pg:"type:varchar(25),notnull" json:"lang"`
-	Active   bool      `pg:",use_zero,notnull" json:"active"`
-	Language *Language `pg:"rel:has-one" json:"language,omitempty"`
+	ID         int                `pg:",pk" json:"id"`
+	DbName     string             `pg:"type:varchar(255),unique,notnull" json:"db_name"`
+	SiteName   string             `pg:"type:varchar(255),notnull" json:"site_name"`
+	SiteCode   string             `pg:"type:varchar(255),notnull" json:"site_code"`
+	SiteURL    string             `pg:"type:varchar(255),notnull" json:"site_url"`
+	Lang       string             `pg:"type:varchar(25),notnull" json:"lang"`
+	Active     bool               `pg:",use_zero,notnull" json:"active"`
+	StableOnly bool               `pg:",use_zero,notnull" json:"stable_only"`
+	Quarantine *quarantine.Policy `pg:"type:jsonb" json:"quarantine,omitempty"`
+	Language   *Language          `pg:"rel:has-one" json:"language,omitempty"`
 	timestamp
 }
 
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// Redaction suppressed revision that has to be removed from already published archives
+type Redaction struct {
+	ID        int                  `json:"id"`
+	DbName    string               `pg:"type:varchar(255),notnull" json:"db_name"`
+	Title     string               `pg:"type:varchar(750),notnull" json:"title"`
+	Revision  int                  `pg:",use_zero" json:"revision"`
+	Artifacts []*RedactionArtifact `pg:"type:jsonb" json:"artifacts"`
+	AppliedAt *time.Time           `pg:"type:timestamp" json:"applied_at,omitempty"`
+	timestamp
+}
+
+// RedactionArtifact published archive that contained the suppressed revision
+type RedactionArtifact struct {
+	Path         string    `json:"path"`
+	Version      string    `json:"version"`
+	DateRedacted time.Time `json:"date_redacted"`
+}
+
+// AddArtifact record archive that was fixed
+func (red *Redaction) AddArtifact(path string, version string, dt time.Time) {
+	red.Artifacts = append(red.Artifacts, &RedactionArtifact{
+		Path:         path,
+		Version:      version,
+		DateRedacted: dt,
+	})
+}
+
+var _ pg.BeforeUpdateHook = (*Redaction)(nil)
+
+// BeforeUpdate model hook
+func (red *Redaction) BeforeUpdate(ctx context.Context) (context.Context, error) {
+	red.OnUpdate()
+	return ctx, nil
+}
+
+var _ pg.BeforeInsertHook = (*Redaction)(nil)
+
+// BeforeInsert model hook
+func (red *Redaction) BeforeInsert(ctx context.Context) (context.Context, error) {
+	red.OnInsert()
+	return ctx, nil
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactionAddArtifact(t *testing.T) {
+	assert := assert.New(t)
+	dt := time.Now().UTC()
+	red := new(Redaction)
+
+	red.AddArtifact("export/enwiki/enwiki_json_0.tar.gz", "version", dt)
+
+	assert.Len(red.Artifacts, 1)
+	assert.Equal("export/enwiki/enwiki_json_0.tar.gz", red.Artifacts[0].Path)
+	assert.Equal("version", red.Artifacts[0].Version)
+	assert.Equal(dt, red.Artifacts[0].DateRedacted)
+}
+
+func TestRedactionBeforeInsert(t *testing.T) {
+	red := new(Redaction)
+	createdAt := red.CreatedAt
+	updatedAt := red.UpdatedAt
+
+	_, err := red.BeforeInsert(context.Background())
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, createdAt, red.CreatedAt)
+	assert.NotEqual(t, updatedAt, red.UpdatedAt)
+}
+
+func TestRedactionBeforeUpdate(t *testing.T) {
+	red := new(Redaction)
+	createdAt := red.CreatedAt
+	updatedAt := red.UpdatedAt
+
+	_, err := red.BeforeUpdate(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, createdAt, red.CreatedAt)
+	assert.NotEqual(t, updatedAt, red.UpdatedAt)
+}
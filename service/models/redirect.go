@@ -0,0 +1,58 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"okapi-data-service/schema/v3"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// Redirect title of the project that points to another page
+type Redirect struct {
+	ID       int    `json:"id"`
+	DbName   string `pg:"type:varchar(255),notnull" json:"db_name"`
+	Title    string `pg:"type:varchar(750),notnull" json:"title"`
+	NsID     int    `pg:",use_zero" json:"ns_id"`
+	Target   string `pg:"type:varchar(750),notnull" json:"target"`
+	Fragment string `pg:",use_zero" json:"fragment"`
+	timestamp
+}
+
+// Schema convert redirect into schema.org representation
+func (red *Redirect) Schema(proj *Project) *schema.Redirect {
+	return &schema.Redirect{
+		Name: red.Title,
+		URL:  fmt.Sprintf("%s/wiki/%s", proj.SiteURL, strings.ReplaceAll(red.Title, " ", "_")),
+		Namespace: &schema.Namespace{
+			Identifier: red.NsID,
+		},
+		IsPartOf: &schema.Project{
+			Name:       proj.SiteName,
+			Identifier: proj.DbName,
+		},
+		Target: &schema.Page{
+			Name: red.Target,
+			URL:  fmt.Sprintf("%s/wiki/%s", proj.SiteURL, strings.ReplaceAll(red.Target, " ", "_")),
+		},
+		Fragment:     red.Fragment,
+		DateModified: &red.UpdatedAt,
+	}
+}
+
+var _ pg.BeforeUpdateHook = (*Redirect)(nil)
+
+// BeforeUpdate model hook
+func (red *Redirect) BeforeUpdate(ctx context.Context) (context.Context, error) {
+	red.OnUpdate()
+	return ctx, nil
+}
+
+var _ pg.BeforeInsertHook = (*Redirect)(nil)
+
+// BeforeInsert model hook
+func (red *Redirect) BeforeInsert(ctx context.Context) (context.Context, error) {
+	red.OnInsert()
+	return ctx, nil
+}
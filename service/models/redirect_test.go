@@ -0,0 +1,63 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedirectSchema(t *testing.T) {
+	assert := assert.New(t)
+	proj := &Project{
+		DbName:   "enwiki",
+		SiteName: "Wikipedia",
+		SiteURL:  "https://en.wikipedia.org",
+	}
+	red := &Redirect{
+		DbName:   proj.DbName,
+		Title:    "Planet Earth",
+		NsID:     0,
+		Target:   "Earth",
+		Fragment: "Orbit",
+		timestamp: timestamp{
+			UpdatedAt: time.Now(),
+		},
+	}
+
+	sch := red.Schema(proj)
+	assert.Equal("Planet Earth", sch.Name)
+	assert.Equal("https://en.wikipedia.org/wiki/Planet_Earth", sch.URL)
+	assert.Equal(0, sch.Namespace.Identifier)
+	assert.Equal(proj.DbName, sch.IsPartOf.Identifier)
+	assert.Equal(proj.SiteName, sch.IsPartOf.Name)
+	assert.Equal("Earth", sch.Target.Name)
+	assert.Equal("https://en.wikipedia.org/wiki/Earth", sch.Target.URL)
+	assert.Equal("Orbit", sch.Fragment)
+	assert.Equal(red.UpdatedAt, *sch.DateModified)
+}
+
+func TestRedirectBeforeInsert(t *testing.T) {
+	red := new(Redirect)
+	createdAt := red.CreatedAt
+	updatedAt := red.UpdatedAt
+
+	_, err := red.BeforeInsert(context.Background())
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, createdAt, red.CreatedAt)
+	assert.NotEqual(t, updatedAt, red.UpdatedAt)
+}
+
+func TestRedirectBeforeUpdate(t *testing.T) {
+	red := new(Redirect)
+	createdAt := red.CreatedAt
+	updatedAt := red.UpdatedAt
+
+	_, err := red.BeforeUpdate(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, createdAt, red.CreatedAt)
+	assert.NotEqual(t, updatedAt, red.UpdatedAt)
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"context"
+	"okapi-data-service/schema/v3"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// NewRevision create revision record out of the page schema
+func NewRevision(page *schema.Page, proj *Project) *Revision {
+	rev := &Revision{
+		Title:       strings.ReplaceAll(page.Name, " ", "_"),
+		PID:         page.Identifier,
+		DbName:      proj.DbName,
+		ContentHash: page.ContentHash,
+	}
+
+	if page.DateModified != nil {
+		rev.RevisionDt = *page.DateModified
+	}
+
+	if page.Version != nil {
+		rev.Revision = page.Version.Identifier
+		rev.Comment = page.Version.Comment
+		rev.Tags = page.Version.Tags
+		rev.Scores = page.Version.Scores
+		rev.SetEditor(page.Version.Editor)
+	}
+
+	return rev
+}
+
+// Revision database table representation (history of page versions)
+type Revision struct {
+	ID          int            `json:"id"`
+	Revision    int            `pg:",use_zero" json:"revision"`
+	RevisionDt  time.Time      `pg:"type:timestamp" json:"revision_dt"`
+	Title       string         `pg:"type:varchar(750),notnull" json:"title"`
+	PID         int            `pg:"type:bigint" json:"pid"`
+	DbName      string         `pg:"type:varchar(255),notnull" json:"db_name"`
+	EditorID    int            `pg:",use_zero" json:"editor_id"`
+	EditorName  string         `pg:"type:varchar(255)" json:"editor_name"`
+	Comment     string         `pg:"type:text" json:"comment"`
+	Tags        []string       `pg:",array" json:"tags"`
+	Scores      *schema.Scores `pg:"type:jsonb" json:"scores,omitempty"`
+	ContentHash string         `pg:"type:varchar(64)" json:"content_hash"`
+	timestamp
+}
+
+// SetEditor set editor of the revision
+func (rev *Revision) SetEditor(editor *schema.Editor) {
+	if editor != nil {
+		rev.EditorID = editor.Identifier
+		rev.EditorName = editor.Name
+	}
+}
+
+var _ pg.BeforeUpdateHook = (*Revision)(nil)
+
+// BeforeUpdate model hook
+func (rev *Revision) BeforeUpdate(ctx context.Context) (context.Context, error) {
+	rev.OnUpdate()
+	return ctx, nil
+}
+
+var _ pg.BeforeInsertHook = (*Revision)(nil)
+
+// BeforeInsert model hook
+func (rev *Revision) BeforeInsert(ctx context.Context) (context.Context, error) {
+	rev.OnInsert()
+	return ctx, nil
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"context"
+	"okapi-data-service/schema/v3"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRevision(t *testing.T) {
+	assert := assert.New(t)
+	dt := time.Now().UTC()
+	page := &schema.Page{
+		Name:         "Planet Earth",
+		Identifier:   9228,
+		DateModified: &dt,
+		ContentHash:  "34c8ee5bceefed69b477ac7347ebb9ac7071bc766e79f08d5e0dbb5b48f10ddd",
+		Version: &schema.Version{
+			Identifier: 100,
+			Comment:    "comment",
+			Tags:       []string{"mobile edit"},
+			Editor: &schema.Editor{
+				Identifier: 10,
+				Name:       "Editor",
+			},
+		},
+	}
+
+	rev := NewRevision(page, &Project{DbName: "enwiki"})
+	assert.Equal("Planet_Earth", rev.Title)
+	assert.Equal(page.Identifier, rev.PID)
+	assert.Equal("enwiki", rev.DbName)
+	assert.Equal(dt, rev.RevisionDt)
+	assert.Equal(100, rev.Revision)
+	assert.Equal("comment", rev.Comment)
+	assert.Equal([]string{"mobile edit"}, rev.Tags)
+	assert.Equal(10, rev.EditorID)
+	assert.Equal("Editor", rev.EditorName)
+	assert.Equal(page.ContentHash, rev.ContentHash)
+}
+
+func TestRevisionBeforeInsert(t *testing.T) {
+	rev := new(Revision)
+	createdAt := rev.CreatedAt
+	updatedAt := rev.UpdatedAt
+
+	_, err := rev.BeforeInsert(context.Background())
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, createdAt, rev.CreatedAt)
+	assert.NotEqual(t, updatedAt, rev.UpdatedAt)
+}
+
+func TestRevisionBeforeUpdate(t *testing.T) {
+	rev := new(Revision)
+	createdAt := rev.CreatedAt
+	updatedAt := rev.UpdatedAt
+
+	_, err := rev.BeforeUpdate(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, createdAt, rev.CreatedAt)
+	assert.NotEqual(t, updatedAt, rev.UpdatedAt)
+}
@@ -0,0 +1,181 @@
+// Package actions minimal actions API client for the page props that are not supported by mediawiki client.
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const actionsURL = "/w/api.php"
+
+// maxContinues safety limit for the number of continuation requests
+const maxContinues = 50
+
+// ErrTooManyContinues query did not complete within the maxContinues requests
+var ErrTooManyContinues = errors.New("too many continuation requests")
+
+// NewClient create new actions API client
+func NewClient(url string, headers map[string]string) *Client {
+	return &Client{
+		URL:        url,
+		HTTPClient: new(http.Client),
+		Headers:    headers,
+	}
+}
+
+// Client actions API client
+type Client struct {
+	URL        string
+	HTTPClient *http.Client
+	Headers    map[string]string
+}
+
+type response struct {
+	Continue map[string]interface{} `json:"continue"`
+	Query    struct {
+		Normalized []struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		} `json:"normalized"`
+		Redirects []struct {
+			From       string `json:"from"`
+			To         string `json:"to"`
+			ToFragment string `json:"tofragment"`
+		} `json:"redirects"`
+		Pages []json.RawMessage `json:"pages"`
+	} `json:"query"`
+}
+
+type page struct {
+	Title   string `json:"title"`
+	Missing bool   `json:"missing"`
+}
+
+// query run actions API query for the list of titles following all of the continuations,
+// handle receives requested title (not the normalized one) and raw page data,
+// returns redirect targets of the requested titles
+func (cl *Client) query(ctx context.Context, params url.Values, titles []string, handle func(title string, data []byte) error) (map[string]Redirect, error) {
+	params.Set("action", "query")
+	params.Set("format", "json")
+	params.Set("formatversion", "2")
+	params.Set("redirects", "1")
+	params.Set("titles", strings.Join(titles, "|"))
+
+	lookup := map[string]string{}
+	redirects := map[string]Redirect{}
+
+	for _, title := range titles {
+		lookup[title] = title
+	}
+
+	// continuation values of the previous response, replaced on every request
+	cont := map[string]interface{}{}
+
+	for i := 0; i < maxContinues; i++ {
+		for key, val := range cont {
+			params.Set(key, fmt.Sprint(val))
+		}
+
+		res, err := cl.post(ctx, params)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, title := range res.Query.Normalized {
+			if from, ok := lookup[title.From]; ok {
+				lookup[title.To] = from
+			}
+		}
+
+		for _, title := range res.Query.Redirects {
+			from, ok := lookup[title.From]
+
+			if !ok {
+				continue
+			}
+
+			redirects[from] = Redirect{
+				Title:    title.To,
+				Fragment: title.ToFragment,
+			}
+
+			// redirect target that was requested itself keeps its own data
+			if _, ok := lookup[title.To]; !ok {
+				lookup[title.To] = from
+			}
+		}
+
+		for _, data := range res.Query.Pages {
+			pg := new(page)
+
+			if err := json.Unmarshal(data, pg); err != nil {
+				return nil, err
+			}
+
+			if title, ok := lookup[pg.Title]; ok && !pg.Missing {
+				if err := handle(title, data); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if len(res.Continue) == 0 {
+			return redirects, nil
+		}
+
+		for key := range cont {
+			params.Del(key)
+		}
+
+		cont = res.Continue
+	}
+
+	return nil, ErrTooManyContinues
+}
+
+func (cl *Client) post(ctx context.Context, params url.Values) (*response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cl.URL+actionsURL, strings.NewReader(params.Encode()))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	for key, value := range cl.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := cl.HTTPClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status: '%d' body: '%s'", resp.StatusCode, data)
+	}
+
+	res := new(response)
+
+	if err := json.Unmarshal(data, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
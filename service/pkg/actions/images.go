@@ -0,0 +1,63 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// ImageInfo metadata of the current version of the file
+type ImageInfo struct {
+	URL         string                 `json:"url"`
+	Mime        string                 `json:"mime"`
+	Size        int                    `json:"size"`
+	Width       int                    `json:"width"`
+	Height      int                    `json:"height"`
+	User        string                 `json:"user"`
+	UserID      int                    `json:"userid"`
+	ExtMetadata map[string]ExtMetadata `json:"extmetadata"`
+}
+
+// ExtMetadata single field of the extended file metadata
+type ExtMetadata struct {
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+// Meta get string value of extended metadata field
+func (ii *ImageInfo) Meta(name string) string {
+	if meta, ok := ii.ExtMetadata[name]; ok && meta.Value != nil {
+		return fmt.Sprint(meta.Value)
+	}
+
+	return ""
+}
+
+// ImagesInfo get metadata of the files for the list of file titles
+func (cl *Client) ImagesInfo(ctx context.Context, titles []string) (map[string]ImageInfo, error) {
+	infos := map[string]ImageInfo{}
+	params := url.Values{
+		"prop":                []string{"imageinfo"},
+		"iiprop":              []string{"url|mime|size|user|userid|extmetadata"},
+		"iiextmetadatafilter": []string{"License|LicenseShortName|LicenseUrl|UsageTerms"},
+	}
+
+	_, err := cl.query(ctx, params, titles, func(title string, data []byte) error {
+		pg := new(struct {
+			ImageInfo []ImageInfo `json:"imageinfo"`
+		})
+
+		if err := json.Unmarshal(data, pg); err != nil {
+			return err
+		}
+
+		if len(pg.ImageInfo) > 0 {
+			infos[title] = pg.ImageInfo[0]
+		}
+
+		return nil
+	})
+
+	return infos, err
+}
@@ -0,0 +1,76 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const imagesInfoTestResponse = `{
+	"batchcomplete": true,
+	"query": {
+		"pages": [
+			{
+				"pageid": 1,
+				"ns": 6,
+				"title": "File:Earth.jpg",
+				"imageinfo": [
+					{
+						"size": 1024,
+						"width": 640,
+						"height": 480,
+						"url": "https://upload.wikimedia.org/wikipedia/commons/e/e1/Earth.jpg",
+						"mime": "image/jpeg",
+						"user": "Uploader",
+						"userid": 10,
+						"extmetadata": {
+							"License": {"value": "cc-by-sa-4.0", "source": "commons-templates"},
+							"LicenseShortName": {"value": "CC BY-SA 4.0", "source": "commons-desc-page"},
+							"LicenseUrl": {"value": "https://creativecommons.org/licenses/by-sa/4.0", "source": "commons-desc-page"}
+						}
+					}
+				]
+			}
+		]
+	}
+}`
+
+func createImagesServer() http.Handler {
+	router := http.NewServeMux()
+
+	router.HandleFunc(actionsURL, func(rw http.ResponseWriter, r *http.Request) {
+		if r.FormValue("prop") != "imageinfo" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_, _ = rw.Write([]byte(imagesInfoTestResponse))
+	})
+
+	return router
+}
+
+func TestImagesInfo(t *testing.T) {
+	assert := assert.New(t)
+	srv := httptest.NewServer(createImagesServer())
+	defer srv.Close()
+
+	cl := NewClient(srv.URL, map[string]string{})
+	infos, err := cl.ImagesInfo(context.Background(), []string{"File:Earth.jpg"})
+
+	assert.NoError(err)
+	assert.Contains(infos, "File:Earth.jpg")
+
+	info := infos["File:Earth.jpg"]
+	assert.Equal("image/jpeg", info.Mime)
+	assert.Equal(1024, info.Size)
+	assert.Equal(640, info.Width)
+	assert.Equal(480, info.Height)
+	assert.Equal(10, info.UserID)
+	assert.Equal("cc-by-sa-4.0", info.Meta("License"))
+	assert.Equal("CC BY-SA 4.0", info.Meta("LicenseShortName"))
+	assert.Equal("", info.Meta("UsageTerms"))
+}
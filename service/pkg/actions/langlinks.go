@@ -0,0 +1,24 @@
+package actions
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// LangLink interlanguage link of the page
+type LangLink struct {
+	Lang  string `json:"lang"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// SiteURL get site url (scheme and host) of the link
+func SiteURL(link string) (string, error) {
+	u, err := url.Parse(link)
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s://%s", u.Scheme, u.Host), nil
+}
@@ -0,0 +1,64 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+)
+
+// Props page props of the list of titles, titles without the prop are omitted
+type Props struct {
+	LangLinks map[string][]LangLink
+	Images    map[string][]string
+	Redirects map[string]Redirect
+}
+
+// NewProps create empty page props
+func NewProps() *Props {
+	return &Props{
+		LangLinks: map[string][]LangLink{},
+		Images:    map[string][]string{},
+		Redirects: map[string]Redirect{},
+	}
+}
+
+// Props get interlanguage links, used files and redirect targets for the list of titles in a single query
+func (cl *Client) Props(ctx context.Context, titles []string) (*Props, error) {
+	props := NewProps()
+	params := url.Values{
+		"prop":    []string{"langlinks|images"},
+		"llprop":  []string{"url"},
+		"lllimit": []string{"max"},
+		"imlimit": []string{"max"},
+	}
+
+	redirects, err := cl.query(ctx, params, titles, func(title string, data []byte) error {
+		pg := new(struct {
+			LangLinks []LangLink `json:"langlinks"`
+			Images    []struct {
+				Title string `json:"title"`
+			} `json:"images"`
+		})
+
+		if err := json.Unmarshal(data, pg); err != nil {
+			return err
+		}
+
+		if len(pg.LangLinks) > 0 {
+			props.LangLinks[title] = append(props.LangLinks[title], pg.LangLinks...)
+		}
+
+		for _, image := range pg.Images {
+			props.Images[title] = append(props.Images[title], image.Title)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	props.Redirects = redirects
+	return props, nil
+}
@@ -0,0 +1,110 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const propsTestFirst = `{
+	"continue": {"llcontinue": "9228|de", "continue": "||images"},
+	"query": {
+		"normalized": [{"fromencoded": false, "from": "Earth_(planet)", "to": "Earth (planet)"}],
+		"redirects": [
+			{"from": "Earth (planet)", "to": "Earth"},
+			{"from": "Earth orbit", "to": "Earth", "tofragment": "Orbit"}
+		],
+		"pages": [
+			{"pageid": 9228, "ns": 0, "title": "Earth", "langlinks": [{"lang": "af", "title": "Aarde", "url": "https://af.wikipedia.org/wiki/Aarde"}]},
+			{"ns": 0, "title": "Missing", "missing": true}
+		]
+	}
+}`
+
+const propsTestSecond = `{
+	"continue": {"imcontinue": "9228|Moon.png", "continue": "||langlinks"},
+	"query": {
+		"pages": [
+			{
+				"pageid": 9228,
+				"ns": 0,
+				"title": "Earth",
+				"langlinks": [{"lang": "de", "title": "Erde", "url": "https://de.wikipedia.org/wiki/Erde"}],
+				"images": [{"ns": 6, "title": "File:Earth.jpg"}]
+			}
+		]
+	}
+}`
+
+const propsTestThird = `{
+	"batchcomplete": true,
+	"query": {
+		"pages": [
+			{"pageid": 9228, "ns": 0, "title": "Earth", "images": [{"ns": 6, "title": "File:Moon.png"}]}
+		]
+	}
+}`
+
+func createPropsServer() http.Handler {
+	router := http.NewServeMux()
+
+	router.HandleFunc(actionsURL, func(rw http.ResponseWriter, r *http.Request) {
+		if r.FormValue("prop") != "langlinks|images" || r.FormValue("redirects") != "1" || r.Header.Get("User-Agent") != "test" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch {
+		case r.FormValue("titles") == "Loop":
+			_, _ = rw.Write([]byte(propsTestFirst))
+		case len(r.FormValue("imcontinue")) > 0 && len(r.FormValue("llcontinue")) == 0:
+			_, _ = rw.Write([]byte(propsTestThird))
+		case len(r.FormValue("llcontinue")) > 0:
+			_, _ = rw.Write([]byte(propsTestSecond))
+		default:
+			_, _ = rw.Write([]byte(propsTestFirst))
+		}
+	})
+
+	return router
+}
+
+func TestProps(t *testing.T) {
+	assert := assert.New(t)
+	srv := httptest.NewServer(createPropsServer())
+	defer srv.Close()
+
+	t.Run("props success", func(t *testing.T) {
+		cl := NewClient(srv.URL, map[string]string{"User-Agent": "test"})
+		props, err := cl.Props(context.Background(), []string{"Earth_(planet)", "Earth orbit", "Missing"})
+
+		assert.NoError(err)
+		assert.Len(props.LangLinks, 1)
+		assert.Equal([]LangLink{
+			{"af", "Aarde", "https://af.wikipedia.org/wiki/Aarde"},
+			{"de", "Erde", "https://de.wikipedia.org/wiki/Erde"},
+		}, props.LangLinks["Earth_(planet)"])
+		assert.Len(props.Images, 1)
+		assert.Equal([]string{"File:Earth.jpg", "File:Moon.png"}, props.Images["Earth_(planet)"])
+		assert.Len(props.Redirects, 2)
+		assert.Equal(Redirect{Title: "Earth"}, props.Redirects["Earth_(planet)"])
+		assert.Equal(Redirect{Title: "Earth", Fragment: "Orbit"}, props.Redirects["Earth orbit"])
+	})
+
+	t.Run("props too many continues", func(t *testing.T) {
+		cl := NewClient(srv.URL, map[string]string{"User-Agent": "test"})
+		_, err := cl.Props(context.Background(), []string{"Loop"})
+
+		assert.Equal(ErrTooManyContinues, err)
+	})
+
+	t.Run("props error", func(t *testing.T) {
+		cl := NewClient(srv.URL, map[string]string{})
+		_, err := cl.Props(context.Background(), []string{"Earth"})
+
+		assert.Error(err)
+	})
+}
@@ -0,0 +1,7 @@
+package actions
+
+// Redirect target of the redirect title
+type Redirect struct {
+	Title    string
+	Fragment string
+}
@@ -0,0 +1,65 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/protsack-stephan/mediawiki-api-client"
+)
+
+// batchRevisions max number of revisions in a single request
+const batchRevisions = 50
+
+// Revisions get revisions with main slot content by identifiers, missing or deleted revisions are omitted
+func (cl *Client) Revisions(ctx context.Context, ids []int) (map[int]mediawiki.PageDataRevision, error) {
+	revs := map[int]mediawiki.PageDataRevision{}
+
+	for i := 0; i < len(ids); i += batchRevisions {
+		end := i + batchRevisions
+
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		revids := []string{}
+
+		for _, id := range ids[i:end] {
+			revids = append(revids, strconv.Itoa(id))
+		}
+
+		params := url.Values{
+			"action":        []string{"query"},
+			"format":        []string{"json"},
+			"formatversion": []string{"2"},
+			"prop":          []string{"revisions"},
+			"rvprop":        []string{"ids|timestamp|flags|comment|user|userid|tags|content"},
+			"rvslots":       []string{"main"},
+			"revids":        []string{strings.Join(revids, "|")},
+		}
+
+		res, err := cl.post(ctx, params)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, data := range res.Query.Pages {
+			pg := new(struct {
+				Revisions []mediawiki.PageDataRevision `json:"revisions"`
+			})
+
+			if err := json.Unmarshal(data, pg); err != nil {
+				return nil, err
+			}
+
+			for _, rev := range pg.Revisions {
+				revs[rev.RevID] = rev
+			}
+		}
+	}
+
+	return revs, nil
+}
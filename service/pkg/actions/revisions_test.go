@@ -0,0 +1,78 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const revisionsTestResponse = `{
+	"batchcomplete": true,
+	"query": {
+		"pages": [
+			{
+				"pageid": 9228,
+				"ns": 0,
+				"title": "Earth",
+				"revisions": [
+					{
+						"revid": 100,
+						"parentid": 99,
+						"minor": true,
+						"user": "Reviewer",
+						"userid": 10,
+						"timestamp": "2021-05-01T10:00:00Z",
+						"comment": "stable",
+						"tags": ["mobile edit"],
+						"slots": {"main": {"contentmodel": "wikitext", "contentformat": "text/x-wiki", "content": "stable wikitext"}}
+					}
+				]
+			}
+		],
+		"badrevids": {"404": {"revid": 404, "missing": true}}
+	}
+}`
+
+func createRevisionsServer() http.Handler {
+	router := http.NewServeMux()
+
+	router.HandleFunc(actionsURL, func(rw http.ResponseWriter, r *http.Request) {
+		if r.FormValue("prop") != "revisions" || r.FormValue("revids") != "100|404" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_, _ = rw.Write([]byte(revisionsTestResponse))
+	})
+
+	return router
+}
+
+func TestRevisions(t *testing.T) {
+	assert := assert.New(t)
+	srv := httptest.NewServer(createRevisionsServer())
+	defer srv.Close()
+
+	cl := NewClient(srv.URL, map[string]string{})
+
+	t.Run("revisions success", func(t *testing.T) {
+		revs, err := cl.Revisions(context.Background(), []int{100, 404})
+
+		assert.NoError(err)
+		assert.Len(revs, 1)
+		assert.Equal(99, revs[100].ParentID)
+		assert.Equal("Reviewer", revs[100].User)
+		assert.Equal("stable", revs[100].Comment)
+		assert.True(revs[100].Minor)
+		assert.Equal("stable wikitext", revs[100].Slots.Main.Content)
+	})
+
+	t.Run("revisions error", func(t *testing.T) {
+		_, err := cl.Revisions(context.Background(), []int{100})
+
+		assert.Error(err)
+	})
+}
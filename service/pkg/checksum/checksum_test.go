@@ -0,0 +1,21 @@
+package checksum
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter(t *testing.T) {
+	assert := assert.New(t)
+	w := NewWriter()
+
+	_, err := io.Copy(w, strings.NewReader("{\"name\":\"Earth\"}\n{\"name\":\"Moon\"}\n"))
+	assert.NoError(err)
+	assert.Equal("fb86c497083747d6410780931ca7a414ae8ba22eef2c167295fd7bb0f450b9fd", w.Sum())
+	assert.Equal(int64(33), w.Size())
+	assert.Equal(2, w.Lines())
+	assert.Equal("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", NewWriter().Sum())
+}
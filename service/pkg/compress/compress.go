@@ -0,0 +1,90 @@
+// Package compress transparent compression of stored page json files.
+// Encoding is detected from the magic bytes of the data, so compressed and old uncompressed files can be mixed.
+package compress
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+)
+
+// Gzip encoding name
+const Gzip = "gzip"
+
+// ErrUnknownEncoding encoding is not supported
+var ErrUnknownEncoding = errors.New("unknown encoding")
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (rc *readCloser) Close() error {
+	var err error
+
+	for _, closer := range rc.closers {
+		if cerr := closer.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// Encode compress data with the encoding, empty encoding keeps the data as is
+func Encode(enc string, data []byte) ([]byte, error) {
+	switch enc {
+	case "":
+		return data, nil
+	case Gzip:
+		buf := new(bytes.Buffer)
+		gzw := gzip.NewWriter(buf)
+
+		if _, err := gzw.Write(data); err != nil {
+			return nil, err
+		}
+
+		if err := gzw.Close(); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	return nil, ErrUnknownEncoding
+}
+
+// NewReader detect the encoding of the stream and decode it, uncompressed data is read as is
+func NewReader(rc io.ReadCloser) (io.ReadCloser, error) {
+	brd := bufio.NewReader(rc)
+
+	if magic, err := brd.Peek(len(gzipMagic)); err != nil || !bytes.Equal(magic, gzipMagic) {
+		return &readCloser{brd, []io.Closer{rc}}, nil
+	}
+
+	gzr, err := gzip.NewReader(brd)
+
+	if err != nil {
+		_ = rc.Close()
+		return nil, err
+	}
+
+	return &readCloser{gzr, []io.Closer{gzr, rc}}, nil
+}
+
+// Decode detect the encoding of the data and decode it
+func Decode(data []byte) ([]byte, error) {
+	rc, err := NewReader(ioutil.NopCloser(bytes.NewReader(data)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
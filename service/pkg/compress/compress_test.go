@@ -0,0 +1,45 @@
+package compress
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const compressTestData = `{"name":"Earth","article_body":{"html":"<p>Earth</p>"}}`
+
+func TestCompress(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, enc := range []string{"", Gzip} {
+		data, err := Encode(enc, []byte(compressTestData))
+		assert.NoError(err)
+
+		if len(enc) > 0 {
+			assert.NotEqual(compressTestData, string(data))
+		}
+
+		decoded, err := Decode(data)
+		assert.NoError(err)
+		assert.Equal(compressTestData, string(decoded))
+
+		rc, err := NewReader(ioutil.NopCloser(strings.NewReader(string(data))))
+		assert.NoError(err)
+		decoded, err = ioutil.ReadAll(rc)
+		assert.NoError(err)
+		assert.NoError(rc.Close())
+		assert.Equal(compressTestData, string(decoded))
+	}
+
+	_, err := Encode("brotli", []byte(compressTestData))
+	assert.Equal(ErrUnknownEncoding, err)
+
+	decoded, err := Decode([]byte("{"))
+	assert.NoError(err)
+	assert.Equal("{", string(decoded))
+
+	_, err = Decode([]byte{0x1f, 0x8b, 0x00})
+	assert.Error(err)
+}
@@ -0,0 +1,71 @@
+// Package editors shared cache of the editor profiles (registration date, edit count, groups).
+package editors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"okapi-data-service/schema/v3"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Expire time to live of the cached editor profile
+const Expire = time.Hour * 24
+
+// Key redis key for the editor profile
+func Key(dbName string, id int) string {
+	return fmt.Sprintf("editor/%s/%d", dbName, id)
+}
+
+// Get editor profile from the cache
+func Get(ctx context.Context, store redis.Cmdable, dbName string, id int) (*schema.Editor, error) {
+	data, err := store.Get(ctx, Key(dbName, id)).Bytes()
+
+	if err != nil {
+		return nil, err
+	}
+
+	editor := new(schema.Editor)
+
+	if err := json.Unmarshal(data, editor); err != nil {
+		return nil, err
+	}
+
+	return editor, nil
+}
+
+// Set put editor profile to the cache, anonymous editors are skipped
+func Set(ctx context.Context, store redis.Cmdable, dbName string, editor *schema.Editor) error {
+	if store == nil || editor == nil || editor.Identifier == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(editor)
+
+	if err != nil {
+		return err
+	}
+
+	return store.Set(ctx, Key(dbName, editor.Identifier), data, Expire).Err()
+}
+
+// Fill set editor profile fields from the cache, returns false if profile is not cached
+func Fill(ctx context.Context, store redis.Cmdable, dbName string, editor *schema.Editor) bool {
+	if store == nil || editor == nil || editor.Identifier == 0 {
+		return false
+	}
+
+	profile, err := Get(ctx, store, dbName, editor.Identifier)
+
+	if err != nil {
+		return false
+	}
+
+	editor.DateStarted = profile.DateStarted
+	editor.EditCount = profile.EditCount
+	editor.Groups = profile.Groups
+	editor.IsBot = profile.IsBot
+	return true
+}
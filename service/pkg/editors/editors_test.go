@@ -0,0 +1,82 @@
+package editors
+
+import (
+	"context"
+	"okapi-data-service/schema/v3"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+const editorsTestDbName = "enwiki"
+
+type editorsRedisMock struct {
+	redis.Cmdable
+	data   map[string]string
+	expire time.Duration
+}
+
+func (r *editorsRedisMock) Get(_ context.Context, key string) *redis.StringCmd {
+	if val, ok := r.data[key]; ok {
+		return redis.NewStringResult(val, nil)
+	}
+
+	return redis.NewStringResult("", redis.Nil)
+}
+
+func (r *editorsRedisMock) Set(_ context.Context, key string, value interface{}, expire time.Duration) *redis.StatusCmd {
+	r.data[key] = string(value.([]byte))
+	r.expire = expire
+	return redis.NewStatusResult("OK", nil)
+}
+
+func TestEditors(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	store := &editorsRedisMock{data: map[string]string{}}
+	started := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("set and get", func(t *testing.T) {
+		assert.NoError(Set(ctx, store, editorsTestDbName, &schema.Editor{
+			Identifier:  10,
+			Name:        "Editor",
+			EditCount:   100,
+			Groups:      []string{"bot"},
+			IsBot:       true,
+			DateStarted: &started,
+		}))
+		assert.Contains(store.data, Key(editorsTestDbName, 10))
+		assert.Equal(Expire, store.expire)
+
+		editor, err := Get(ctx, store, editorsTestDbName, 10)
+		assert.NoError(err)
+		assert.Equal(100, editor.EditCount)
+		assert.Equal(started, *editor.DateStarted)
+	})
+
+	t.Run("set anonymous", func(t *testing.T) {
+		assert.NoError(Set(ctx, store, editorsTestDbName, &schema.Editor{Name: "127.0.0.1", IsAnonymous: true}))
+		assert.NotContains(store.data, Key(editorsTestDbName, 0))
+	})
+
+	t.Run("get missing", func(t *testing.T) {
+		_, err := Get(ctx, store, editorsTestDbName, 11)
+		assert.Equal(redis.Nil, err)
+	})
+
+	t.Run("fill", func(t *testing.T) {
+		editor := &schema.Editor{Identifier: 10, Name: "Editor"}
+		assert.True(Fill(ctx, store, editorsTestDbName, editor))
+		assert.Equal(100, editor.EditCount)
+		assert.Equal([]string{"bot"}, editor.Groups)
+		assert.True(editor.IsBot)
+		assert.Equal(started, *editor.DateStarted)
+
+		missing := &schema.Editor{Identifier: 11}
+		assert.False(Fill(ctx, store, editorsTestDbName, missing))
+		assert.Zero(missing.EditCount)
+		assert.False(Fill(ctx, nil, editorsTestDbName, editor))
+	})
+}
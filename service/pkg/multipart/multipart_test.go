@@ -0,0 +1,153 @@
+package multipart
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/protsack-stephan/dev-toolkit/lib/fs"
+	"github.com/stretchr/testify/assert"
+)
+
+const multipartTestPath = "export/enwiki/enwiki_json_0.tar.gz"
+
+var errMultipartTest = errors.New("connection reset")
+
+// failingUploader fails the upload of the given part
+type failingUploader struct {
+	Uploader
+	fail int
+}
+
+func (u *failingUploader) Upload(path string, id string, num int, body io.ReadSeeker) (string, error) {
+	if num == u.fail {
+		return "", errMultipartTest
+	}
+
+	return u.Uploader.Upload(path, id, num, body)
+}
+
+func multipartTestData() []byte {
+	return bytes.Repeat([]byte("0123456789"), 10)
+}
+
+func TestWriter(t *testing.T) {
+	assert := assert.New(t)
+	data := multipartTestData()
+
+	t.Run("upload in parts", func(t *testing.T) {
+		vol := t.TempDir()
+		tracker := fs.NewStorage(t.TempDir())
+		w, err := NewWriter(NewFS(vol), tracker, multipartTestPath)
+		assert.NoError(err)
+		w.PartSize = 30
+
+		_, err = io.Copy(w, bytes.NewReader(data))
+		assert.NoError(err)
+		assert.Len(w.state.Parts, 3)
+		assert.NoError(w.Close())
+
+		stored, err := ioutil.ReadFile(filepath.Join(vol, multipartTestPath))
+		assert.NoError(err)
+		assert.Equal(data, stored)
+
+		_, err = tracker.Get("multipart/" + multipartTestPath + ".json")
+		assert.Error(err)
+	})
+
+	t.Run("empty upload", func(t *testing.T) {
+		vol := t.TempDir()
+		w, err := NewWriter(NewFS(vol), fs.NewStorage(t.TempDir()), multipartTestPath)
+		assert.NoError(err)
+		assert.NoError(w.Close())
+
+		stored, err := ioutil.ReadFile(filepath.Join(vol, multipartTestPath))
+		assert.NoError(err)
+		assert.Empty(stored)
+	})
+
+	t.Run("resume upload", func(t *testing.T) {
+		vol := t.TempDir()
+		tracker := fs.NewStorage(t.TempDir())
+		w, err := NewWriter(&failingUploader{NewFS(vol), 3}, tracker, multipartTestPath)
+		assert.NoError(err)
+		w.PartSize = 30
+
+		_, err = io.Copy(w, bytes.NewReader(data))
+		assert.Equal(errMultipartTest, err)
+
+		w, err = NewWriter(NewFS(vol), tracker, multipartTestPath)
+		assert.NoError(err)
+		w.PartSize = 30
+
+		_, err = io.Copy(w, bytes.NewReader(data))
+		assert.NoError(err)
+		assert.NoError(w.Close())
+		assert.Equal(2, w.Reused())
+
+		stored, err := ioutil.ReadFile(filepath.Join(vol, multipartTestPath))
+		assert.NoError(err)
+		assert.Equal(data, stored)
+	})
+
+	t.Run("resume with changed data", func(t *testing.T) {
+		vol := t.TempDir()
+		tracker := fs.NewStorage(t.TempDir())
+		w, err := NewWriter(&failingUploader{NewFS(vol), 3}, tracker, multipartTestPath)
+		assert.NoError(err)
+		w.PartSize = 30
+
+		_, err = io.Copy(w, bytes.NewReader(data))
+		assert.Error(err)
+
+		changed := append([]byte("x"), data[1:]...)
+		w, err = NewWriter(NewFS(vol), tracker, multipartTestPath)
+		assert.NoError(err)
+		w.PartSize = 30
+
+		_, err = io.Copy(w, bytes.NewReader(changed))
+		assert.NoError(err)
+		assert.NoError(w.Close())
+		assert.Zero(w.Reused())
+
+		stored, err := ioutil.ReadFile(filepath.Join(vol, multipartTestPath))
+		assert.NoError(err)
+		assert.Equal(changed, stored)
+	})
+
+	t.Run("resume aborted upload", func(t *testing.T) {
+		vol := t.TempDir()
+		tracker := fs.NewStorage(t.TempDir())
+		up := NewFS(vol)
+		w, err := NewWriter(&failingUploader{up, 2}, tracker, multipartTestPath)
+		assert.NoError(err)
+		w.PartSize = 30
+
+		_, err = io.Copy(w, bytes.NewReader(data))
+		assert.Error(err)
+		assert.NoError(up.Abort(multipartTestPath, w.state.UploadID))
+
+		w, err = NewWriter(up, tracker, multipartTestPath)
+		assert.NoError(err)
+		w.PartSize = 30
+
+		_, err = io.Copy(w, bytes.NewReader(data))
+		assert.NoError(err)
+		assert.NoError(w.Close())
+		assert.Zero(w.Reused())
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		vol := t.TempDir()
+		up := NewFS(vol)
+		w, err := NewWriter(up, fs.NewStorage(t.TempDir()), multipartTestPath)
+		assert.NoError(err)
+		assert.NoError(w.Abort())
+
+		_, err = up.List(multipartTestPath, w.state.UploadID)
+		assert.Equal(ErrNoSuchUpload, err)
+	})
+}
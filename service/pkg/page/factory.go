@@ -3,6 +3,8 @@ package page
 import (
 	"fmt"
 	"okapi-data-service/models"
+	"okapi-data-service/pkg/actions"
+	"okapi-data-service/pkg/wikitext"
 	schema "okapi-data-service/schema/v3"
 	"strings"
 	"time"
@@ -10,13 +12,16 @@ import (
 	"github.com/protsack-stephan/mediawiki-api-client"
 )
 
-const wikidataURL = "http://www.wikidata.org/entity/"
+// WikidataURL base url of the wikidata entities
+const WikidataURL = "http://www.wikidata.org/entity/"
 
 // Factory create schema.org article
 type Factory struct {
 	Project   *models.Project
 	Language  *models.Language
 	Namespace *models.Namespace
+	License   *schema.License // license of the project namespace, default one is used if not set
+	Templates []string        // template names (or name prefixes) to extract parameters from
 }
 
 // Create change mediawiki page into schema.org article
@@ -51,38 +56,21 @@ func (f *Factory) Create(data *mediawiki.PageData, html string) *schema.Page {
 		},
 	}
 
-	// Custom license for wikinews projects.
-	if f.Project.SiteCode == "wikinews" {
-		page.License = []*schema.License{
-			{
-				Name:       "Attribution 2.5 Generic",
-				Identifier: "CC-BY-2.5",
-				URL:        "https://creativecommons.org/licenses/by/2.5/",
-			},
-		}
+	if f.License != nil {
+		license := *f.License
+		page.License = []*schema.License{&license}
 	}
 
 	if len(data.Revisions) > 0 {
 		page.DateModified = &data.Revisions[0].Timestamp
 		page.ArticleBody.Wikitext = data.Revisions[0].Slots.Main.Content
-		page.Version = &schema.Version{
-			Identifier:      data.LastRevID,
-			Comment:         data.Revisions[0].Comment,
-			Tags:            data.Revisions[0].Tags,
-			IsMinorEdit:     data.Revisions[0].Minor,
-			IsFlaggedStable: data.Flagged.StableRevID == data.LastRevID,
-			Editor: &schema.Editor{
-				Identifier:  data.Revisions[0].UserID,
-				Name:        data.Revisions[0].User,
-				IsAnonymous: data.Revisions[0].UserID == 0,
-			},
-		}
+		page.Version = f.Version(data.LastRevID, &data.Revisions[0], &data.Flagged)
 	}
 
 	if len(data.Pageprops.WikibaseItem) != 0 {
 		page.MainEntity = &schema.Entity{
 			Identifier: data.Pageprops.WikibaseItem,
-			URL:        fmt.Sprintf("%s%s", wikidataURL, data.Pageprops.WikibaseItem),
+			URL:        fmt.Sprintf("%s%s", WikidataURL, data.Pageprops.WikibaseItem),
 		}
 	}
 
@@ -100,7 +88,7 @@ func (f *Factory) Create(data *mediawiki.PageData, html string) *schema.Page {
 		for id, aspects := range data.WbEntityUsage {
 			page.AdditionalEntities = append(page.AdditionalEntities, &schema.Entity{
 				Identifier: id,
-				URL:        fmt.Sprintf("%s%s", wikidataURL, id),
+				URL:        fmt.Sprintf("%s%s", WikidataURL, id),
 				Aspects:    aspects.Aspects,
 			})
 		}
@@ -126,6 +114,17 @@ func (f *Factory) Create(data *mediawiki.PageData, html string) *schema.Page {
 		}
 	}
 
+	if len(f.Templates) > 0 && len(page.ArticleBody.Wikitext) > 0 {
+		for _, template := range wikitext.Templates(page.ArticleBody.Wikitext) {
+			if f.extract(template.Name) {
+				page.TemplateData = append(page.TemplateData, &schema.TemplateData{
+					Name:       template.Name,
+					Parameters: template.Parameters,
+				})
+			}
+		}
+	}
+
 	if len(data.Redirects) > 0 {
 		for _, redirect := range data.Redirects {
 			page.Redirects = append(page.Redirects, &schema.Page{
@@ -137,3 +136,70 @@ func (f *Factory) Create(data *mediawiki.PageData, html string) *schema.Page {
 
 	return page
 }
+
+// Version create page version out of the revision
+func (f *Factory) Version(id int, rev *mediawiki.PageDataRevision, flagged *mediawiki.PageDataFlagged) *schema.Version {
+	version := &schema.Version{
+		Identifier:      id,
+		Comment:         rev.Comment,
+		Tags:            rev.Tags,
+		IsMinorEdit:     rev.Minor,
+		IsFlaggedStable: flagged.StableRevID == id,
+	}
+
+	// Editor name is missing when the user is hidden (suppressed) on the revision.
+	if len(rev.User) > 0 {
+		version.Editor = &schema.Editor{
+			Identifier:  rev.UserID,
+			Name:        rev.User,
+			IsAnonymous: rev.UserID == 0,
+		}
+	}
+
+	return version
+}
+
+// CreateFile change file metadata into schema file
+func (f *Factory) CreateFile(info *actions.ImageInfo) *schema.File {
+	file := &schema.File{
+		URL:      info.URL,
+		MimeType: info.Mime,
+		Width:    info.Width,
+		Height:   info.Height,
+		Size:     info.Size,
+	}
+
+	if len(info.User) > 0 {
+		file.Uploader = &schema.Editor{
+			Identifier:  info.UserID,
+			Name:        info.User,
+			IsAnonymous: info.UserID == 0,
+		}
+	}
+
+	if name := info.Meta("LicenseShortName"); len(name) > 0 {
+		file.License = &schema.License{
+			Name:       name,
+			Identifier: strings.ToUpper(info.Meta("License")),
+			URL:        info.Meta("LicenseUrl"),
+		}
+
+		if len(file.License.Identifier) == 0 {
+			file.License.Identifier = name
+		}
+	}
+
+	return file
+}
+
+func (f *Factory) extract(name string) bool {
+	for _, prefix := range f.Templates {
+		prefix = wikitext.NormalizeName(prefix)
+
+		if len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,6 +3,7 @@ package page
 import (
 	"fmt"
 	"okapi-data-service/models"
+	"okapi-data-service/pkg/actions"
 	"okapi-data-service/schema/v3"
 	"strings"
 	"testing"
@@ -97,8 +98,8 @@ func TestFactory(t *testing.T) {
 	}
 }
 
-// TestWikinewsLicense tests the license for wikinews projects.
-func TestWikinewsLicense(t *testing.T) {
+// TestFactoryLicense tests the configured project license.
+func TestFactoryLicense(t *testing.T) {
 	assert := assert.New(t)
 
 	fact := new(Factory)
@@ -111,6 +112,11 @@ func TestWikinewsLicense(t *testing.T) {
 		Code: "ar",
 	}
 	fact.Namespace = factoryTestNamespace
+	fact.License = &schema.License{
+		Name:       "Attribution 2.5 Generic",
+		Identifier: "CC-BY-2.5",
+		URL:        "https://creativecommons.org/licenses/by/2.5/",
+	}
 
 	data := &mediawiki.PageData{
 		Revisions: []mediawiki.PageDataRevision{
@@ -119,6 +125,8 @@ func TestWikinewsLicense(t *testing.T) {
 	}
 
 	page := fact.Create(data, factoryTestHTML)
+	assert.Len(page.License, 1)
+	assert.NotSame(fact.License, page.License[0])
 
 	for _, license := range page.License {
 		assert.Equal("CC-BY-2.5", license.Identifier)
@@ -126,3 +134,66 @@ func TestWikinewsLicense(t *testing.T) {
 		assert.Equal("https://creativecommons.org/licenses/by/2.5/", license.URL)
 	}
 }
+
+func TestFactoryTemplateData(t *testing.T) {
+	assert := assert.New(t)
+
+	fact := new(Factory)
+	fact.Project = factoryTestProject
+	fact.Language = factoryTestLanguage
+	fact.Namespace = factoryTestNamespace
+	fact.Templates = []string{"Infobox"}
+
+	data := &mediawiki.PageData{
+		Revisions: []mediawiki.PageDataRevision{
+			{},
+		},
+	}
+	data.Revisions[0].Slots.Main.Content = "{{Infobox planet|name=Earth|{{val|1}}}}{{Cite web|url=example.com}}"
+
+	page := fact.Create(data, factoryTestHTML)
+	assert.Len(page.TemplateData, 1)
+	assert.Equal("Infobox planet", page.TemplateData[0].Name)
+	assert.Equal("Earth", page.TemplateData[0].Parameters["name"])
+	assert.Equal("{{val|1}}", page.TemplateData[0].Parameters["1"])
+
+	fact.Templates = []string{}
+	assert.Empty(fact.Create(data, factoryTestHTML).TemplateData)
+}
+
+func TestFactoryCreateFile(t *testing.T) {
+	assert := assert.New(t)
+
+	fact := new(Factory)
+	fact.Project = factoryTestProject
+
+	info := &actions.ImageInfo{
+		URL:    "https://upload.wikimedia.org/wikipedia/commons/e/e1/Earth.jpg",
+		Mime:   "image/jpeg",
+		Size:   1024,
+		Width:  640,
+		Height: 480,
+		User:   "Uploader",
+		UserID: 10,
+		ExtMetadata: map[string]actions.ExtMetadata{
+			"License":          {Value: "cc-by-sa-4.0"},
+			"LicenseShortName": {Value: "CC BY-SA 4.0"},
+			"LicenseUrl":       {Value: "https://creativecommons.org/licenses/by-sa/4.0"},
+		},
+	}
+
+	file := fact.CreateFile(info)
+	assert.Equal(info.URL, file.URL)
+	assert.Equal(info.Mime, file.MimeType)
+	assert.Equal(info.Size, file.Size)
+	assert.Equal(info.Width, file.Width)
+	assert.Equal(info.Height, file.Height)
+	assert.Equal(info.UserID, file.Uploader.Identifier)
+	assert.Equal(info.User, file.Uploader.Name)
+	assert.Equal("CC-BY-SA-4.0", file.License.Identifier)
+	assert.Equal("CC BY-SA 4.0", file.License.Name)
+	assert.Equal("https://creativecommons.org/licenses/by-sa/4.0", file.License.URL)
+
+	info.ExtMetadata = nil
+	assert.Nil(fact.CreateFile(info).License)
+}
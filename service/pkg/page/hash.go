@@ -0,0 +1,92 @@
+package page
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"okapi-data-service/schema/v3"
+)
+
+// hashVersion version fields that change only with the new revision
+type hashVersion struct {
+	Identifier      int      `json:"identifier"`
+	Comment         string   `json:"comment"`
+	Tags            []string `json:"tags"`
+	IsMinorEdit     bool     `json:"is_minor_edit"`
+	IsFlaggedStable bool     `json:"is_flagged_stable"`
+}
+
+// hashContent page fields that make up the page content, the ones that change without an edit
+// (editor stats, scores, wikidata labels, modification date) are left out
+type hashContent struct {
+	Name               string                 `json:"name"`
+	Identifier         int                    `json:"identifier"`
+	URL                string                 `json:"url"`
+	Protection         []*schema.Protection   `json:"protection"`
+	Version            *hashVersion           `json:"version"`
+	LatestVersion      int                    `json:"latest_version"`
+	Namespace          *schema.Namespace      `json:"namespace"`
+	MainEntity         string                 `json:"main_entity"`
+	AdditionalEntities []string               `json:"additional_entities"`
+	Categories         []*schema.Page         `json:"categories"`
+	Templates          []*schema.Page         `json:"templates"`
+	TemplateData       []*schema.TemplateData `json:"template_data"`
+	Redirects          []*schema.Page         `json:"redirects"`
+	LangLinks          []*schema.Page         `json:"lang_links"`
+	Images             []*schema.Page         `json:"images"`
+	File               *schema.File           `json:"file"`
+	ArticleBody        *schema.ArticleBody    `json:"article_body"`
+	License            []*schema.License      `json:"license"`
+	Visibility         *schema.Visibility     `json:"visibility"`
+}
+
+// Hash calculate stable hash over the page content fields, so the hash changes only when the content does
+func Hash(page *schema.Page) (string, error) {
+	cnt := &hashContent{
+		Name:         page.Name,
+		Identifier:   page.Identifier,
+		URL:          page.URL,
+		Protection:   page.Protection,
+		Namespace:    page.Namespace,
+		Categories:   page.Categories,
+		Templates:    page.Templates,
+		TemplateData: page.TemplateData,
+		Redirects:    page.Redirects,
+		LangLinks:    page.LangLinks,
+		Images:       page.Images,
+		File:         page.File,
+		ArticleBody:  page.ArticleBody,
+		License:      page.License,
+		Visibility:   page.Visibility,
+	}
+
+	if page.Version != nil {
+		cnt.Version = &hashVersion{
+			Identifier:      page.Version.Identifier,
+			Comment:         page.Version.Comment,
+			Tags:            page.Version.Tags,
+			IsMinorEdit:     page.Version.IsMinorEdit,
+			IsFlaggedStable: page.Version.IsFlaggedStable,
+		}
+	}
+
+	if page.LatestVersion != nil {
+		cnt.LatestVersion = page.LatestVersion.Identifier
+	}
+
+	if page.MainEntity != nil {
+		cnt.MainEntity = page.MainEntity.Identifier
+	}
+
+	for _, ent := range page.AdditionalEntities {
+		cnt.AdditionalEntities = append(cnt.AdditionalEntities, ent.Identifier)
+	}
+
+	data, err := json.Marshal(cnt)
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
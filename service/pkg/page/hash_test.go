@@ -0,0 +1,50 @@
+package page
+
+import (
+	"okapi-data-service/schema/v3"
+	"testing"
+	"time"
+
+	ores "github.com/protsack-stephan/mediawiki-ores-client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHash(t *testing.T) {
+	assert := assert.New(t)
+	page := &schema.Page{
+		Name:        "Earth",
+		Identifier:  100,
+		Version:     &schema.Version{Identifier: 1},
+		ArticleBody: &schema.ArticleBody{Wikitext: "wikitext"},
+	}
+
+	hash, err := Hash(page)
+	assert.NoError(err)
+	assert.Len(hash, 64)
+
+	page.ContentHash = hash
+	same, err := Hash(page)
+	assert.NoError(err)
+	assert.Equal(hash, same)
+	assert.Equal(hash, page.ContentHash)
+
+	dt := time.Now()
+	page.DateModified = &dt
+	page.MainEntity = &schema.Entity{Identifier: "Q2", Name: "Earth"}
+	withEntity, err := Hash(page)
+	assert.NoError(err)
+	assert.NotEqual(hash, withEntity)
+
+	page.Version.Editor = &schema.Editor{Identifier: 1, EditCount: 10, Groups: []string{"user"}}
+	page.Version.Scores = &schema.Scores{Damaging: &ores.ScoreDamaging{Prediction: true}}
+	page.MainEntity.Name = "Terra"
+	page.MainEntity.Description = "third planet from the Sun"
+	metadata, err := Hash(page)
+	assert.NoError(err)
+	assert.Equal(withEntity, metadata)
+
+	page.ArticleBody.Wikitext = "changed"
+	changed, err := Hash(page)
+	assert.NoError(err)
+	assert.NotEqual(withEntity, changed)
+}
@@ -0,0 +1,32 @@
+package page
+
+import (
+	"context"
+	"okapi-data-service/models"
+	"okapi-data-service/schema/v3"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/protsack-stephan/dev-toolkit/pkg/repository"
+)
+
+// License get license of the project namespace, namespace specific license takes precedence
+// over the project one and the default license is used if neither is configured
+func License(ctx context.Context, repo repository.Finder, dbName string, ns int) (*schema.License, error) {
+	lics := []*models.License{}
+	query := func(q *orm.Query) *orm.Query {
+		return q.
+			Where("db_name = ? and (ns = ? or ns is null)", dbName, ns).
+			OrderExpr("ns nulls last").
+			Limit(1)
+	}
+
+	if err := repo.Find(ctx, &lics, query); err != nil {
+		return nil, err
+	}
+
+	if len(lics) == 0 {
+		return schema.NewLicense(), nil
+	}
+
+	return lics[0].Schema(), nil
+}
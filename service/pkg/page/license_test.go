@@ -0,0 +1,69 @@
+package page
+
+import (
+	"context"
+	"errors"
+	"okapi-data-service/models"
+	"okapi-data-service/schema/v3"
+	"testing"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type licenseRepoMock struct {
+	mock.Mock
+	lics []*models.License
+}
+
+func (r *licenseRepoMock) Find(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, _ ...interface{}) error {
+	if lics, ok := model.(*[]*models.License); ok {
+		*lics = append(*lics, r.lics...)
+	}
+
+	return r.Called(model).Error(0)
+}
+
+func TestLicense(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	t.Run("license configured", func(t *testing.T) {
+		repo := &licenseRepoMock{
+			lics: []*models.License{
+				{
+					DbName:     "enwikinews",
+					Name:       "Attribution 2.5 Generic",
+					Identifier: "CC-BY-2.5",
+					URL:        "https://creativecommons.org/licenses/by/2.5/",
+				},
+			},
+		}
+		repo.On("Find", mock.Anything).Return(nil)
+
+		license, err := License(ctx, repo, "enwikinews", 0)
+		assert.NoError(err)
+		assert.Equal("CC-BY-2.5", license.Identifier)
+		assert.Equal("Attribution 2.5 Generic", license.Name)
+		assert.Equal("https://creativecommons.org/licenses/by/2.5/", license.URL)
+	})
+
+	t.Run("license default", func(t *testing.T) {
+		repo := new(licenseRepoMock)
+		repo.On("Find", mock.Anything).Return(nil)
+
+		license, err := License(ctx, repo, "enwiki", 0)
+		assert.NoError(err)
+		assert.Equal(schema.NewLicense(), license)
+	})
+
+	t.Run("license error", func(t *testing.T) {
+		errFind := errors.New("can't find license")
+		repo := new(licenseRepoMock)
+		repo.On("Find", mock.Anything).Return(errFind)
+
+		_, err := License(ctx, repo, "enwiki", 0)
+		assert.Equal(errFind, err)
+	})
+}
@@ -0,0 +1,78 @@
+package page
+
+import (
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
+	"github.com/protsack-stephan/dev-toolkit/lib/s3"
+)
+
+// ContentType content type of the page files
+const ContentType = "application/json"
+
+// Uploader remote storage that keeps content type and encoding of the uploaded files,
+// so the objects can be read directly from the bucket
+type Uploader interface {
+	PutObject(path string, body io.Reader, contentType string, contentEncoding string) error
+}
+
+// Walker remote storage that lists every key under the prefix, no matter how many there are
+type Walker interface {
+	WalkAll(prefix string, callback func(key string)) error
+}
+
+// S3 s3 storage that sets content type and encoding of the uploaded page files
+type S3 struct {
+	*s3.Storage
+	client   s3iface.S3API
+	uploader s3manageriface.UploaderAPI
+	bucket   string
+}
+
+// NewS3 create remote storage for the bucket
+func NewS3(ses *session.Session, bucket string) *S3 {
+	return &S3{
+		Storage:  s3.NewStorage(ses, bucket),
+		client:   awss3.New(ses),
+		uploader: s3manager.NewUploader(ses),
+		bucket:   bucket,
+	}
+}
+
+// PutObject upload file with the content type and encoding (empty encoding is not set)
+func (s *S3) PutObject(path string, body io.Reader, contentType string, contentEncoding string) error {
+	input := &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(path),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	}
+
+	if len(contentEncoding) > 0 {
+		input.ContentEncoding = aws.String(contentEncoding)
+	}
+
+	_, err := s.uploader.Upload(input)
+	return err
+}
+
+// WalkAll call back with full key of every object under the prefix, going through all the listing pages
+func (s *S3) WalkAll(prefix string, callback func(key string)) error {
+	input := &awss3.ListObjectsInput{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	}
+
+	return s.client.ListObjectsPages(input, func(page *awss3.ListObjectsOutput, _ bool) bool {
+		for _, obj := range page.Contents {
+			callback(aws.StringValue(obj.Key))
+		}
+
+		return true
+	})
+}
@@ -0,0 +1,120 @@
+package page
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"okapi-data-service/pkg/compress"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
+	"github.com/stretchr/testify/assert"
+)
+
+type s3UploaderMock struct {
+	s3manageriface.UploaderAPI
+	input *s3manager.UploadInput
+}
+
+func (u *s3UploaderMock) Upload(input *s3manager.UploadInput, _ ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	u.input = input
+	return new(s3manager.UploadOutput), nil
+}
+
+func (u *s3UploaderMock) UploadWithContext(_ aws.Context, input *s3manager.UploadInput, _ ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	return u.Upload(input)
+}
+
+// s3ListHandler s3 api that lists bucket objects in pages of two keys, like ListObjects does with max keys
+func s3ListHandler(keys []string, calls *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		prefix := r.URL.Query().Get("prefix")
+		marker := r.URL.Query().Get("marker")
+		matched := []string{}
+
+		for _, key := range keys {
+			if strings.HasPrefix(key, prefix) && key > marker {
+				matched = append(matched, key)
+			}
+		}
+
+		sort.Strings(matched)
+		truncated := len(matched) > 2
+
+		if truncated {
+			matched = matched[:2]
+		}
+
+		contents := ""
+
+		for _, key := range matched {
+			contents += fmt.Sprintf("<Contents><Key>%s</Key></Contents>", key)
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = fmt.Fprintf(w, `<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Name>wme-data</Name><Prefix>%s</Prefix><Marker>%s</Marker><IsTruncated>%t</IsTruncated>%s</ListBucketResult>`, prefix, marker, truncated, contents)
+	}
+}
+
+func TestS3(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("put gzip encoded", func(t *testing.T) {
+		up := new(s3UploaderMock)
+		store := &S3{uploader: up, bucket: "wme-data"}
+
+		assert.NoError(store.PutObject(storageTestRemotePath, strings.NewReader(storageTestBody), ContentType, compress.Gzip))
+		assert.Equal("wme-data", aws.StringValue(up.input.Bucket))
+		assert.Equal(storageTestRemotePath, aws.StringValue(up.input.Key))
+		assert.Equal(ContentType, aws.StringValue(up.input.ContentType))
+		assert.Equal(compress.Gzip, aws.StringValue(up.input.ContentEncoding))
+	})
+
+	t.Run("put without encoding", func(t *testing.T) {
+		up := new(s3UploaderMock)
+		store := &S3{uploader: up, bucket: "wme-data"}
+
+		assert.NoError(store.PutObject(storageTestRemotePath, strings.NewReader(storageTestBody), ContentType, ""))
+		assert.Equal(ContentType, aws.StringValue(up.input.ContentType))
+		assert.Nil(up.input.ContentEncoding)
+	})
+
+	t.Run("walk all pages", func(t *testing.T) {
+		keys := []string{
+			"page/json/enwiki/Earth.json",
+			"page/json/enwiki/Moon.json",
+			"page/json/enwiki/Mars.json",
+			"page/json/enwiki/Category:Planets/Solar.json",
+			"page/json/enwiki/Venus.json",
+			"page/json/dewiki/Erde.json",
+		}
+		calls := 0
+		srv := httptest.NewServer(s3ListHandler(keys, &calls))
+		defer srv.Close()
+
+		ses, err := session.NewSession(&aws.Config{
+			Endpoint:         aws.String(srv.URL),
+			Region:           aws.String("us-east-1"),
+			S3ForcePathStyle: aws.Bool(true),
+			Credentials:      credentials.NewStaticCredentials("id", "secret", ""),
+		})
+		assert.NoError(err)
+
+		store := &S3{client: awss3.New(ses), bucket: "wme-data"}
+		walked := []string{}
+
+		assert.NoError(store.WalkAll("page/json/enwiki/", func(key string) {
+			walked = append(walked, key)
+		}))
+		assert.ElementsMatch(keys[:5], walked)
+		assert.Equal(3, calls)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"okapi-data-service/pkg/compress"
 
 	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
 )
@@ -16,13 +17,20 @@ type store interface {
 }
 
 type Storage struct {
-	Local  store
-	Remote store
+	Local    store
+	Remote   store
+	Encoding string // compression of the stored files, empty to store them as is
 }
 
-// Get get data from the storage
+// Get get data from the storage, compressed files are decoded
 func (s Storage) Get(path string) (io.ReadCloser, error) {
-	return s.Local.Get(path)
+	rc, err := s.Local.Get(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return compress.NewReader(rc)
 }
 
 // Put upload file to remote and local storages
@@ -34,11 +42,23 @@ func (s Storage) Put(path string, body io.Reader) error {
 		return err
 	}
 
+	data, err = compress.Encode(s.Encoding, data)
+
+	if err != nil {
+		return err
+	}
+
 	go func() {
 		errs <- s.Local.Put(path, bytes.NewReader(data))
 	}()
 
 	go func() {
+		// readers sniff the compression anyway, metadata is for the direct consumers of the bucket
+		if up, ok := s.Remote.(Uploader); ok {
+			errs <- up.PutObject(fmt.Sprintf("page/%s", path), bytes.NewReader(data), ContentType, s.Encoding)
+			return
+		}
+
 		errs <- s.Remote.Put(fmt.Sprintf("page/%s", path), bytes.NewReader(data))
 	}()
 
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"io"
 	"io/ioutil"
+	"okapi-data-service/pkg/compress"
 	"strings"
 	"testing"
 
@@ -39,6 +40,20 @@ func (s *storageMock) Get(path string) (io.ReadCloser, error) {
 	return ioutil.NopCloser(strings.NewReader(args.String(0))), args.Error(1)
 }
 
+type uploaderMock struct {
+	storageMock
+}
+
+func (s *uploaderMock) PutObject(path string, body io.Reader, contentType string, contentEncoding string) error {
+	data, err := ioutil.ReadAll(body)
+
+	if err != nil {
+		return err
+	}
+
+	return s.Called(path, string(data), contentType, contentEncoding).Error(0)
+}
+
 func TestStorage(t *testing.T) {
 	assert := assert.New(t)
 
@@ -49,7 +64,7 @@ func TestStorage(t *testing.T) {
 		local := new(storageMock)
 		local.On("Delete", storageTestPath).Return(nil)
 
-		store := &Storage{local, remote}
+		store := &Storage{Local: local, Remote: remote}
 		assert.NoError(store.Delete(storageTestPath))
 	})
 
@@ -61,7 +76,7 @@ func TestStorage(t *testing.T) {
 		local := new(storageMock)
 		local.On("Delete", storageTestPath).Return(nil)
 
-		store := &Storage{local, remote}
+		store := &Storage{Local: local, Remote: remote}
 		assert.Equal(err, store.Delete(storageTestPath))
 	})
 
@@ -73,7 +88,7 @@ func TestStorage(t *testing.T) {
 		local := new(storageMock)
 		local.On("Delete", storageTestPath).Return(err)
 
-		store := &Storage{local, remote}
+		store := &Storage{Local: local, Remote: remote}
 		assert.Equal(err, store.Delete(storageTestPath))
 	})
 
@@ -84,7 +99,7 @@ func TestStorage(t *testing.T) {
 		local := new(storageMock)
 		local.On("Put", storageTestPath, storageTestBody).Return(nil)
 
-		store := &Storage{local, remote}
+		store := &Storage{Local: local, Remote: remote}
 		assert.NoError(store.Put(storageTestPath, strings.NewReader(storageTestBody)))
 	})
 
@@ -96,7 +111,7 @@ func TestStorage(t *testing.T) {
 		local := new(storageMock)
 		local.On("Put", storageTestPath, storageTestBody).Return(nil)
 
-		store := &Storage{local, remote}
+		store := &Storage{Local: local, Remote: remote}
 		assert.Equal(err, store.Put(storageTestPath, strings.NewReader(storageTestBody)))
 	})
 
@@ -108,7 +123,7 @@ func TestStorage(t *testing.T) {
 		local := new(storageMock)
 		local.On("Put", storageTestPath, storageTestBody).Return(err)
 
-		store := &Storage{local, remote}
+		store := &Storage{Local: local, Remote: remote}
 		assert.Equal(err, store.Put(storageTestPath, strings.NewReader(storageTestBody)))
 	})
 
@@ -116,7 +131,7 @@ func TestStorage(t *testing.T) {
 		local := new(storageMock)
 		local.On("Get", storageTestPath).Return(storageTestBody, nil)
 
-		store := &Storage{local, new(storageMock)}
+		store := &Storage{Local: local, Remote: new(storageMock)}
 		rc, err := store.Get(storageTestPath)
 		assert.NoError(err)
 		data, err := ioutil.ReadAll(rc)
@@ -129,8 +144,44 @@ func TestStorage(t *testing.T) {
 		local := new(storageMock)
 		local.On("Get", storageTestPath).Return("", gErr)
 
-		store := &Storage{local, new(storageMock)}
+		store := &Storage{Local: local, Remote: new(storageMock)}
 		_, err := store.Get(storageTestPath)
 		assert.Equal(gErr, err)
 	})
+
+	t.Run("put and get compressed", func(t *testing.T) {
+		data, err := compress.Encode(compress.Gzip, []byte(storageTestBody))
+		assert.NoError(err)
+
+		remote := new(storageMock)
+		remote.On("Put", storageTestRemotePath, string(data)).Return(nil)
+
+		local := new(storageMock)
+		local.On("Put", storageTestPath, string(data)).Return(nil)
+		local.On("Get", storageTestPath).Return(string(data), nil)
+
+		store := &Storage{Local: local, Remote: remote, Encoding: compress.Gzip}
+		assert.NoError(store.Put(storageTestPath, strings.NewReader(storageTestBody)))
+
+		rc, err := store.Get(storageTestPath)
+		assert.NoError(err)
+		body, err := ioutil.ReadAll(rc)
+		assert.NoError(err)
+		assert.Equal(storageTestBody, string(body))
+	})
+
+	t.Run("put with content metadata", func(t *testing.T) {
+		data, err := compress.Encode(compress.Gzip, []byte(storageTestBody))
+		assert.NoError(err)
+
+		remote := new(uploaderMock)
+		remote.On("PutObject", storageTestRemotePath, string(data), ContentType, compress.Gzip).Return(nil)
+
+		local := new(storageMock)
+		local.On("Put", storageTestPath, string(data)).Return(nil)
+
+		store := &Storage{Local: local, Remote: remote, Encoding: compress.Gzip}
+		assert.NoError(store.Put(storageTestPath, strings.NewReader(storageTestBody)))
+		remote.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+	})
 }
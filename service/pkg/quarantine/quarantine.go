@@ -0,0 +1,176 @@
+// Package quarantine holds risky revisions away from publishing until the project policy delay expires.
+package quarantine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"okapi-data-service/schema/v3"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	ores "github.com/protsack-stephan/mediawiki-ores-client"
+)
+
+// Name redis key for the sorted set of quarantined revisions by release time
+const Name = "quarantine"
+
+// ErrChanged held payload was replaced or released after it was read
+var ErrChanged = errors.New("held payload changed since it was read")
+
+// holdScript replace held payload and its release time if the payload is still the one that was read
+var holdScript = redis.NewScript(`
+if (redis.call("get", KEYS[2]) or "") ~= ARGV[1] then
+	return 0
+end
+
+redis.call("set", KEYS[2], ARGV[2])
+redis.call("zadd", KEYS[1], ARGV[3], KEYS[2])
+return 1
+`)
+
+// dropScript remove held payload if it is still the one that was read
+var dropScript = redis.NewScript(`
+if (redis.call("get", KEYS[2]) or "") ~= ARGV[1] then
+	return 0
+end
+
+redis.call("zrem", KEYS[1], KEYS[2])
+redis.call("del", KEYS[2])
+return 1
+`)
+
+// takeScript take the key out of the release schedule and remove its payload, returns nothing if the key was already taken
+var takeScript = redis.NewScript(`
+if redis.call("zrem", KEYS[1], KEYS[2]) == 0 then
+	return false
+end
+
+local data = redis.call("get", KEYS[2])
+redis.call("del", KEYS[2])
+return data
+`)
+
+// Reasons for the revision to be held
+const (
+	ReasonDamaging  = "damaging"
+	ReasonAnonymous = "anonymous"
+	ReasonUnscored  = "unscored"
+)
+
+// Policy per-project publishing policy
+type Policy struct {
+	Damaging  float64 `json:"damaging,omitempty"`  // damaging probability threshold, zero disables the check
+	Anonymous bool    `json:"anonymous,omitempty"` // hold revisions from anonymous editors
+	Delay     int     `json:"delay"`               // delay before publishing in seconds
+}
+
+// Check get reasons to hold the revision, unscored revisions are held until the scores arrive
+// if the policy has damaging threshold and the project has damaging model (otherwise scores never arrive)
+func (p *Policy) Check(dbName string, scores *schema.Scores, editor *schema.Editor) []string {
+	reasons := []string{}
+
+	if p.Damaging > 0 {
+		if scores == nil || scores.Damaging == nil {
+			if ores.ModelDamaging.Supports(dbName) {
+				reasons = append(reasons, ReasonUnscored)
+			}
+		} else if scores.Damaging.Probability.True >= p.Damaging {
+			reasons = append(reasons, ReasonDamaging)
+		}
+	}
+
+	if p.Anonymous && editor != nil && editor.IsAnonymous {
+		reasons = append(reasons, ReasonAnonymous)
+	}
+
+	return reasons
+}
+
+// Release get release time of the revision held at the time
+func (p *Policy) Release(held time.Time) time.Time {
+	return held.Add(time.Second * time.Duration(p.Delay))
+}
+
+// Key redis key for the quarantined revision of the page
+func Key(dbName string, title string) string {
+	return fmt.Sprintf("quarantine/%s/%s", dbName, title)
+}
+
+// Hold put payload into quarantine until the release time, replacing the held one that was read by Get,
+// returns ErrChanged if the held payload changed since then
+func Hold(ctx context.Context, store redis.Cmdable, key string, held string, payload interface{}, release time.Time) error {
+	data, err := json.Marshal(payload)
+
+	if err != nil {
+		return err
+	}
+
+	n, err := holdScript.Run(ctx, store, []string{Name, key}, held, data, release.Unix()).Int()
+
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrChanged
+	}
+
+	return nil
+}
+
+// Get held payload, returns raw held data to pass to Hold and Drop, empty if nothing is held under the key
+func Get(ctx context.Context, store redis.Cmdable, key string, payload interface{}) (string, error) {
+	data, err := store.Get(ctx, key).Result()
+
+	if err == redis.Nil {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return data, json.Unmarshal([]byte(data), payload)
+}
+
+// Drop remove held payload that was read by Get from quarantine, returns ErrChanged if it changed since then
+func Drop(ctx context.Context, store redis.Cmdable, key string, held string) error {
+	n, err := dropScript.Run(ctx, store, []string{Name, key}, held).Int()
+
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrChanged
+	}
+
+	return nil
+}
+
+// Due get keys of the payloads that have to be released by the time
+func Due(ctx context.Context, store redis.Cmdable, now time.Time) ([]string, error) {
+	return store.ZRangeByScore(ctx, Name, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+}
+
+// Take claim the key and remove its payload from quarantine at once,
+// returns false if somebody else already took it or nothing is held under the key
+func Take(ctx context.Context, store redis.Cmdable, key string, payload interface{}) (bool, error) {
+	data, err := takeScript.Run(ctx, store, []string{Name, key}).Text()
+
+	if err == redis.Nil {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal([]byte(data), payload)
+}
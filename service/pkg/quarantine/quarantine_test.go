@@ -0,0 +1,171 @@
+package quarantine
+
+import (
+	"context"
+	"okapi-data-service/schema/v3"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	ores "github.com/protsack-stephan/mediawiki-ores-client"
+	"github.com/stretchr/testify/assert"
+)
+
+const quarantineTestDbName = "enwiki"
+const quarantineTestTitle = "Earth"
+
+type quarantineRedisMock struct {
+	redis.Cmdable
+	data map[string]string
+	zset map[string]float64
+}
+
+func (s *quarantineRedisMock) Get(_ context.Context, key string) *redis.StringCmd {
+	if val, ok := s.data[key]; ok {
+		return redis.NewStringResult(val, nil)
+	}
+
+	return redis.NewStringResult("", redis.Nil)
+}
+
+func (s *quarantineRedisMock) ZRangeByScore(_ context.Context, _ string, opt *redis.ZRangeBy) *redis.StringSliceCmd {
+	max, _ := strconv.ParseFloat(opt.Max, 64)
+	keys := []string{}
+
+	for key, score := range s.zset {
+		if score <= max {
+			keys = append(keys, key)
+		}
+	}
+
+	return redis.NewStringSliceResult(keys, nil)
+}
+
+// EvalSha run quarantine script on the mock, scripts are told apart by the number of arguments
+func (s *quarantineRedisMock) EvalSha(_ context.Context, _ string, keys []string, args ...interface{}) *redis.Cmd {
+	key := keys[1]
+
+	if len(args) == 0 {
+		if _, ok := s.zset[key]; !ok {
+			return redis.NewCmdResult(nil, redis.Nil)
+		}
+
+		delete(s.zset, key)
+		data, ok := s.data[key]
+		delete(s.data, key)
+
+		if !ok {
+			return redis.NewCmdResult(nil, redis.Nil)
+		}
+
+		return redis.NewCmdResult(data, nil)
+	}
+
+	if s.data[key] != args[0].(string) {
+		return redis.NewCmdResult(int64(0), nil)
+	}
+
+	if len(args) == 1 {
+		delete(s.zset, key)
+		delete(s.data, key)
+	} else {
+		s.data[key] = string(args[1].([]byte))
+		s.zset[key] = float64(args[2].(int64))
+	}
+
+	return redis.NewCmdResult(int64(1), nil)
+}
+
+func newScores(damaging float64) *schema.Scores {
+	scores := &schema.Scores{
+		Damaging: new(ores.ScoreDamaging),
+	}
+	scores.Damaging.Probability.True = damaging
+	scores.Damaging.Probability.False = 1 - damaging
+
+	return scores
+}
+
+func TestPolicy(t *testing.T) {
+	assert := assert.New(t)
+	policy := &Policy{
+		Damaging:  0.8,
+		Anonymous: true,
+		Delay:     60,
+	}
+
+	assert.Equal([]string{ReasonUnscored}, policy.Check("enwiki", nil, nil))
+	assert.Empty(policy.Check("enwiktionary", nil, nil))
+	assert.Equal([]string{ReasonDamaging}, policy.Check("enwiki", newScores(0.9), nil))
+	assert.Empty(policy.Check("enwiki", newScores(0.1), &schema.Editor{Identifier: 1}))
+	assert.Equal([]string{ReasonAnonymous}, policy.Check("enwiki", newScores(0.1), &schema.Editor{IsAnonymous: true}))
+	assert.Equal([]string{ReasonAnonymous}, policy.Check("enwiktionary", nil, &schema.Editor{IsAnonymous: true}))
+	assert.Empty((&Policy{Anonymous: true}).Check("enwiki", nil, nil))
+
+	dt := time.Now()
+	assert.Equal(dt.Add(time.Minute), policy.Release(dt))
+}
+
+func TestQuarantine(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	store := &quarantineRedisMock{data: map[string]string{}, zset: map[string]float64{}}
+	key := Key(quarantineTestDbName, quarantineTestTitle)
+	now := time.Now().UTC()
+
+	assert.Equal("quarantine/enwiki/Earth", key)
+
+	t.Run("hold and get", func(t *testing.T) {
+		assert.NoError(Hold(ctx, store, key, "", map[string]int{"revision": 1}, now.Add(time.Minute)))
+
+		payload := map[string]int{}
+		held, err := Get(ctx, store, key, &payload)
+		assert.NoError(err)
+		assert.NotEmpty(held)
+		assert.Equal(1, payload["revision"])
+
+		assert.NoError(Hold(ctx, store, key, held, map[string]int{"revision": 2}, now.Add(time.Minute)))
+		assert.Equal(ErrChanged, Hold(ctx, store, key, held, map[string]int{"revision": 3}, now.Add(time.Minute)))
+		assert.Equal(ErrChanged, Hold(ctx, store, key, "", map[string]int{"revision": 3}, now.Add(time.Minute)))
+
+		_, err = Get(ctx, store, key, &payload)
+		assert.NoError(err)
+		assert.Equal(2, payload["revision"])
+	})
+
+	t.Run("due and take", func(t *testing.T) {
+		keys, err := Due(ctx, store, now)
+		assert.NoError(err)
+		assert.Empty(keys)
+
+		keys, err = Due(ctx, store, now.Add(time.Minute))
+		assert.NoError(err)
+		assert.Equal([]string{key}, keys)
+
+		payload := map[string]int{}
+		taken, err := Take(ctx, store, key, &payload)
+		assert.NoError(err)
+		assert.True(taken)
+		assert.Equal(2, payload["revision"])
+		assert.NotContains(store.data, key)
+
+		taken, err = Take(ctx, store, key, &payload)
+		assert.NoError(err)
+		assert.False(taken)
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		assert.NoError(Hold(ctx, store, key, "", map[string]int{"revision": 1}, now.Add(time.Minute)))
+
+		held, err := Get(ctx, store, key, &map[string]int{})
+		assert.NoError(err)
+		assert.Equal(ErrChanged, Drop(ctx, store, key, ""))
+		assert.NoError(Drop(ctx, store, key, held))
+		assert.NotContains(store.zset, key)
+
+		held, err = Get(ctx, store, key, &map[string]int{})
+		assert.NoError(err)
+		assert.Empty(held)
+	})
+}
@@ -0,0 +1,699 @@
+// Package segment log-structured storage for page json files.
+// Pages of every project are appended to numbered segment files (<vol>/segment/<db_name>/<seq>.seg)
+// and looked up through in-memory index keyed by title, titles of every namespace are sorted on the first walk after a change.
+// The index takes about 200 bytes of memory per page, so the number of pages in the project is limited by MaxPages.
+// Several processes can share one volume: every operation takes a file lock, writes catch up with
+// the records appended by others before touching the index and reads do it at most once per SyncInterval.
+package segment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"okapi-data-service/pkg/compress"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Name value of the JSON_STORE env variable that enables segment storage
+const Name = "segment"
+
+// Loc directory prefix of the page paths (json/<db_name>/<title>.json)
+const Loc = "json"
+
+// MaxSegmentSize size of the segment file after which new one is started
+const MaxSegmentSize = 1 << 30
+
+// DefaultMaxPages number of pages the project can have (about 4GB of index memory)
+const DefaultMaxPages = 20000000
+
+// DefaultSyncInterval how long reads can use the index without looking for records appended by other processes
+const DefaultSyncInterval = time.Second
+
+const dir = "segment"
+const ext = ".seg"
+const lock = "LOCK"
+const nsSep = "#"
+
+// header layout: op (1), namespace (4), title length (4), data length (4), crc32 (4)
+const headerSize = 17
+
+const (
+	opPut byte = iota + 1
+	opDelete
+)
+
+// ErrInvalidPath path can't be mapped to the project and title
+var ErrInvalidPath = errors.New("path should look like json/<db_name>/<title>.json")
+
+// ErrNotFound page is not in the storage
+var ErrNotFound = fmt.Errorf("page %w", os.ErrNotExist)
+
+// ErrNoNamespace page body has no namespace identifier
+var ErrNoNamespace = errors.New("page has no namespace identifier")
+
+// ErrTooManyPages project reached MaxPages, new pages can't be added
+var ErrTooManyPages = errors.New("project has too many pages for segment storage")
+
+// NsLoc walk path to range scan single namespace of the project
+func NsLoc(dbName string, ns int) string {
+	return fmt.Sprintf("%s/%s%s%d", Loc, dbName, nsSep, ns)
+}
+
+type entry struct {
+	ns   int
+	seq  int
+	off  int64
+	size int
+	rec  int64 // size of the whole record
+}
+
+// namespace titles of the namespace, sorted list is built by walk and dropped when a title is added or removed
+type namespace struct {
+	titles map[string]bool
+	sorted []string
+}
+
+// list titles of the namespace in order
+func (n *namespace) list() []string {
+	if n.sorted == nil {
+		n.sorted = make([]string, 0, len(n.titles))
+
+		for title := range n.titles {
+			n.sorted = append(n.sorted, title)
+		}
+
+		sort.Strings(n.sorted)
+	}
+
+	return n.sorted
+}
+
+type project struct {
+	mu         sync.Mutex
+	dir        string
+	lock       *os.File
+	files      map[int]*os.File
+	synced     map[int]int64 // number of bytes replayed for every segment
+	syncAt     time.Time     // time of the last sync, zero when index has to be rebuilt
+	index      map[string]*entry
+	namespaces map[int]*namespace
+	live       int64
+	garbage    int64
+}
+
+// list titles of the namespace (or all of them if ns is -1) ordered by namespace and title
+func (p *project) list(ns int) [][]string {
+	ids := []int{}
+
+	for id := range p.namespaces {
+		if ns == -1 || id == ns {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Ints(ids)
+	lists := make([][]string, 0, len(ids))
+
+	for _, id := range ids {
+		lists = append(lists, p.namespaces[id].list())
+	}
+
+	return lists
+}
+
+// Storage log-structured page storage, implements storage Getter, Putter, Deleter and Walker
+type Storage struct {
+	MaxSize      int64
+	MaxPages     int
+	SyncInterval time.Duration
+	vol          string
+	mu           sync.Mutex
+	projects     map[string]*project
+}
+
+// NewStorage create new segment storage in the volume
+func NewStorage(vol string) *Storage {
+	return &Storage{
+		MaxSize:      MaxSegmentSize,
+		MaxPages:     DefaultMaxPages,
+		SyncInterval: DefaultSyncInterval,
+		vol:          vol,
+		projects:     map[string]*project{},
+	}
+}
+
+// Get read page data
+func (s *Storage) Get(path string) (io.ReadCloser, error) {
+	dbName, title, err := parse(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	err = s.exec(dbName, syscall.LOCK_SH, false, func(proj *project) error {
+		ent, ok := proj.index[title]
+
+		if !ok {
+			return ErrNotFound
+		}
+
+		data = make([]byte, ent.size)
+		_, err := proj.files[ent.seq].ReadAt(data, ent.off)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Put append page data, namespace is taken from the page body
+func (s *Storage) Put(path string, body io.Reader) error {
+	dbName, title, err := parse(path)
+
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadAll(body)
+
+	if err != nil {
+		return err
+	}
+
+	page := new(struct {
+		Namespace *struct {
+			Identifier int `json:"identifier"`
+		} `json:"namespace"`
+	})
+
+	// data is stored as it came, compressed or not
+	decoded, err := compress.Decode(data)
+
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(decoded, page); err != nil {
+		return err
+	}
+
+	if page.Namespace == nil {
+		return ErrNoNamespace
+	}
+
+	return s.exec(dbName, syscall.LOCK_EX, true, func(proj *project) error {
+		if _, ok := proj.index[title]; !ok && s.MaxPages > 0 && len(proj.index) >= s.MaxPages {
+			return ErrTooManyPages
+		}
+
+		if err := proj.append(s.MaxSize, opPut, page.Namespace.Identifier, title, data); err != nil {
+			return err
+		}
+
+		return proj.compact(s.MaxSize, false)
+	})
+}
+
+// Delete append tombstone for the page
+func (s *Storage) Delete(path string) error {
+	dbName, title, err := parse(path)
+
+	if err != nil {
+		return err
+	}
+
+	return s.exec(dbName, syscall.LOCK_EX, true, func(proj *project) error {
+		ent, ok := proj.index[title]
+
+		if !ok {
+			return ErrNotFound
+		}
+
+		if err := proj.append(s.MaxSize, opDelete, ent.ns, title, nil); err != nil {
+			return err
+		}
+
+		return proj.compact(s.MaxSize, false)
+	})
+}
+
+// Walk call the callback with paths of the project pages ordered by namespace and title,
+// use NsLoc path to walk through single namespace
+func (s *Storage) Walk(path string, callback func(path string)) error {
+	loc := strings.TrimSuffix(strings.TrimPrefix(path, Loc+"/"), "/")
+	dbName, ns := loc, -1
+
+	if i := strings.LastIndex(loc, nsSep); i != -1 {
+		id, err := strconv.Atoi(loc[i+len(nsSep):])
+
+		if err != nil {
+			return ErrInvalidPath
+		}
+
+		dbName, ns = loc[:i], id
+	}
+
+	if len(dbName) == 0 || strings.Contains(dbName, "/") {
+		return ErrInvalidPath
+	}
+
+	// sorted lists are replaced, not changed, so they can be read after the lock is released
+	var lists [][]string
+	err := s.exec(dbName, syscall.LOCK_SH, true, func(proj *project) error {
+		lists = proj.list(ns)
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, titles := range lists {
+		for _, title := range titles {
+			callback(fmt.Sprintf("%s/%s/%s.json", Loc, dbName, title))
+		}
+	}
+
+	return nil
+}
+
+// Compact rewrite live pages of the project into new segments ordered by namespace and title
+func (s *Storage) Compact(dbName string) error {
+	return s.exec(dbName, syscall.LOCK_EX, true, func(proj *project) error {
+		return proj.compact(s.MaxSize, true)
+	})
+}
+
+// Sync catch up with the records appended to the project by other processes
+func (s *Storage) Sync(dbName string) error {
+	return s.exec(dbName, syscall.LOCK_SH, true, func(*project) error {
+		return nil
+	})
+}
+
+// Close release all the open files
+func (s *Storage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for dbName, proj := range s.projects {
+		proj.mu.Lock()
+		proj.reset()
+		_ = proj.lock.Close()
+		proj.mu.Unlock()
+		delete(s.projects, dbName)
+	}
+
+	return nil
+}
+
+// exec run the function holding project locks, the index is brought up to date when fresh is set
+// or it wasn't synced for SyncInterval (walks and writes need all the records, single reads can lag behind)
+func (s *Storage) exec(dbName string, how int, fresh bool, fn func(proj *project) error) error {
+	proj, err := s.project(dbName)
+
+	if err != nil {
+		return err
+	}
+
+	proj.mu.Lock()
+	defer proj.mu.Unlock()
+
+	if err := syscall.Flock(int(proj.lock.Fd()), how); err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = syscall.Flock(int(proj.lock.Fd()), syscall.LOCK_UN)
+	}()
+
+	if fresh || proj.syncAt.IsZero() || time.Since(proj.syncAt) >= s.SyncInterval {
+		if err := proj.sync(how == syscall.LOCK_EX); err != nil {
+			return err
+		}
+	}
+
+	return fn(proj)
+}
+
+func (s *Storage) project(dbName string) (*project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if proj, ok := s.projects[dbName]; ok {
+		return proj, nil
+	}
+
+	loc := filepath.Join(s.vol, dir, dbName)
+
+	if err := os.MkdirAll(loc, 0766); err != nil {
+		return nil, err
+	}
+
+	lf, err := os.OpenFile(filepath.Join(loc, lock), os.O_CREATE|os.O_RDWR, 0666)
+
+	if err != nil {
+		return nil, err
+	}
+
+	proj := &project{dir: loc, lock: lf}
+	proj.reset()
+	s.projects[dbName] = proj
+
+	return proj, nil
+}
+
+// reset drop the index so it will be rebuilt from the segments
+func (p *project) reset() {
+	for _, file := range p.files {
+		_ = file.Close()
+	}
+
+	p.files = map[int]*os.File{}
+	p.synced = map[int]int64{}
+	p.syncAt = time.Time{}
+	p.index = map[string]*entry{}
+	p.namespaces = map[int]*namespace{}
+	p.live, p.garbage = 0, 0
+}
+
+func (p *project) segments() ([]int, error) {
+	names, err := filepath.Glob(filepath.Join(p.dir, "*"+ext))
+
+	if err != nil {
+		return nil, err
+	}
+
+	seqs := []int{}
+
+	for _, name := range names {
+		seq, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(name), ext))
+
+		if err == nil {
+			seqs = append(seqs, seq)
+		}
+	}
+
+	sort.Ints(seqs)
+	return seqs, nil
+}
+
+func (p *project) open(seq int) (*os.File, error) {
+	if file, ok := p.files[seq]; ok {
+		return file, nil
+	}
+
+	file, err := os.OpenFile(filepath.Join(p.dir, fmt.Sprintf("%09d%s", seq, ext)), os.O_CREATE|os.O_RDWR, 0666)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.files[seq] = file
+	return file, nil
+}
+
+// sync replay records appended since the last call, segments removed by compaction rebuild the whole index,
+// torn records at the end of the segment (left by a crash) are cut off when holding exclusive lock
+func (p *project) sync(exclusive bool) error {
+	seqs, err := p.segments()
+
+	if err != nil {
+		return err
+	}
+
+	exists := map[int]bool{}
+
+	for _, seq := range seqs {
+		exists[seq] = true
+	}
+
+	for seq := range p.synced {
+		if !exists[seq] {
+			p.reset()
+			break
+		}
+	}
+
+	for _, seq := range seqs {
+		file, err := p.open(seq)
+
+		if err != nil {
+			return err
+		}
+
+		info, err := file.Stat()
+
+		if err != nil {
+			return err
+		}
+
+		off := p.synced[seq]
+
+		for off < info.Size() {
+			size, err := p.replay(file, seq, off, info.Size())
+
+			if err != nil {
+				if !exclusive {
+					break
+				}
+
+				if err := file.Truncate(off); err != nil {
+					return err
+				}
+
+				break
+			}
+
+			off += size
+		}
+
+		p.synced[seq] = off
+	}
+
+	p.syncAt = time.Now()
+	return nil
+}
+
+var errTornRecord = errors.New("torn record")
+
+func (p *project) replay(file *os.File, seq int, off int64, end int64) (int64, error) {
+	if end-off < headerSize {
+		return 0, errTornRecord
+	}
+
+	header := make([]byte, headerSize)
+
+	if _, err := file.ReadAt(header, off); err != nil {
+		return 0, err
+	}
+
+	tlen, dlen := int64(binary.LittleEndian.Uint32(header[5:])), int64(binary.LittleEndian.Uint32(header[9:]))
+
+	if end-off < headerSize+tlen+dlen {
+		return 0, errTornRecord
+	}
+
+	body := make([]byte, tlen+dlen)
+
+	if _, err := file.ReadAt(body, off+headerSize); err != nil {
+		return 0, err
+	}
+
+	crc := crc32.NewIEEE()
+	_, _ = crc.Write(header[:13])
+	_, _ = crc.Write(body)
+
+	if crc.Sum32() != binary.LittleEndian.Uint32(header[13:]) {
+		return 0, errTornRecord
+	}
+
+	title := string(body[:tlen])
+	size := headerSize + tlen + dlen
+	p.apply(title, header[0], &entry{
+		ns:   int(int32(binary.LittleEndian.Uint32(header[1:]))),
+		seq:  seq,
+		off:  off + headerSize + tlen,
+		size: int(dlen),
+		rec:  size,
+	})
+
+	return size, nil
+}
+
+func (p *project) apply(title string, op byte, ent *entry) {
+	old, exists := p.index[title]
+
+	if exists {
+		p.live -= old.rec
+		p.garbage += old.rec
+	}
+
+	if exists && (op == opDelete || old.ns != ent.ns) {
+		if n, ok := p.namespaces[old.ns]; ok {
+			delete(n.titles, title)
+			n.sorted = nil
+		}
+	}
+
+	switch op {
+	case opPut:
+		p.index[title] = ent
+		p.live += ent.rec
+
+		if !exists || old.ns != ent.ns {
+			n, ok := p.namespaces[ent.ns]
+
+			if !ok {
+				n = &namespace{titles: map[string]bool{}}
+				p.namespaces[ent.ns] = n
+			}
+
+			n.titles[title] = true
+			n.sorted = nil
+		}
+	case opDelete:
+		delete(p.index, title)
+		p.garbage += ent.rec
+	}
+}
+
+// append write the record to the last segment or start new one when it's full
+func (p *project) append(max int64, op byte, ns int, title string, data []byte) error {
+	seqs, err := p.segments()
+
+	if err != nil {
+		return err
+	}
+
+	seq := 0
+
+	if len(seqs) > 0 {
+		seq = seqs[len(seqs)-1]
+
+		if p.synced[seq] >= max {
+			seq++
+		}
+	}
+
+	return p.write(seq, op, ns, title, data)
+}
+
+// write add the record to the end of the segment and update the index
+func (p *project) write(seq int, op byte, ns int, title string, data []byte) error {
+	file, err := p.open(seq)
+
+	if err != nil {
+		return err
+	}
+
+	record := make([]byte, headerSize+len(title)+len(data))
+	record[0] = op
+	binary.LittleEndian.PutUint32(record[1:], uint32(int32(ns)))
+	binary.LittleEndian.PutUint32(record[5:], uint32(len(title)))
+	binary.LittleEndian.PutUint32(record[9:], uint32(len(data)))
+	copy(record[headerSize:], title)
+	copy(record[headerSize+len(title):], data)
+
+	crc := crc32.NewIEEE()
+	_, _ = crc.Write(record[:13])
+	_, _ = crc.Write(record[headerSize:])
+	binary.LittleEndian.PutUint32(record[13:], crc.Sum32())
+
+	off := p.synced[seq]
+
+	if _, err := file.WriteAt(record, off); err != nil {
+		return err
+	}
+
+	p.synced[seq] = off + int64(len(record))
+	p.apply(title, op, &entry{ns, seq, off + headerSize + int64(len(title)), len(data), int64(len(record))})
+
+	return nil
+}
+
+// compact rewrite live records into new segments, unless forced runs only when most of the segments is garbage
+func (p *project) compact(max int64, force bool) error {
+	if !force && (p.garbage < max || p.garbage < p.live) {
+		return nil
+	}
+
+	old, err := p.segments()
+
+	if err != nil {
+		return err
+	}
+
+	if len(old) == 0 {
+		return nil
+	}
+
+	seq := old[len(old)-1] + 1
+
+	for _, titles := range p.list(-1) {
+		for _, title := range titles {
+			ent := p.index[title]
+			data := make([]byte, ent.size)
+
+			if _, err := p.files[ent.seq].ReadAt(data, ent.off); err != nil {
+				return err
+			}
+
+			if p.synced[seq] >= max {
+				seq++
+			}
+
+			if err := p.write(seq, opPut, ent.ns, title, data); err != nil {
+				return err
+			}
+		}
+	}
+
+	// ascending order keeps tombstones after the records they delete if removal is interrupted
+	for _, seq := range old {
+		if file, ok := p.files[seq]; ok {
+			_ = file.Close()
+			delete(p.files, seq)
+		}
+
+		delete(p.synced, seq)
+
+		if err := os.Remove(filepath.Join(p.dir, fmt.Sprintf("%09d%s", seq, ext))); err != nil {
+			return err
+		}
+	}
+
+	p.garbage = 0
+	return nil
+}
+
+// parse get project and title out of the page path
+func parse(path string) (string, string, error) {
+	if !strings.HasPrefix(path, Loc+"/") || !strings.HasSuffix(path, ".json") {
+		return "", "", ErrInvalidPath
+	}
+
+	parts := strings.SplitN(strings.TrimSuffix(strings.TrimPrefix(path, Loc+"/"), ".json"), "/", 2)
+
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", "", ErrInvalidPath
+	}
+
+	return parts[0], parts[1], nil
+}
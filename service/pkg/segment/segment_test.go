@@ -0,0 +1,205 @@
+package segment
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"okapi-data-service/pkg/compress"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const segmentTestDbName = "afwikibooks"
+
+var segmentTestPages = map[string]int{
+	"Earth":         0,
+	"Moon":          0,
+	"Category:Rock": 14,
+	"Talk:Moon/Sub": 1,
+}
+
+func segmentTestBody(title string, ns int, rev int) string {
+	return fmt.Sprintf(`{"name":"%s","namespace":{"identifier":%d},"version":{"identifier":%d}}`, title, ns, rev)
+}
+
+func segmentTestPath(title string) string {
+	return fmt.Sprintf("json/%s/%s.json", segmentTestDbName, title)
+}
+
+func segmentTestGet(assert *assert.Assertions, store *Storage, title string) string {
+	rc, err := store.Get(segmentTestPath(title))
+	assert.NoError(err)
+
+	if err != nil {
+		return ""
+	}
+
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	assert.NoError(err)
+
+	return string(data)
+}
+
+func segmentTestWalk(assert *assert.Assertions, store *Storage, path string) []string {
+	paths := []string{}
+	assert.NoError(store.Walk(path, func(path string) {
+		paths = append(paths, path)
+	}))
+
+	return paths
+}
+
+func TestStorage(t *testing.T) {
+	assert := assert.New(t)
+	vol := t.TempDir()
+	store := NewStorage(vol)
+	store.SyncInterval = time.Hour
+	defer store.Close()
+
+	for title, ns := range segmentTestPages {
+		assert.NoError(store.Put(segmentTestPath(title), strings.NewReader(segmentTestBody(title, ns, 1))))
+	}
+
+	t.Run("get", func(t *testing.T) {
+		for title, ns := range segmentTestPages {
+			assert.Equal(segmentTestBody(title, ns, 1), segmentTestGet(assert, store, title))
+		}
+
+		_, err := store.Get(segmentTestPath("Mars"))
+		assert.True(errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("walk", func(t *testing.T) {
+		assert.Equal([]string{
+			segmentTestPath("Earth"),
+			segmentTestPath("Moon"),
+			segmentTestPath("Talk:Moon/Sub"),
+			segmentTestPath("Category:Rock"),
+		}, segmentTestWalk(assert, store, fmt.Sprintf("json/%s", segmentTestDbName)))
+		assert.Equal([]string{
+			segmentTestPath("Category:Rock"),
+		}, segmentTestWalk(assert, store, NsLoc(segmentTestDbName, 14)))
+		assert.Empty(segmentTestWalk(assert, store, "json/enwiki"))
+	})
+
+	t.Run("put and delete", func(t *testing.T) {
+		assert.NoError(store.Put(segmentTestPath("Earth"), strings.NewReader(segmentTestBody("Earth", 0, 2))))
+		assert.Equal(segmentTestBody("Earth", 0, 2), segmentTestGet(assert, store, "Earth"))
+
+		assert.NoError(store.Delete(segmentTestPath("Moon")))
+		_, err := store.Get(segmentTestPath("Moon"))
+		assert.Equal(ErrNotFound, err)
+		assert.Equal(ErrNotFound, store.Delete(segmentTestPath("Moon")))
+		assert.Equal([]string{segmentTestPath("Earth")}, segmentTestWalk(assert, store, NsLoc(segmentTestDbName, 0)))
+	})
+
+	t.Run("shared volume", func(t *testing.T) {
+		other := NewStorage(vol)
+		defer other.Close()
+
+		assert.Equal(segmentTestBody("Earth", 0, 2), segmentTestGet(assert, other, "Earth"))
+		assert.NoError(other.Put(segmentTestPath("Mars"), strings.NewReader(segmentTestBody("Mars", 0, 1))))
+
+		// reads don't look for records of other processes until the sync interval passes
+		_, err := store.Get(segmentTestPath("Mars"))
+		assert.Equal(ErrNotFound, err)
+		assert.NoError(store.Sync(segmentTestDbName))
+		assert.Equal(segmentTestBody("Mars", 0, 1), segmentTestGet(assert, store, "Mars"))
+	})
+
+	t.Run("sync interval", func(t *testing.T) {
+		other := NewStorage(vol)
+		defer other.Close()
+
+		store.SyncInterval = time.Millisecond
+		defer func() { store.SyncInterval = time.Hour }()
+
+		assert.NoError(other.Put(segmentTestPath("Jupiter"), strings.NewReader(segmentTestBody("Jupiter", 0, 1))))
+		time.Sleep(5 * time.Millisecond)
+		assert.Equal(segmentTestBody("Jupiter", 0, 1), segmentTestGet(assert, store, "Jupiter"))
+		assert.NoError(other.Delete(segmentTestPath("Jupiter")))
+	})
+
+	t.Run("compact", func(t *testing.T) {
+		assert.NoError(store.Compact(segmentTestDbName))
+
+		other := NewStorage(vol)
+		defer other.Close()
+
+		segs, err := filepath.Glob(filepath.Join(vol, dir, segmentTestDbName, "*"+ext))
+		assert.NoError(err)
+		assert.Len(segs, 1)
+		assert.Equal(segmentTestBody("Earth", 0, 2), segmentTestGet(assert, other, "Earth"))
+		assert.Equal(segmentTestBody("Earth", 0, 2), segmentTestGet(assert, store, "Earth"))
+		assert.Len(segmentTestWalk(assert, other, fmt.Sprintf("json/%s", segmentTestDbName)), 4)
+	})
+
+	t.Run("torn record", func(t *testing.T) {
+		segs, err := filepath.Glob(filepath.Join(vol, dir, segmentTestDbName, "*"+ext))
+		assert.NoError(err)
+
+		file, err := os.OpenFile(segs[len(segs)-1], os.O_APPEND|os.O_WRONLY, 0666)
+		assert.NoError(err)
+		_, err = file.Write([]byte{opPut, 0, 0})
+		assert.NoError(err)
+		assert.NoError(file.Close())
+
+		other := NewStorage(vol)
+		defer other.Close()
+
+		assert.NoError(other.Put(segmentTestPath("Venus"), strings.NewReader(segmentTestBody("Venus", 0, 1))))
+		assert.NoError(store.Sync(segmentTestDbName))
+		assert.Equal(segmentTestBody("Venus", 0, 1), segmentTestGet(assert, store, "Venus"))
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		store := NewStorage(t.TempDir())
+		store.MaxSize = 64
+		defer store.Close()
+
+		for i := 0; i < 5; i++ {
+			title := fmt.Sprintf("Page_%d", i)
+			assert.NoError(store.Put(segmentTestPath(title), strings.NewReader(segmentTestBody(title, 0, 1))))
+		}
+
+		segs, err := filepath.Glob(filepath.Join(store.vol, dir, segmentTestDbName, "*"+ext))
+		assert.NoError(err)
+		assert.Len(segs, 5)
+		assert.Equal(segmentTestBody("Page_0", 0, 1), segmentTestGet(assert, store, "Page_0"))
+	})
+
+	t.Run("max pages", func(t *testing.T) {
+		store := NewStorage(t.TempDir())
+		store.MaxPages = 2
+		defer store.Close()
+
+		for _, title := range []string{"Earth", "Moon"} {
+			assert.NoError(store.Put(segmentTestPath(title), strings.NewReader(segmentTestBody(title, 0, 1))))
+		}
+
+		assert.Equal(ErrTooManyPages, store.Put(segmentTestPath("Mars"), strings.NewReader(segmentTestBody("Mars", 0, 1))))
+		assert.NoError(store.Put(segmentTestPath("Moon"), strings.NewReader(segmentTestBody("Moon", 0, 2))))
+	})
+
+	t.Run("compressed page", func(t *testing.T) {
+		data, err := compress.Encode(compress.Gzip, []byte(segmentTestBody("Category:Gas", 14, 1)))
+		assert.NoError(err)
+		assert.NoError(store.Put(segmentTestPath("Category:Gas"), bytes.NewReader(data)))
+
+		assert.Equal(string(data), segmentTestGet(assert, store, "Category:Gas"))
+		assert.Contains(segmentTestWalk(assert, store, NsLoc(segmentTestDbName, 14)), segmentTestPath("Category:Gas"))
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		assert.Equal(ErrInvalidPath, store.Put("json/Earth.json", strings.NewReader("{}")))
+		assert.Equal(ErrNoNamespace, store.Put(segmentTestPath("Pluto"), strings.NewReader("{}")))
+		assert.Equal(ErrInvalidPath, store.Walk("json/", func(string) {}))
+	})
+}
@@ -0,0 +1,34 @@
+// Package titlepath reversible encoding of page titles into storage paths.
+// Keep in sync with the copies in the batch and API services, all of them have to produce the same paths.
+package titlepath
+
+import (
+	"net/url"
+	"strings"
+)
+
+const hex = "0123456789ABCDEF"
+
+// Encode make the title safe to use as a single path segment on the filesystem and S3,
+// "%", "/", "\" and control characters are percent encoded, everything else is kept as is
+func Encode(title string) string {
+	var sb strings.Builder
+	sb.Grow(len(title))
+
+	for i := 0; i < len(title); i++ {
+		if c := title[i]; c == '%' || c == '/' || c == '\\' || c < 0x20 || c == 0x7f {
+			sb.WriteByte('%')
+			sb.WriteByte(hex[c>>4])
+			sb.WriteByte(hex[c&15])
+		} else {
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
+
+// Decode get the original title out of the encoded path segment
+func Decode(name string) (string, error) {
+	return url.PathUnescape(name)
+}
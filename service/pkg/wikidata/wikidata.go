@@ -0,0 +1,238 @@
+// Package wikidata minimal wikidata API client for entity labels, descriptions and instance of values.
+package wikidata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const actionsURL = "/w/api.php"
+
+// propInstanceOf wikidata "instance of" property
+const propInstanceOf = "P31"
+
+// batchSize max number of entities in a single API request
+const batchSize = 50
+
+// NewClient create new wikidata API client
+func NewClient(url string, headers map[string]string) *Client {
+	return &Client{
+		URL:        url,
+		HTTPClient: new(http.Client),
+		Headers:    headers,
+		Expire:     time.Hour * 24,
+	}
+}
+
+// Client wikidata API client, caches entities in redis if cache is provided
+type Client struct {
+	URL        string
+	HTTPClient *http.Client
+	Headers    map[string]string
+	Cache      redis.Cmdable
+	Expire     time.Duration
+}
+
+// Entity wikidata item in a certain language
+type Entity struct {
+	Identifier  string   `json:"identifier"`
+	Label       string   `json:"label"`
+	Description string   `json:"description"`
+	InstanceOf  []string `json:"instance_of"`
+}
+
+type value struct {
+	Value string `json:"value"`
+}
+
+type claim struct {
+	Mainsnak struct {
+		Datavalue struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"datavalue"`
+	} `json:"mainsnak"`
+}
+
+type response struct {
+	Entities map[string]struct {
+		ID           string             `json:"id"`
+		Missing      *string            `json:"missing"`
+		Labels       map[string]value   `json:"labels"`
+		Descriptions map[string]value   `json:"descriptions"`
+		Claims       map[string][]claim `json:"claims"`
+	} `json:"entities"`
+	Error *struct {
+		Code string `json:"code"`
+		Info string `json:"info"`
+	} `json:"error"`
+}
+
+// Entities get entities by identifiers in the language, missing entities are omitted
+func (cl *Client) Entities(ctx context.Context, lang string, ids ...string) (map[string]*Entity, error) {
+	ents := map[string]*Entity{}
+	misses := []string{}
+
+	for _, id := range unique(ids) {
+		if ent := cl.get(ctx, lang, id); ent != nil {
+			ents[id] = ent
+		} else {
+			misses = append(misses, id)
+		}
+	}
+
+	for i := 0; i < len(misses); i += batchSize {
+		end := i + batchSize
+
+		if end > len(misses) {
+			end = len(misses)
+		}
+
+		res, err := cl.fetch(ctx, lang, misses[i:end])
+
+		if err != nil {
+			return ents, err
+		}
+
+		for id, ent := range res {
+			ents[id] = ent
+			cl.set(ctx, lang, ent)
+		}
+	}
+
+	return ents, nil
+}
+
+func (cl *Client) fetch(ctx context.Context, lang string, ids []string) (map[string]*Entity, error) {
+	params := url.Values{
+		"action":           []string{"wbgetentities"},
+		"format":           []string{"json"},
+		"ids":              []string{strings.Join(ids, "|")},
+		"props":            []string{"labels|descriptions|claims"},
+		"languages":        []string{lang},
+		"languagefallback": []string{"1"},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cl.URL+actionsURL, strings.NewReader(params.Encode()))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	for key, value := range cl.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := cl.HTTPClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status: '%d' body: '%s'", resp.StatusCode, data)
+	}
+
+	res := new(response)
+
+	if err := json.Unmarshal(data, res); err != nil {
+		return nil, err
+	}
+
+	if res.Error != nil {
+		return nil, fmt.Errorf("code: '%s' info: '%s'", res.Error.Code, res.Error.Info)
+	}
+
+	ents := map[string]*Entity{}
+
+	for id, data := range res.Entities {
+		if data.Missing != nil {
+			continue
+		}
+
+		ent := &Entity{
+			Identifier:  id,
+			Label:       data.Labels[lang].Value,
+			Description: data.Descriptions[lang].Value,
+		}
+
+		for _, clm := range data.Claims[propInstanceOf] {
+			item := new(struct {
+				ID string `json:"id"`
+			})
+
+			if err := json.Unmarshal(clm.Mainsnak.Datavalue.Value, item); err == nil && len(item.ID) > 0 {
+				ent.InstanceOf = append(ent.InstanceOf, item.ID)
+			}
+		}
+
+		ents[id] = ent
+	}
+
+	return ents, nil
+}
+
+func (cl *Client) get(ctx context.Context, lang string, id string) *Entity {
+	if cl.Cache == nil {
+		return nil
+	}
+
+	data, err := cl.Cache.Get(ctx, key(lang, id)).Bytes()
+
+	if err != nil {
+		return nil
+	}
+
+	ent := new(Entity)
+
+	if err := json.Unmarshal(data, ent); err != nil {
+		return nil
+	}
+
+	return ent
+}
+
+func (cl *Client) set(ctx context.Context, lang string, ent *Entity) {
+	if cl.Cache == nil {
+		return
+	}
+
+	if data, err := json.Marshal(ent); err == nil {
+		cl.Cache.Set(ctx, key(lang, ent.Identifier), data, cl.Expire)
+	}
+}
+
+func key(lang string, id string) string {
+	return fmt.Sprintf("wikidata/%s/%s", lang, id)
+}
+
+func unique(ids []string) []string {
+	seen := map[string]bool{}
+	res := []string{}
+
+	for _, id := range ids {
+		if len(id) > 0 && !seen[id] {
+			seen[id] = true
+			res = append(res, id)
+		}
+	}
+
+	return res
+}
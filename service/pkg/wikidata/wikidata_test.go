@@ -0,0 +1,119 @@
+package wikidata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+const wikidataTestLang = "en"
+
+const wikidataTestResponse = `{
+	"entities": {
+		"Q42": {
+			"type": "item",
+			"id": "Q42",
+			"labels": {"en": {"language": "en", "value": "Douglas Adams"}},
+			"descriptions": {"en": {"language": "en", "value": "English writer and humorist"}},
+			"claims": {
+				"P31": [{"mainsnak": {"snaktype": "value", "property": "P31", "datavalue": {"value": {"entity-type": "item", "numeric-id": 5, "id": "Q5"}, "type": "wikibase-entityid"}}}]
+			}
+		},
+		"Q5": {
+			"type": "item",
+			"id": "Q5",
+			"labels": {"en": {"language": "en", "value": "human"}},
+			"descriptions": {},
+			"claims": {}
+		},
+		"Q0": {"id": "Q0", "missing": ""}
+	}
+}`
+
+type wikidataCacheMock struct {
+	redis.Cmdable
+	data map[string]string
+}
+
+func (c *wikidataCacheMock) Get(ctx context.Context, key string) *redis.StringCmd {
+	if val, ok := c.data[key]; ok {
+		return redis.NewStringResult(val, nil)
+	}
+
+	return redis.NewStringResult("", redis.Nil)
+}
+
+func (c *wikidataCacheMock) Set(ctx context.Context, key string, value interface{}, _ time.Duration) *redis.StatusCmd {
+	c.data[key] = string(value.([]byte))
+	return redis.NewStatusResult("OK", nil)
+}
+
+func createWikidataServer(calls *int) http.Handler {
+	router := http.NewServeMux()
+
+	router.HandleFunc(actionsURL, func(rw http.ResponseWriter, r *http.Request) {
+		*calls++
+
+		if r.FormValue("action") != "wbgetentities" || r.FormValue("languages") != wikidataTestLang {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if strings.Contains(r.FormValue("ids"), "Q404") {
+			_, _ = rw.Write([]byte(`{"error": {"code": "no-such-entity", "info": "Could not find an entity with the ID \"Q404\"."}}`))
+			return
+		}
+
+		_, _ = rw.Write([]byte(wikidataTestResponse))
+	})
+
+	return router
+}
+
+func TestEntities(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	calls := 0
+	srv := httptest.NewServer(createWikidataServer(&calls))
+	defer srv.Close()
+
+	cache := &wikidataCacheMock{data: map[string]string{}}
+	cl := NewClient(srv.URL, map[string]string{})
+	cl.Cache = cache
+
+	t.Run("entities success", func(t *testing.T) {
+		ents, err := cl.Entities(ctx, wikidataTestLang, "Q42", "Q5", "Q0", "Q42")
+
+		assert.NoError(err)
+		assert.Len(ents, 2)
+		assert.Equal(&Entity{
+			Identifier:  "Q42",
+			Label:       "Douglas Adams",
+			Description: "English writer and humorist",
+			InstanceOf:  []string{"Q5"},
+		}, ents["Q42"])
+		assert.Equal("human", ents["Q5"].Label)
+		assert.Equal(1, calls)
+		assert.Contains(cache.data, "wikidata/en/Q42")
+	})
+
+	t.Run("entities from cache", func(t *testing.T) {
+		ents, err := cl.Entities(ctx, wikidataTestLang, "Q42", "Q5")
+
+		assert.NoError(err)
+		assert.Len(ents, 2)
+		assert.Equal("Douglas Adams", ents["Q42"].Label)
+		assert.Equal(1, calls)
+	})
+
+	t.Run("entities error", func(t *testing.T) {
+		_, err := cl.Entities(ctx, wikidataTestLang, "Q404")
+		assert.Error(err)
+	})
+}
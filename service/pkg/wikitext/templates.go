@@ -0,0 +1,155 @@
+package wikitext
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Template invocation of the template inside wikitext
+type Template struct {
+	Name       string
+	Parameters map[string]string
+}
+
+type part struct {
+	text string
+	eq   int
+}
+
+type parser struct {
+	text      string
+	templates []*Template
+}
+
+// Templates parse all the template invocations (nested ones included) from the wikitext
+func Templates(text string) []*Template {
+	p := &parser{text: text}
+	p.scan(0, "")
+
+	tpls := []*Template{}
+
+	for _, tpl := range p.templates {
+		if tpl != nil {
+			tpls = append(tpls, tpl)
+		}
+	}
+
+	return tpls
+}
+
+// NormalizeName bring template name to the canonical form (no namespace prefix, spaces instead of underscores)
+func NormalizeName(name string) string {
+	name = strings.Join(strings.Fields(strings.ReplaceAll(name, "_", " ")), " ")
+
+	for _, prefix := range []string{"safesubst:", "subst:", "msgnw:", "template:"} {
+		if len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			name = strings.TrimSpace(name[len(prefix):])
+		}
+	}
+
+	return name
+}
+
+// scan walk the text starting from pos until closer is found (or till the end of the text if closer is empty),
+// returns top level parts split by pipes, the position after the closer and whether closer was found
+func (p *parser) scan(pos int, closer string) ([]part, int, bool) {
+	parts := []part{}
+	cur, eq, links := new(strings.Builder), -1, 0
+
+	for pos < len(p.text) {
+		rest := p.text[pos:]
+
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest, "-->")
+
+			if end < 0 {
+				pos = len(p.text)
+				continue
+			}
+
+			pos += end + len("-->")
+		case strings.HasPrefix(rest, "<nowiki>"):
+			end := strings.Index(rest, "</nowiki>")
+
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += len("</nowiki>")
+			}
+
+			cur.WriteString(rest[:end])
+			pos += end
+		case strings.HasPrefix(rest, "{{{"):
+			_, end, _ := p.scan(pos+3, "}}}")
+			cur.WriteString(p.text[pos:end])
+			pos = end
+		case strings.HasPrefix(rest, "{{"):
+			end := p.template(pos)
+			cur.WriteString(p.text[pos:end])
+			pos = end
+		case strings.HasPrefix(rest, "[["):
+			links++
+			cur.WriteString("[[")
+			pos += 2
+		case strings.HasPrefix(rest, "]]") && links > 0:
+			links--
+			cur.WriteString("]]")
+			pos += 2
+		case len(closer) > 0 && links == 0 && strings.HasPrefix(rest, closer):
+			return append(parts, part{cur.String(), eq}), pos + len(closer), true
+		case closer == "}}" && links == 0 && rest[0] == '|':
+			parts = append(parts, part{cur.String(), eq})
+			cur, eq = new(strings.Builder), -1
+			pos++
+		default:
+			if rest[0] == '=' && eq < 0 && links == 0 {
+				eq = cur.Len()
+			}
+
+			cur.WriteByte(rest[0])
+			pos++
+		}
+	}
+
+	return append(parts, part{cur.String(), eq}), pos, false
+}
+
+// template parse single template invocation starting at pos, returns position after the invocation
+func (p *parser) template(pos int) int {
+	slot := len(p.templates)
+	p.templates = append(p.templates, nil)
+	parts, end, closed := p.scan(pos+2, "}}")
+
+	if !closed {
+		return end
+	}
+
+	name := NormalizeName(parts[0].text)
+
+	if len(name) == 0 || strings.HasPrefix(name, "#") {
+		return end
+	}
+
+	tpl := &Template{
+		Name:       name,
+		Parameters: map[string]string{},
+	}
+
+	num := 0
+
+	for _, prt := range parts[1:] {
+		if prt.eq >= 0 {
+			if key := strings.TrimSpace(prt.text[:prt.eq]); len(key) > 0 {
+				tpl.Parameters[key] = strings.TrimSpace(prt.text[prt.eq+1:])
+				continue
+			}
+		}
+
+		num++
+		tpl.Parameters[strconv.Itoa(num)] = strings.TrimSpace(prt.text)
+	}
+
+	p.templates[slot] = tpl
+	return end
+}
@@ -0,0 +1,52 @@
+package wikitext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const templatesTestWikitext = `{{Short description|Third planet from the Sun}}
+{{Infobox planet
+| name = Earth <!-- not a [[comment]] -->
+| image = [[File:Earth.jpg|thumb|Earth]]
+| mass = {{val|5.97237|e=24|u=kg}}
+| {{{unused|x}}}
+| positional
+}}
+'''Earth''' is the third planet from the Sun.{{#if:{{{1|}}}|yes|no}}
+{{Template:Infobox_settlement|name=Moon}}
+{{unterminated|a=b`
+
+func TestTemplates(t *testing.T) {
+	assert := assert.New(t)
+
+	tpls := Templates(templatesTestWikitext)
+	assert.Len(tpls, 4)
+
+	assert.Equal("Short description", tpls[0].Name)
+	assert.Equal("Third planet from the Sun", tpls[0].Parameters["1"])
+
+	assert.Equal("Infobox planet", tpls[1].Name)
+	assert.Equal("Earth", tpls[1].Parameters["name"])
+	assert.Equal("[[File:Earth.jpg|thumb|Earth]]", tpls[1].Parameters["image"])
+	assert.Equal("{{val|5.97237|e=24|u=kg}}", tpls[1].Parameters["mass"])
+	assert.Equal("{{{unused|x}}}", tpls[1].Parameters["1"])
+	assert.Equal("positional", tpls[1].Parameters["2"])
+
+	assert.Equal("val", tpls[2].Name)
+	assert.Equal("5.97237", tpls[2].Parameters["1"])
+	assert.Equal("24", tpls[2].Parameters["e"])
+	assert.Equal("kg", tpls[2].Parameters["u"])
+
+	assert.Equal("Infobox settlement", tpls[3].Name)
+	assert.Equal("Moon", tpls[3].Parameters["name"])
+}
+
+func TestNormalizeName(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("Infobox person", NormalizeName(" Infobox_person\n"))
+	assert.Equal("Infobox person", NormalizeName("Template:Infobox  person"))
+	assert.Equal("Cite web", NormalizeName("subst:Cite web"))
+}
@@ -15,10 +15,10 @@ import (
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
-	"github.com/protsack-stephan/dev-toolkit/lib/s3"
 
 	"okapi-data-service/lib/elastic"
 	"okapi-data-service/pkg/page"
+	"okapi-data-service/pkg/segment"
 	"okapi-data-service/pkg/worker"
 	"okapi-data-service/queues/pagedelete"
 	"okapi-data-service/queues/pagefetch"
@@ -84,11 +84,15 @@ func main() {
 	}
 
 	json := fs.NewStorage(env.JSONVol)
-	remote := s3.NewStorage(aws.Session(), env.AWSBucket)
+	remote := page.NewS3(aws.Session(), env.AWSBucket)
 	store := store.Client()
 	elastic := elastic.Client()
 	repo := db.NewRepository(pg.Conn())
-	storage := &page.Storage{Local: json, Remote: remote}
+	storage := &page.Storage{Local: json, Remote: remote, Encoding: env.JSONEncoding}
+
+	if env.JSONStore == segment.Name {
+		storage.Local = segment.NewStorage(env.JSONVol)
+	}
 
 	queues := []queue{
 		{
@@ -99,12 +103,12 @@ func main() {
 		{
 			workers: env.PagefetchWorkers,
 			name:    pagefetch.Name,
-			worker:  pagefetch.Worker(new(fetch.Factory), storage, repo, producer),
+			worker:  pagefetch.Worker(&fetch.Factory{Cache: store}, storage, repo, producer, store),
 		},
 		{
 			workers: env.PagevisibilityWorkers,
 			name:    pagevisibility.Name,
-			worker:  pagevisibility.Worker(repo, storage, producer),
+			worker:  pagevisibility.Worker(repo, storage, producer, store),
 		},
 	}
 
@@ -156,6 +160,27 @@ func main() {
 		}
 	}
 
+	if name == all || fmt.Sprintf("queue/%s", name) == pagefetch.Name {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ticker := time.NewTicker(pagefetch.ReleaseInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := pagefetch.Release(ctx, store); err != nil {
+						log.Printf("name: %s, quarantine: %v\n", pagefetch.Name, err)
+					}
+				}
+			}
+		}()
+	}
+
 	log.Println(<-sign)
 	cancel()
 	wg.Wait()
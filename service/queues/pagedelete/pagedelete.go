@@ -13,6 +13,7 @@ import (
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/go-pg/pg/v10"
 	"github.com/go-pg/pg/v10/orm"
 	"github.com/go-redis/redis/v8"
 	"github.com/protsack-stephan/dev-toolkit/pkg/repository"
@@ -24,9 +25,10 @@ const Name string = "queue/pagedelete"
 
 // Data item of the queue
 type Data struct {
-	Title  string         `json:"title"`
-	DbName string         `json:"db_name"`
-	Editor *schema.Editor `json:"editor,omitempty"`
+	Title    string         `json:"title"`
+	DbName   string         `json:"db_name"`
+	Editor   *schema.Editor `json:"editor,omitempty"`
+	Redirect bool           `json:"redirect,omitempty"` // page became a redirect, its redirect record is kept
 }
 
 // Repo all the needed repositories to call delete
@@ -55,7 +57,15 @@ func Worker(repo Repo, storage Storage, producer producer.Producer, elastic *ela
 			return q.Where("title = ? and db_name = ?", data.Title, data.DbName)
 		}
 
-		if err := repo.Find(ctx, page, query); err != nil {
+		// redirects don't have page records, only the redirect record needs to go
+		if err := repo.Find(ctx, page, query); err == pg.ErrNoRows {
+			if data.Redirect {
+				return nil
+			}
+
+			_, err := repo.Delete(ctx, new(models.Redirect), query)
+			return err
+		} else if err != nil {
 			return err
 		}
 
@@ -16,6 +16,7 @@ import (
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/go-pg/pg/v10"
 	"github.com/go-pg/pg/v10/orm"
 	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/assert"
@@ -96,6 +97,8 @@ func (r *repoMock) Delete(_ context.Context, model interface{}, _ func(*orm.Quer
 	switch model := model.(type) {
 	case *models.Page:
 		return nil, r.Called(*model).Error(0)
+	case *models.Redirect:
+		return nil, r.Called(*model).Error(0)
 	}
 
 	return nil, errors.New("unknown call")
@@ -240,6 +243,37 @@ func TestPagedelete(t *testing.T) {
 		repo.AssertNotCalled(t, "Delete", page)
 	})
 
+	t.Run("worker redirect", func(t *testing.T) {
+		repo := new(repoMock)
+		repo.url = srv.URL
+		repo.On("Find", models.Page{}).Return(pg.ErrNoRows)
+		repo.On("Delete", models.Redirect{}).Return(nil)
+
+		worker := Worker(repo, new(storageMock), new(producerMock), els)
+
+		assert.NoError(worker(ctx, data))
+		repo.AssertCalled(t, "Delete", models.Redirect{})
+		repo.AssertNotCalled(t, "Delete", page)
+	})
+
+	t.Run("worker page became redirect", func(t *testing.T) {
+		repo := new(repoMock)
+		repo.url = srv.URL
+		repo.On("Find", models.Page{}).Return(pg.ErrNoRows)
+
+		data, err := json.Marshal(&Data{
+			Title:    pagedeleteTestTitle,
+			DbName:   pagedeleteTestDbName,
+			Redirect: true,
+		})
+		assert.NoError(err)
+
+		worker := Worker(repo, new(storageMock), new(producerMock), els)
+
+		assert.NoError(worker(ctx, data))
+		repo.AssertNotCalled(t, "Delete", models.Redirect{})
+	})
+
 	t.Run("worker JSON format error", func(t *testing.T) {
 		repo := new(repoMock)
 		repo.On("Find", models.Page{}).Return(nil)
@@ -4,12 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"log"
 	"net/http"
 	"okapi-data-service/lib/env"
 	"okapi-data-service/models"
+	"okapi-data-service/pkg/editors"
 	"okapi-data-service/pkg/page"
 	"okapi-data-service/pkg/producer"
+	"okapi-data-service/pkg/quarantine"
 	"okapi-data-service/pkg/worker"
+	"okapi-data-service/queues/pagedelete"
 	"okapi-data-service/schema/v3"
 	"okapi-data-service/server/pages/fetch"
 	"sync"
@@ -24,6 +28,12 @@ import (
 // Name redis key for the queue
 const Name string = "queue/pagefetch"
 
+// ReleaseInterval how often quarantined revisions are checked for release
+const ReleaseInterval = time.Second * 30
+
+// holdAttempts number of attempts to apply the publishing policy while other workers keep changing the held revision
+const holdAttempts = 3
+
 // ErrPageNotFound page was not found
 var ErrPageNotFound = errors.New("page not found")
 
@@ -32,17 +42,18 @@ var clients sync.Map
 
 // Data item of the queue
 type Data struct {
-	Title     string         `json:"title"`
-	Revision  int            `json:"revision"`
-	DbName    string         `json:"db_name"`
-	Lang      string         `json:"lang"`
-	SiteURL   string         `json:"site_url"`
-	Namespace int            `json:"namespace"`
-	Scores    *schema.Scores `json:"scores,omitempty"`
-	Editor    *schema.Editor `json:"editor,omitempty"`
+	Title      string             `json:"title"`
+	Revision   int                `json:"revision"`
+	DbName     string             `json:"db_name"`
+	Lang       string             `json:"lang"`
+	SiteURL    string             `json:"site_url"`
+	Namespace  int                `json:"namespace"`
+	Scores     *schema.Scores     `json:"scores,omitempty"`
+	Editor     *schema.Editor     `json:"editor,omitempty"`
+	Quarantine *schema.Quarantine `json:"quarantine,omitempty"`
 }
 
-func Worker(fetcher fetch.FetcherFactory, store fetch.Storage, repo fetch.Repo, producer producer.Producer) worker.Worker {
+func Worker(fetcher fetch.FetcherFactory, store fetch.Storage, repo fetch.Repo, producer producer.Producer, cache redis.Cmdable) worker.Worker {
 	return func(ctx context.Context, payload []byte) error {
 		data := new(Data)
 
@@ -63,6 +74,10 @@ func Worker(fetcher fetch.FetcherFactory, store fetch.Storage, repo fetch.Repo,
 			return err
 		}
 
+		if held, err := hold(ctx, cache, proj, data); err != nil || held {
+			return err
+		}
+
 		ns := new(models.Namespace)
 		nquery := func(q *orm.Query) *orm.Query {
 			return q.Where("lang = ? and id = ?", proj.Lang, data.Namespace)
@@ -72,6 +87,23 @@ func Worker(fetcher fetch.FetcherFactory, store fetch.Storage, repo fetch.Repo,
 			return err
 		}
 
+		license, err := page.License(ctx, repo, proj.DbName, ns.ID)
+
+		if err != nil {
+			return err
+		}
+
+		prev := []*models.Page{}
+		hquery := func(q *orm.Query) *orm.Query {
+			return q.
+				Column("content_hash").
+				Where("db_name = ? and title = ?", data.DbName, data.Title)
+		}
+
+		if err := repo.Find(ctx, &prev, hquery); err != nil {
+			return err
+		}
+
 		info, _ := clients.LoadOrStore(data.SiteURL, mediawiki.
 			NewBuilder(data.SiteURL).
 			HTTPClient(&http.Client{Timeout: time.Second * 30}).
@@ -85,6 +117,8 @@ func Worker(fetcher fetch.FetcherFactory, store fetch.Storage, repo fetch.Repo,
 				Project:   proj,
 				Language:  proj.Language,
 				Namespace: ns,
+				License:   license,
+				Templates: env.TemplateData,
 			},
 			store,
 			cl,
@@ -99,7 +133,20 @@ func Worker(fetcher fetch.FetcherFactory, store fetch.Storage, repo fetch.Repo,
 			return err
 		}
 
-		if err := errs[data.Title]; err != nil {
+		// redirects are saved by the fetch worker and there's no page to publish,
+		// article that became a redirect is removed the same way as the deleted one
+		if err := errs[data.Title]; err == fetch.ErrPageRedirect {
+			if len(prev) == 0 {
+				return nil
+			}
+
+			return pagedelete.Enqueue(ctx, cache, &pagedelete.Data{
+				Title:    data.Title,
+				DbName:   data.DbName,
+				Editor:   data.Editor,
+				Redirect: true,
+			})
+		} else if err != nil {
 			return err
 		}
 
@@ -112,11 +159,12 @@ func Worker(fetcher fetch.FetcherFactory, store fetch.Storage, repo fetch.Repo,
 		if page.Version != nil {
 			if page.Version.Identifier == data.Revision {
 				page.Version.Editor = data.Editor
+				page.Version.Quarantine = data.Quarantine
 
 				if data.Scores != nil {
 					page.Version.Scores = data.Scores
 				}
-			} else if page.Version.Editor.Identifier != 0 {
+			} else if page.Version.Editor != nil && page.Version.Editor.Identifier != 0 && !editors.Fill(ctx, cache, data.DbName, page.Version.Editor) {
 				user, err := cl.User(ctx, page.Version.Editor.Identifier)
 
 				if err == nil {
@@ -132,10 +180,30 @@ func Worker(fetcher fetch.FetcherFactory, store fetch.Storage, repo fetch.Repo,
 							}
 						}
 					}
+
+					_ = editors.Set(ctx, cache, data.DbName, page.Version.Editor)
 				}
 			}
 		}
 
+		if page.Version != nil {
+			rev := models.NewRevision(page, proj)
+			rquery := func(q *orm.Query) *orm.Query {
+				return q.
+					Column("editor_id", "editor_name", "scores", "updated_at").
+					Where("db_name = ? and revision = ?", rev.DbName, rev.Revision)
+			}
+
+			if _, err := repo.Update(ctx, rev, rquery); err != nil {
+				return err
+			}
+		}
+
+		// content hash comes from the fetch worker, so the message has the same one as the stored page
+		if isUnchanged(prev, page, data) {
+			return nil
+		}
+
 		value, err := json.Marshal(page)
 
 		if err != nil {
@@ -161,6 +229,113 @@ func Worker(fetcher fetch.FetcherFactory, store fetch.Storage, repo fetch.Repo,
 	}
 }
 
+// hold apply project publishing policy to the revision, returns true if the revision was put into quarantine
+// or a newer revision of the page is already held there, retries if other worker changed the held revision meanwhile
+func hold(ctx context.Context, store redis.Cmdable, proj *models.Project, data *Data) (bool, error) {
+	if store == nil {
+		return false, nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		held, err := tryHold(ctx, store, proj, data)
+
+		if err != quarantine.ErrChanged || attempt >= holdAttempts {
+			return held, err
+		}
+	}
+}
+
+// tryHold apply publishing policy against the revision held at the moment
+func tryHold(ctx context.Context, store redis.Cmdable, proj *models.Project, data *Data) (bool, error) {
+	key := quarantine.Key(data.DbName, data.Title)
+	prev := new(Data)
+	held, err := quarantine.Get(ctx, store, key, prev)
+
+	if err != nil {
+		return false, err
+	}
+
+	exists := len(held) > 0
+
+	// older revision is dropped, the held one is newer and will be published on release
+	if exists && prev.Revision > data.Revision {
+		log.Printf("db_name: %s, title: %s, revision: %d, held revision: %d", data.DbName, data.Title, data.Revision, prev.Revision)
+		return true, nil
+	}
+
+	var reasons []string
+
+	if proj.Quarantine != nil && (data.Quarantine == nil || data.Quarantine.DateReleased == nil) {
+		reasons = proj.Quarantine.Check(data.DbName, data.Scores, data.Editor)
+
+		if len(reasons) == 0 {
+			data.Quarantine = &schema.Quarantine{Held: false}
+		}
+	}
+
+	// newer revision or scores of the same revision replace the held one
+	if len(reasons) == 0 {
+		if exists {
+			return false, quarantine.Drop(ctx, store, key, held)
+		}
+
+		return false, nil
+	}
+
+	dt := time.Now().UTC()
+
+	if exists && prev.Revision == data.Revision && prev.Quarantine != nil && prev.Quarantine.DateHeld != nil {
+		dt = *prev.Quarantine.DateHeld
+	}
+
+	data.Quarantine = &schema.Quarantine{
+		Held:     true,
+		Reasons:  reasons,
+		DateHeld: &dt,
+	}
+
+	return true, quarantine.Hold(ctx, store, key, held, data, proj.Quarantine.Release(dt))
+}
+
+// Release put quarantined revisions that are due back into the queue
+func Release(ctx context.Context, store redis.Cmdable) error {
+	keys, err := quarantine.Due(ctx, store, time.Now().UTC())
+
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		data := new(Data)
+		taken, err := quarantine.Take(ctx, store, key, data)
+
+		if err != nil {
+			return err
+		}
+
+		if !taken || data.Quarantine == nil {
+			continue
+		}
+
+		dt := time.Now().UTC()
+		data.Quarantine.DateReleased = &dt
+
+		if err := Enqueue(ctx, store, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isUnchanged check if stored content is the same as fetched one and event has nothing new to add
+func isUnchanged(prev []*models.Page, sch *schema.Page, data *Data) bool {
+	return data.Scores == nil &&
+		len(prev) > 0 &&
+		len(prev[0].ContentHash) > 0 &&
+		prev[0].ContentHash == sch.ContentHash
+}
+
 // Enqueue add data to the worker queue
 func Enqueue(ctx context.Context, store redis.Cmdable, data *Data) error {
 	return worker.Enqueue(ctx, Name, store, data)
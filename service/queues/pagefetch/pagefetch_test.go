@@ -6,15 +6,20 @@ import (
 	"errors"
 	"io"
 	"okapi-data-service/models"
+	"okapi-data-service/pkg/editors"
 	"okapi-data-service/pkg/page"
+	"okapi-data-service/pkg/quarantine"
+	"okapi-data-service/queues/pagedelete"
 	"okapi-data-service/schema/v3"
 	"okapi-data-service/server/pages/fetch"
 	"testing"
+	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"github.com/go-pg/pg/v10/orm"
 	"github.com/go-redis/redis/v8"
 	"github.com/protsack-stephan/mediawiki-api-client"
+	ores "github.com/protsack-stephan/mediawiki-ores-client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -24,18 +29,126 @@ const pagefetchTestDbName = "test"
 const pagefetchTestLang = "en"
 const pagefetchTestNamespace = 14
 const pagefetchTestSiteURL = "https://uk.wikipedia.org"
+const pagefetchTestRevision = 100
+const pagefetchTestHash = "hash"
+const pagefetchTestEditorID = 10
 
 type pagefetchRedisMock struct {
 	mock.Mock
 	redis.Cmdable
+	data    map[string]string
+	zset    map[string]float64
+	queue   [][]byte
+	changes []string // held payloads other worker writes right after the read
 }
 
-func (s *pagefetchRedisMock) RPush(_ context.Context, _ string, _ ...interface{}) *redis.IntCmd {
+func (s *pagefetchRedisMock) Get(_ context.Context, key string) *redis.StringCmd {
+	val, ok := s.data[key]
+
+	if len(s.changes) > 0 {
+		s.data[key], s.changes = s.changes[0], s.changes[1:]
+	}
+
+	if ok {
+		return redis.NewStringResult(val, nil)
+	}
+
+	return redis.NewStringResult("", redis.Nil)
+}
+
+func (s *pagefetchRedisMock) Set(_ context.Context, key string, value interface{}, _ time.Duration) *redis.StatusCmd {
+	s.data[key] = string(value.([]byte))
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (s *pagefetchRedisMock) Del(_ context.Context, keys ...string) *redis.IntCmd {
+	for _, key := range keys {
+		delete(s.data, key)
+	}
+
+	return redis.NewIntResult(int64(len(keys)), nil)
+}
+
+func (s *pagefetchRedisMock) ZAdd(_ context.Context, _ string, members ...*redis.Z) *redis.IntCmd {
+	for _, member := range members {
+		s.zset[member.Member.(string)] = member.Score
+	}
+
+	return redis.NewIntResult(int64(len(members)), nil)
+}
+
+func (s *pagefetchRedisMock) ZRem(_ context.Context, _ string, members ...interface{}) *redis.IntCmd {
+	n := 0
+
+	for _, member := range members {
+		if _, ok := s.zset[member.(string)]; ok {
+			delete(s.zset, member.(string))
+			n++
+		}
+	}
+
+	return redis.NewIntResult(int64(n), nil)
+}
+
+func (s *pagefetchRedisMock) ZRangeByScore(_ context.Context, _ string, _ *redis.ZRangeBy) *redis.StringSliceCmd {
+	keys := []string{}
+
+	for key := range s.zset {
+		keys = append(keys, key)
+	}
+
+	return redis.NewStringSliceResult(keys, nil)
+}
+
+// EvalSha run quarantine script on the mock, scripts are told apart by the number of arguments
+func (s *pagefetchRedisMock) EvalSha(_ context.Context, _ string, keys []string, args ...interface{}) *redis.Cmd {
+	key := keys[1]
+
+	if len(args) == 0 {
+		if _, ok := s.zset[key]; !ok {
+			return redis.NewCmdResult(nil, redis.Nil)
+		}
+
+		delete(s.zset, key)
+		data, ok := s.data[key]
+		delete(s.data, key)
+
+		if !ok {
+			return redis.NewCmdResult(nil, redis.Nil)
+		}
+
+		return redis.NewCmdResult(data, nil)
+	}
+
+	if s.data[key] != args[0].(string) {
+		return redis.NewCmdResult(int64(0), nil)
+	}
+
+	if len(args) == 1 {
+		delete(s.zset, key)
+		delete(s.data, key)
+	} else {
+		s.data[key] = string(args[1].([]byte))
+		s.zset[key] = float64(args[2].(int64))
+	}
+
+	return redis.NewCmdResult(int64(1), nil)
+}
+
+func (s *pagefetchRedisMock) RPush(_ context.Context, _ string, values ...interface{}) *redis.IntCmd {
+	for _, value := range values {
+		if data, ok := value.([]byte); ok {
+			s.queue = append(s.queue, data)
+		}
+	}
+
 	return new(redis.IntCmd)
 }
 
 type pagefetchRepoMock struct {
 	mock.Mock
+	hash   string
+	policy *quarantine.Policy
 }
 
 func (r *pagefetchRepoMock) Create(_ context.Context, model interface{}, _ ...interface{}) (orm.Result, error) {
@@ -53,9 +166,14 @@ func (r *pagefetchRepoMock) Find(_ context.Context, model interface{}, _ func(*o
 		model.Language = &models.Language{
 			Code: pagefetchTestLang,
 		}
+		model.Quarantine = r.policy
 	case *models.Namespace:
 		model.ID = pagefetchTestNamespace
 		model.Lang = pagefetchTestLang
+	case *[]*models.Page:
+		if len(r.hash) > 0 {
+			*model = append(*model, &models.Page{ContentHash: r.hash})
+		}
 	}
 
 	return args.Error(0)
@@ -65,6 +183,10 @@ func (r *pagefetchRepoMock) Update(_ context.Context, model interface{}, _ func(
 	return nil, r.Called(model).Error(0)
 }
 
+func (r *pagefetchRepoMock) Delete(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, _ ...interface{}) (orm.Result, error) {
+	return nil, r.Called(model).Error(0)
+}
+
 type pagefetchStorageMock struct{}
 
 func (s *pagefetchStorageMock) Put(_ string, _ io.Reader) error {
@@ -107,6 +229,7 @@ func TestPagefetch(t *testing.T) {
 
 	data, err := json.Marshal(Data{
 		Title:     pagefetchTestTitle,
+		Revision:  pagefetchTestRevision,
 		DbName:    pagefetchTestDbName,
 		Lang:      pagefetchTestLang,
 		Namespace: pagefetchTestNamespace,
@@ -136,12 +259,225 @@ func TestPagefetch(t *testing.T) {
 		repo := new(pagefetchRepoMock)
 		repo.On("Find", &models.Project{}).Return(nil)
 		repo.On("Find", &models.Namespace{}).Return(nil)
+		repo.On("Find", &[]*models.License{}).Return(nil)
+		repo.On("Find", &[]*models.Page{}).Return(nil)
+
+		prod := new(pagefetchProducerMock)
+		prod.msgs = make(chan *kafka.Message, 1)
+
+		fetch := Worker(fact, store, repo, prod, nil)
+		assert.NoError(fetch(ctx, data))
+	})
+
+	t.Run("worker unchanged content", func(t *testing.T) {
+		errs := map[string]error{
+			pagefetchTestTitle: nil,
+		}
+
+		pages := map[string]*schema.Page{
+			pagefetchTestTitle: {
+				Name:        pagefetchTestTitle,
+				ContentHash: pagefetchTestHash,
+			},
+		}
+
+		worker := new(pagefetchWorkerMock)
+		worker.On("Fetch", []string{pagefetchTestTitle}).Return(pages, errs, nil)
+
+		fact := new(pagefetchWorkerFactoryMock)
+		fact.On("Create").Return(worker)
+
+		store := new(pagefetchStorageMock)
+
+		repo := new(pagefetchRepoMock)
+		repo.hash = pagefetchTestHash
+		repo.On("Find", &models.Project{}).Return(nil)
+		repo.On("Find", &models.Namespace{}).Return(nil)
+		repo.On("Find", &[]*models.License{}).Return(nil)
+		repo.On("Find", &[]*models.Page{}).Return(nil)
+
+		prod := new(pagefetchProducerMock)
+		prod.msgs = make(chan *kafka.Message, 1)
+
+		fetch := Worker(fact, store, repo, prod, nil)
+		assert.NoError(fetch(ctx, data))
+		assert.Len(prod.msgs, 0)
+	})
+
+	t.Run("worker changed content", func(t *testing.T) {
+		errs := map[string]error{
+			pagefetchTestTitle: nil,
+		}
+
+		pages := map[string]*schema.Page{
+			pagefetchTestTitle: {
+				Name:        pagefetchTestTitle,
+				ContentHash: pagefetchTestHash,
+			},
+		}
+
+		worker := new(pagefetchWorkerMock)
+		worker.On("Fetch", []string{pagefetchTestTitle}).Return(pages, errs, nil)
+
+		fact := new(pagefetchWorkerFactoryMock)
+		fact.On("Create").Return(worker)
+
+		store := new(pagefetchStorageMock)
+
+		repo := new(pagefetchRepoMock)
+		repo.hash = "previous"
+		repo.On("Find", &models.Project{}).Return(nil)
+		repo.On("Find", &models.Namespace{}).Return(nil)
+		repo.On("Find", &[]*models.License{}).Return(nil)
+		repo.On("Find", &[]*models.Page{}).Return(nil)
+
+		prod := new(pagefetchProducerMock)
+		prod.msgs = make(chan *kafka.Message, 1)
+
+		fetch := Worker(fact, store, repo, prod, nil)
+		assert.NoError(fetch(ctx, data))
+		assert.Len(prod.msgs, 1)
+
+		msg := <-prod.msgs
+		page := new(schema.Page)
+		assert.NoError(json.Unmarshal(msg.Value, page))
+		assert.Equal(pagefetchTestHash, page.ContentHash)
+	})
+
+	t.Run("worker editor from cache", func(t *testing.T) {
+		errs := map[string]error{
+			pagefetchTestTitle: nil,
+		}
+
+		pages := map[string]*schema.Page{
+			pagefetchTestTitle: {
+				Name: pagefetchTestTitle,
+				Version: &schema.Version{
+					Identifier: pagefetchTestRevision + 1,
+					Editor: &schema.Editor{
+						Identifier: pagefetchTestEditorID,
+						Name:       "Editor",
+					},
+				},
+			},
+		}
+
+		profile, err := json.Marshal(&schema.Editor{
+			Identifier: pagefetchTestEditorID,
+			Name:       "Editor",
+			EditCount:  100,
+			Groups:     []string{"bot"},
+			IsBot:      true,
+		})
+		assert.NoError(err)
+
+		cache := new(pagefetchRedisMock)
+		cache.data = map[string]string{
+			editors.Key(pagefetchTestDbName, pagefetchTestEditorID): string(profile),
+		}
+
+		worker := new(pagefetchWorkerMock)
+		worker.On("Fetch", []string{pagefetchTestTitle}).Return(pages, errs, nil)
+
+		fact := new(pagefetchWorkerFactoryMock)
+		fact.On("Create").Return(worker)
+
+		store := new(pagefetchStorageMock)
+
+		repo := new(pagefetchRepoMock)
+		repo.On("Find", &models.Project{}).Return(nil)
+		repo.On("Find", &models.Namespace{}).Return(nil)
+		repo.On("Find", &[]*models.License{}).Return(nil)
+		repo.On("Find", &[]*models.Page{}).Return(nil)
+		repo.On("Update", mock.Anything).Return(nil)
+
+		prod := new(pagefetchProducerMock)
+		prod.msgs = make(chan *kafka.Message, 1)
+
+		fetch := Worker(fact, store, repo, prod, cache)
+		assert.NoError(fetch(ctx, data))
+
+		editor := pages[pagefetchTestTitle].Version.Editor
+		assert.Equal(100, editor.EditCount)
+		assert.Equal([]string{"bot"}, editor.Groups)
+		assert.True(editor.IsBot)
+	})
+
+	t.Run("worker revision success", func(t *testing.T) {
+		errs := map[string]error{
+			pagefetchTestTitle: nil,
+		}
+
+		pages := map[string]*schema.Page{
+			pagefetchTestTitle: {
+				Name: pagefetchTestTitle,
+				Version: &schema.Version{
+					Identifier: pagefetchTestRevision,
+					Editor:     &schema.Editor{},
+				},
+			},
+		}
+
+		worker := new(pagefetchWorkerMock)
+		worker.On("Fetch", []string{pagefetchTestTitle}).Return(pages, errs, nil)
+
+		fact := new(pagefetchWorkerFactoryMock)
+		fact.On("Create").Return(worker)
+
+		store := new(pagefetchStorageMock)
+
+		repo := new(pagefetchRepoMock)
+		repo.On("Find", &models.Project{}).Return(nil)
+		repo.On("Find", &models.Namespace{}).Return(nil)
+		repo.On("Find", &[]*models.License{}).Return(nil)
+		repo.On("Find", &[]*models.Page{}).Return(nil)
+		repo.On("Update", mock.MatchedBy(func(rev *models.Revision) bool {
+			return rev.Revision == pagefetchTestRevision && rev.DbName == pagefetchTestDbName
+		})).Return(nil)
 
 		prod := new(pagefetchProducerMock)
 		prod.msgs = make(chan *kafka.Message, 1)
 
-		fetch := Worker(fact, store, repo, prod)
+		fetch := Worker(fact, store, repo, prod, nil)
 		assert.NoError(fetch(ctx, data))
+		repo.AssertNumberOfCalls(t, "Update", 1)
+	})
+
+	t.Run("worker revision error", func(t *testing.T) {
+		errs := map[string]error{
+			pagefetchTestTitle: nil,
+		}
+
+		pages := map[string]*schema.Page{
+			pagefetchTestTitle: {
+				Name: pagefetchTestTitle,
+				Version: &schema.Version{
+					Identifier: pagefetchTestRevision,
+					Editor:     &schema.Editor{},
+				},
+			},
+		}
+
+		worker := new(pagefetchWorkerMock)
+		worker.On("Fetch", []string{pagefetchTestTitle}).Return(pages, errs, nil)
+
+		fact := new(pagefetchWorkerFactoryMock)
+		fact.On("Create").Return(worker)
+
+		store := new(pagefetchStorageMock)
+
+		errUpdate := errors.New("can't update the revision")
+		repo := new(pagefetchRepoMock)
+		repo.On("Find", &models.Project{}).Return(nil)
+		repo.On("Find", &models.Namespace{}).Return(nil)
+		repo.On("Find", &[]*models.License{}).Return(nil)
+		repo.On("Find", &[]*models.Page{}).Return(nil)
+		repo.On("Update", mock.Anything).Return(errUpdate)
+
+		prod := new(pagefetchProducerMock)
+
+		fetch := Worker(fact, store, repo, prod, nil)
+		assert.Equal(errUpdate, fetch(ctx, data))
 	})
 
 	t.Run("worker find project error", func(t *testing.T) {
@@ -154,7 +490,7 @@ func TestPagefetch(t *testing.T) {
 
 		prod := new(pagefetchProducerMock)
 
-		fetch := Worker(fact, store, repo, prod)
+		fetch := Worker(fact, store, repo, prod, nil)
 		assert.Equal(errFind, fetch(ctx, data))
 	})
 
@@ -169,7 +505,7 @@ func TestPagefetch(t *testing.T) {
 
 		prod := new(pagefetchProducerMock)
 
-		fetch := Worker(fact, store, repo, prod)
+		fetch := Worker(fact, store, repo, prod, nil)
 		assert.Equal(errFind, fetch(ctx, data))
 	})
 
@@ -185,10 +521,12 @@ func TestPagefetch(t *testing.T) {
 		repo := new(pagefetchRepoMock)
 		repo.On("Find", &models.Project{}).Return(nil)
 		repo.On("Find", &models.Namespace{}).Return(nil)
+		repo.On("Find", &[]*models.License{}).Return(nil)
+		repo.On("Find", &[]*models.Page{}).Return(nil)
 
 		prod := new(pagefetchProducerMock)
 
-		fetch := Worker(fact, store, repo, prod)
+		fetch := Worker(fact, store, repo, prod, nil)
 		assert.Equal(errFetch, fetch(ctx, data))
 	})
 
@@ -204,12 +542,191 @@ func TestPagefetch(t *testing.T) {
 		repo := new(pagefetchRepoMock)
 		repo.On("Find", &models.Project{}).Return(nil)
 		repo.On("Find", &models.Namespace{}).Return(nil)
+		repo.On("Find", &[]*models.License{}).Return(nil)
+		repo.On("Find", &[]*models.Page{}).Return(nil)
 
 		prod := new(pagefetchProducerMock)
 
-		fetch := Worker(fact, store, repo, prod)
+		fetch := Worker(fact, store, repo, prod, nil)
 		assert.Equal(errPage, fetch(ctx, data))
 	})
+
+	t.Run("worker page redirect", func(t *testing.T) {
+		worker := new(pagefetchWorkerMock)
+		worker.On("Fetch", []string{pagefetchTestTitle}).Return(map[string]*schema.Page{}, map[string]error{pagefetchTestTitle: fetch.ErrPageRedirect}, nil)
+
+		fact := new(pagefetchWorkerFactoryMock)
+		fact.On("Create").Return(worker)
+		store := new(pagefetchStorageMock)
+
+		repo := new(pagefetchRepoMock)
+		repo.On("Find", &models.Project{}).Return(nil)
+		repo.On("Find", &models.Namespace{}).Return(nil)
+		repo.On("Find", &[]*models.License{}).Return(nil)
+		repo.On("Find", &[]*models.Page{}).Return(nil)
+
+		prod := new(pagefetchProducerMock)
+
+		assert.NoError(Worker(fact, store, repo, prod, nil)(ctx, data))
+		repo.AssertNumberOfCalls(t, "Update", 0)
+	})
+
+	t.Run("worker article became redirect", func(t *testing.T) {
+		worker := new(pagefetchWorkerMock)
+		worker.On("Fetch", []string{pagefetchTestTitle}).Return(map[string]*schema.Page{}, map[string]error{pagefetchTestTitle: fetch.ErrPageRedirect}, nil)
+
+		fact := new(pagefetchWorkerFactoryMock)
+		fact.On("Create").Return(worker)
+		store := new(pagefetchStorageMock)
+
+		repo := new(pagefetchRepoMock)
+		repo.hash = pagefetchTestHash
+		repo.On("Find", &models.Project{}).Return(nil)
+		repo.On("Find", &models.Namespace{}).Return(nil)
+		repo.On("Find", &[]*models.License{}).Return(nil)
+		repo.On("Find", &[]*models.Page{}).Return(nil)
+
+		cache := &pagefetchRedisMock{data: map[string]string{}, zset: map[string]float64{}}
+		prod := new(pagefetchProducerMock)
+
+		assert.NoError(Worker(fact, store, repo, prod, cache)(ctx, data))
+		assert.Len(cache.queue, 1)
+
+		del := new(pagedelete.Data)
+		assert.NoError(json.Unmarshal(cache.queue[0], del))
+		assert.Equal(pagefetchTestTitle, del.Title)
+		assert.Equal(pagefetchTestDbName, del.DbName)
+		assert.True(del.Redirect)
+	})
+}
+
+func TestPagefetchQuarantine(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	key := quarantine.Key(pagefetchTestDbName, pagefetchTestTitle)
+	policy := &quarantine.Policy{
+		Damaging: 0.5,
+		Delay:    60,
+	}
+
+	newData := func(rev int, damaging float64) []byte {
+		data := &Data{
+			Title:     pagefetchTestTitle,
+			Revision:  rev,
+			DbName:    pagefetchTestDbName,
+			Lang:      pagefetchTestLang,
+			Namespace: pagefetchTestNamespace,
+			SiteURL:   pagefetchTestSiteURL,
+			Scores: &schema.Scores{
+				Damaging: new(ores.ScoreDamaging),
+			},
+		}
+		data.Scores.Damaging.Probability.True = damaging
+
+		payload, err := json.Marshal(data)
+		assert.NoError(err)
+
+		return payload
+	}
+
+	newRepo := func() *pagefetchRepoMock {
+		repo := &pagefetchRepoMock{policy: policy}
+		repo.On("Find", mock.Anything).Return(nil)
+		repo.On("Update", mock.Anything).Return(nil)
+
+		return repo
+	}
+
+	t.Run("worker quarantine hold", func(t *testing.T) {
+		cache := &pagefetchRedisMock{data: map[string]string{}, zset: map[string]float64{}}
+		fact := new(pagefetchWorkerFactoryMock)
+
+		fetch := Worker(fact, new(pagefetchStorageMock), newRepo(), new(pagefetchProducerMock), cache)
+		assert.NoError(fetch(ctx, newData(pagefetchTestRevision, 0.9)))
+		fact.AssertNotCalled(t, "Create")
+
+		held := new(Data)
+		assert.NoError(json.Unmarshal([]byte(cache.data[key]), held))
+		assert.True(held.Quarantine.Held)
+		assert.Equal([]string{quarantine.ReasonDamaging}, held.Quarantine.Reasons)
+		assert.Contains(cache.zset, key)
+	})
+
+	t.Run("worker quarantine outdated revision", func(t *testing.T) {
+		cache := &pagefetchRedisMock{data: map[string]string{}, zset: map[string]float64{}}
+		fact := new(pagefetchWorkerFactoryMock)
+		fetch := Worker(fact, new(pagefetchStorageMock), newRepo(), new(pagefetchProducerMock), cache)
+
+		assert.NoError(fetch(ctx, newData(pagefetchTestRevision+1, 0.9)))
+		assert.NoError(fetch(ctx, newData(pagefetchTestRevision, 0.1)))
+		fact.AssertNotCalled(t, "Create")
+		assert.Contains(cache.data, key)
+	})
+
+	t.Run("worker quarantine newer revision", func(t *testing.T) {
+		cache := &pagefetchRedisMock{data: map[string]string{}, zset: map[string]float64{}}
+		pages := map[string]*schema.Page{
+			pagefetchTestTitle: {
+				Name: pagefetchTestTitle,
+				Version: &schema.Version{
+					Identifier: pagefetchTestRevision + 1,
+				},
+			},
+		}
+
+		worker := new(pagefetchWorkerMock)
+		worker.On("Fetch", []string{pagefetchTestTitle}).Return(pages, map[string]error{}, nil)
+
+		fact := new(pagefetchWorkerFactoryMock)
+		fact.On("Create").Return(worker)
+
+		prod := new(pagefetchProducerMock)
+		prod.msgs = make(chan *kafka.Message, 1)
+
+		fetch := Worker(fact, new(pagefetchStorageMock), newRepo(), prod, cache)
+		assert.NoError(fetch(ctx, newData(pagefetchTestRevision, 0.9)))
+		assert.NoError(fetch(ctx, newData(pagefetchTestRevision+1, 0.1)))
+		assert.NotContains(cache.data, key)
+		assert.NotContains(cache.zset, key)
+
+		page := new(schema.Page)
+		assert.NoError(json.Unmarshal((<-prod.msgs).Value, page))
+		assert.False(page.Version.Quarantine.Held)
+	})
+
+	t.Run("worker quarantine changed meanwhile", func(t *testing.T) {
+		cache := &pagefetchRedisMock{
+			data:    map[string]string{},
+			zset:    map[string]float64{},
+			changes: []string{string(newData(pagefetchTestRevision+1, 0.9))},
+		}
+		fact := new(pagefetchWorkerFactoryMock)
+		fetch := Worker(fact, new(pagefetchStorageMock), newRepo(), new(pagefetchProducerMock), cache)
+
+		assert.NoError(fetch(ctx, newData(pagefetchTestRevision, 0.9)))
+		fact.AssertNotCalled(t, "Create")
+
+		held := new(Data)
+		assert.NoError(json.Unmarshal([]byte(cache.data[key]), held))
+		assert.Equal(pagefetchTestRevision+1, held.Revision)
+	})
+
+	t.Run("release", func(t *testing.T) {
+		cache := &pagefetchRedisMock{data: map[string]string{}, zset: map[string]float64{}}
+		fetch := Worker(new(pagefetchWorkerFactoryMock), new(pagefetchStorageMock), newRepo(), new(pagefetchProducerMock), cache)
+		assert.NoError(fetch(ctx, newData(pagefetchTestRevision, 0.9)))
+
+		assert.NoError(Release(ctx, cache))
+		assert.NotContains(cache.data, key)
+		assert.NotContains(cache.zset, key)
+		assert.Len(cache.queue, 1)
+
+		released := new(Data)
+		assert.NoError(json.Unmarshal(cache.queue[0], released))
+		assert.Equal(pagefetchTestRevision, released.Revision)
+		assert.True(released.Quarantine.Held)
+		assert.NotNil(released.Quarantine.DateReleased)
+	})
 }
 
 func TestEnqueue(t *testing.T) {
@@ -1,11 +1,16 @@
 package pagevisibility
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"okapi-data-service/models"
+	"okapi-data-service/pkg/editors"
+	pkgpage "okapi-data-service/pkg/page"
 	"okapi-data-service/pkg/producer"
+	"okapi-data-service/pkg/titlepath"
 	"okapi-data-service/pkg/worker"
 	"okapi-data-service/schema/v3"
 	"time"
@@ -39,9 +44,94 @@ type Data struct {
 	} `json:"visibility"`
 }
 
+// Storage all the needed storages to apply visibility changes
 type Storage interface {
 	storage.Deleter
 	storage.Getter
+	storage.Putter
+}
+
+// Repo all the needed repositories to apply visibility changes
+type Repo interface {
+	repository.Finder
+	repository.Updater
+	repository.SelectOrCreator
+}
+
+// redactStorage delete stored page if the text of the current revision was hidden,
+// or remove hidden editor and comment from it
+func redactStorage(storage Storage, path string, stored []byte, rev int, vis *schema.Visibility) error {
+	if len(stored) == 0 || (vis.Text && vis.User && vis.Comment) {
+		return nil
+	}
+
+	page := new(schema.Page)
+
+	if err := json.Unmarshal(stored, page); err != nil {
+		return err
+	}
+
+	if page.Version == nil || page.Version.Identifier != rev {
+		return nil
+	}
+
+	if !vis.Text {
+		return storage.Delete(path)
+	}
+
+	page.Redact(vis)
+	data, err := json.Marshal(page)
+
+	if err != nil {
+		return err
+	}
+
+	return storage.Put(path, bytes.NewReader(data))
+}
+
+// redactRevision remove hidden editor and comment from the revisions history
+func redactRevision(ctx context.Context, repo Repo, dbName string, rev int, vis *schema.Visibility) error {
+	columns := []string{}
+
+	if !vis.User {
+		columns = append(columns, "editor_id", "editor_name")
+	}
+
+	if !vis.Comment {
+		columns = append(columns, "comment")
+	}
+
+	if len(columns) == 0 {
+		return nil
+	}
+
+	query := func(q *orm.Query) *orm.Query {
+		return q.
+			Column(append(columns, "updated_at")...).
+			Where("db_name = ? and revision = ?", dbName, rev)
+	}
+
+	_, err := repo.Update(ctx, &models.Revision{DbName: dbName, Revision: rev}, query)
+	return err
+}
+
+// recordRedaction remember suppressed revision to remove it from already published archives
+func recordRedaction(ctx context.Context, repo Repo, data *Data, vis *schema.Visibility) error {
+	if vis.Text {
+		return nil
+	}
+
+	red := &models.Redaction{
+		DbName:   data.DbName,
+		Title:    data.Title,
+		Revision: data.Revision,
+	}
+
+	_, err := repo.SelectOrCreate(ctx, red, func(q *orm.Query) *orm.Query {
+		return q.Where("db_name = ? and revision = ?", data.DbName, data.Revision)
+	})
+
+	return err
 }
 
 // Enqueue add data to the worker queue
@@ -50,7 +140,7 @@ func Enqueue(ctx context.Context, store redis.Cmdable, data *Data) error {
 }
 
 // Worker processing function
-func Worker(repo repository.Finder, storage Storage, producer producer.Producer) worker.Worker {
+func Worker(repo Repo, storage Storage, producer producer.Producer, cache redis.Cmdable) worker.Worker {
 	return func(ctx context.Context, payload []byte) error {
 		data := new(Data)
 
@@ -58,18 +148,28 @@ func Worker(repo repository.Finder, storage Storage, producer producer.Producer)
 			return err
 		}
 
-		path := fmt.Sprintf("%s/%s.json", data.DbName, data.Title)
-		page := new(schema.Page)
+		path := fmt.Sprintf("json/%s/%s.json", data.DbName, titlepath.Encode(data.Title))
+		page, stored := new(schema.Page), []byte{}
+		vis := &schema.Visibility{
+			Text:    data.Visibility.Text,
+			User:    data.Visibility.User,
+			Comment: data.Visibility.Comment,
+		}
 
 		if prc, err := storage.Get(path); err == nil {
-			defer prc.Close()
+			stored, err = ioutil.ReadAll(prc)
+			_ = prc.Close()
+
+			if err != nil {
+				return err
+			}
 
-			if err := json.NewDecoder(prc).Decode(page); err != nil {
+			if err := json.Unmarshal(stored, page); err != nil {
 				return err
 			}
 		}
 
-		resps := make(chan error, 2)
+		resps := make(chan error, 3)
 
 		go func() {
 			if len(page.Name) == 0 {
@@ -104,15 +204,11 @@ func Worker(repo repository.Finder, storage Storage, producer producer.Producer)
 					Identifier: data.Revision,
 				}
 
-				license := schema.NewLicense()
+				license, err := pkgpage.License(ctx, repo, proj.DbName, ns.ID)
 
-				// Custom license for wikinews projects.
-				if proj.SiteCode == "wikinews" {
-					license = &schema.License{
-						Name:       "Attribution 2.5 Generic",
-						Identifier: "CC BY 2.5",
-						URL:        "https://creativecommons.org/licenses/by/2.5/",
-					}
+				if err != nil {
+					resps <- err
+					return
 				}
 
 				page.License = append(page.License, license)
@@ -139,12 +235,11 @@ func Worker(repo repository.Finder, storage Storage, producer producer.Producer)
 
 			if page.Version != nil {
 				page.Version.Editor = data.Editor
+				editors.Fill(ctx, cache, data.DbName, page.Version.Editor)
 			}
 
-			page.Visibility = new(schema.Visibility)
-			page.Visibility.Comment = data.Visibility.Comment
-			page.Visibility.Text = data.Visibility.Text
-			page.Visibility.User = data.Visibility.User
+			page.Redact(vis)
+			page.Visibility = vis
 			value, err := json.Marshal(page)
 
 			if err != nil {
@@ -175,16 +270,21 @@ func Worker(repo repository.Finder, storage Storage, producer producer.Producer)
 		}()
 
 		go func() {
-			if page.Version != nil && page.Version.Identifier == data.Revision && (!data.Visibility.Text || !data.Visibility.User || !data.Visibility.Comment) {
-				resps <- storage.Delete(path)
-			} else {
-				resps <- nil
+			resps <- redactStorage(storage, path, stored, data.Revision, vis)
+		}()
+
+		go func() {
+			if err := redactRevision(ctx, repo, data.DbName, data.Revision, vis); err != nil {
+				resps <- err
+				return
 			}
+
+			resps <- recordRedaction(ctx, repo, data, vis)
 		}()
 
 		errs := []error{}
 
-		for i := 0; i < 2; i++ {
+		for i := 0; i < 3; i++ {
 			if err := <-resps; err != nil {
 				errs = append(errs, err)
 			}
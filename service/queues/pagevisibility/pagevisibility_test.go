@@ -8,6 +8,7 @@ import (
 	"io"
 	"io/ioutil"
 	"okapi-data-service/models"
+	"okapi-data-service/pkg/editors"
 	"okapi-data-service/schema/v3"
 	"strings"
 	"testing"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"github.com/go-pg/pg/v10/orm"
+	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -33,6 +35,7 @@ const pagevisibilityTestLang = "en"
 const pagevisibilityTestNsID = 0
 const pagevisibilityTestNsTitle = "Article"
 const pagevisibilityTestLangLocalName = "English"
+const pagevisibilityTestEditorID = 10
 
 const pagevisibilityTestWikitext = "...wikitext goes here..."
 const pagevisibilityTestHTML = "...HTML goes here..."
@@ -41,6 +44,7 @@ var pagevisibilityTestRevDt = time.Now()
 
 type repoMock struct {
 	mock.Mock
+	lics []*models.License
 }
 
 func (r *repoMock) Find(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, _ ...interface{}) error {
@@ -59,11 +63,22 @@ func (r *repoMock) Find(_ context.Context, model interface{}, _ func(*orm.Query)
 	case *models.Namespace:
 		model.ID = pagevisibilityTestNsID
 		model.Title = pagevisibilityTestNsTitle
+	case *[]*models.License:
+		*model = append(*model, r.lics...)
 	}
 
 	return args.Error(0)
 }
 
+func (r *repoMock) SelectOrCreate(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, _ ...interface{}) (bool, error) {
+	args := r.Called(model)
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *repoMock) Update(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, _ ...interface{}) (orm.Result, error) {
+	return nil, r.Called(model).Error(0)
+}
+
 type storageMock struct {
 	mock.Mock
 }
@@ -73,10 +88,33 @@ func (s *storageMock) Get(path string) (io.ReadCloser, error) {
 	return ioutil.NopCloser(strings.NewReader(args.String(0))), args.Error(1)
 }
 
+func (s *storageMock) Put(path string, body io.Reader) error {
+	data, err := ioutil.ReadAll(body)
+
+	if err != nil {
+		return err
+	}
+
+	return s.Called(path, string(data)).Error(0)
+}
+
 func (s *storageMock) Delete(path string) error {
 	return s.Called(path).Error(0)
 }
 
+type cacheMock struct {
+	redis.Cmdable
+	data map[string]string
+}
+
+func (c *cacheMock) Get(_ context.Context, key string) *redis.StringCmd {
+	if val, ok := c.data[key]; ok {
+		return redis.NewStringResult(val, nil)
+	}
+
+	return redis.NewStringResult("", redis.Nil)
+}
+
 type producerMock struct {
 	mock.Mock
 	msgs chan *kafka.Message
@@ -138,7 +176,7 @@ func newData(textVisible, commentVisible, userVisible bool) *Data {
 func TestPagevisibility(t *testing.T) {
 	assert := assert.New(t)
 	ctx := context.Background()
-	path := fmt.Sprintf("%s/%s.json", pagevisibilityTestDbName, pagevisibilityTestTitle)
+	path := fmt.Sprintf("json/%s/%s.json", pagevisibilityTestDbName, pagevisibilityTestTitle)
 
 	t.Run("worker storage success", func(t *testing.T) {
 		page := newPage()
@@ -162,7 +200,15 @@ func TestPagevisibility(t *testing.T) {
 		producer := new(producerMock)
 		producer.msgs = make(chan *kafka.Message, 1)
 
-		assert.NoError(Worker(new(repoMock), store, producer)(ctx, qData))
+		repo := new(repoMock)
+		repo.On("SelectOrCreate", &models.Redaction{
+			DbName:   pagevisibilityTestDbName,
+			Title:    pagevisibilityTestTitle,
+			Revision: pagevisibilityTestRev,
+		}).Return(true, nil)
+
+		assert.NoError(Worker(repo, store, producer, nil)(ctx, qData))
+		repo.AssertNumberOfCalls(t, "SelectOrCreate", 1)
 		msg := <-producer.ProduceChannel()
 
 		expectMsg, err := json.Marshal(page)
@@ -194,7 +240,10 @@ func TestPagevisibility(t *testing.T) {
 		producer := new(producerMock)
 		producer.msgs = make(chan *kafka.Message, 1)
 
-		assert.Equal(errDelete, Worker(new(repoMock), store, producer)(ctx, qData))
+		repo := new(repoMock)
+		repo.On("SelectOrCreate", mock.Anything).Return(true, nil)
+
+		assert.Equal(errDelete, Worker(repo, store, producer, nil)(ctx, qData))
 		msg := <-producer.ProduceChannel()
 
 		expectMsg, err := json.Marshal(page)
@@ -203,6 +252,135 @@ func TestPagevisibility(t *testing.T) {
 		assert.Equal(pagevisibilityTestKey, string(msg.Key))
 	})
 
+	t.Run("worker editor from cache", func(t *testing.T) {
+		pData, err := json.Marshal(newPage())
+		assert.NoError(err)
+
+		data := newData(false, true, true)
+		data.Editor = &schema.Editor{
+			Identifier: pagevisibilityTestEditorID,
+			Name:       "Editor",
+		}
+		qData, err := json.Marshal(data)
+		assert.NoError(err)
+
+		profile, err := json.Marshal(&schema.Editor{
+			Identifier: pagevisibilityTestEditorID,
+			Name:       "Editor",
+			EditCount:  100,
+			Groups:     []string{"sysop"},
+		})
+		assert.NoError(err)
+
+		cache := &cacheMock{data: map[string]string{
+			editors.Key(pagevisibilityTestDbName, pagevisibilityTestEditorID): string(profile),
+		}}
+
+		store := new(storageMock)
+		store.On("Get", path).Return(string(pData), nil)
+		store.On("Delete", path).Return(nil)
+
+		producer := new(producerMock)
+		producer.msgs = make(chan *kafka.Message, 1)
+
+		repo := new(repoMock)
+		repo.On("SelectOrCreate", mock.Anything).Return(true, nil)
+
+		assert.NoError(Worker(repo, store, producer, cache)(ctx, qData))
+		msg := <-producer.ProduceChannel()
+
+		page := new(schema.Page)
+		assert.NoError(json.Unmarshal(msg.Value, page))
+		assert.Equal(100, page.Version.Editor.EditCount)
+		assert.Equal([]string{"sysop"}, page.Version.Editor.Groups)
+	})
+
+	t.Run("worker redact editor and comment", func(t *testing.T) {
+		page := newPage()
+		page.Version.Comment = "comment"
+		page.Version.Editor = &schema.Editor{
+			Identifier: pagevisibilityTestEditorID,
+			Name:       "Editor",
+		}
+		pData, err := json.Marshal(page)
+		assert.NoError(err)
+
+		data := newData(true, false, false)
+		data.Editor = page.Version.Editor
+		qData, err := json.Marshal(data)
+		assert.NoError(err)
+
+		page.Version.Comment = ""
+		page.Version.Editor = nil
+		sData, err := json.Marshal(page)
+		assert.NoError(err)
+
+		store := new(storageMock)
+		store.On("Get", path).Return(string(pData), nil)
+		store.On("Put", path, string(sData)).Return(nil)
+
+		repo := new(repoMock)
+		repo.On("Update", &models.Revision{DbName: pagevisibilityTestDbName, Revision: pagevisibilityTestRev}).Return(nil)
+
+		producer := new(producerMock)
+		producer.msgs = make(chan *kafka.Message, 1)
+
+		assert.NoError(Worker(repo, store, producer, nil)(ctx, qData))
+		msg := <-producer.ProduceChannel()
+
+		evt := new(schema.Page)
+		assert.NoError(json.Unmarshal(msg.Value, evt))
+		assert.Nil(evt.Version.Editor)
+		assert.Empty(evt.Version.Comment)
+		assert.False(evt.Visibility.User)
+		assert.False(evt.Visibility.Comment)
+		store.AssertCalled(t, "Put", path, string(sData))
+		store.AssertNotCalled(t, "Delete", path)
+		repo.AssertNumberOfCalls(t, "Update", 1)
+	})
+
+	t.Run("worker redact revision error", func(t *testing.T) {
+		pData, err := json.Marshal(newPage())
+		assert.NoError(err)
+
+		qData, err := json.Marshal(newData(true, false, true))
+		assert.NoError(err)
+
+		store := new(storageMock)
+		store.On("Get", path).Return(string(pData), nil)
+		store.On("Put", path, mock.Anything).Return(nil)
+
+		errUpdate := errors.New("can't update revision")
+		repo := new(repoMock)
+		repo.On("Update", mock.Anything).Return(errUpdate)
+
+		producer := new(producerMock)
+		producer.msgs = make(chan *kafka.Message, 1)
+
+		assert.Equal(errUpdate, Worker(repo, store, producer, nil)(ctx, qData))
+	})
+
+	t.Run("worker record redaction error", func(t *testing.T) {
+		pData, err := json.Marshal(newPage())
+		assert.NoError(err)
+
+		qData, err := json.Marshal(newData(false, true, true))
+		assert.NoError(err)
+
+		store := new(storageMock)
+		store.On("Get", path).Return(string(pData), nil)
+		store.On("Delete", path).Return(nil)
+
+		errRecord := errors.New("can't record redaction")
+		repo := new(repoMock)
+		repo.On("SelectOrCreate", mock.Anything).Return(false, errRecord)
+
+		producer := new(producerMock)
+		producer.msgs = make(chan *kafka.Message, 1)
+
+		assert.Equal(errRecord, Worker(repo, store, producer, nil)(ctx, qData))
+	})
+
 	t.Run("worker db success", func(t *testing.T) {
 		page := newPage()
 		page.ArticleBody = nil
@@ -221,11 +399,12 @@ func TestPagevisibility(t *testing.T) {
 		repo := new(repoMock)
 		repo.On("Find", new(models.Project)).Return(nil)
 		repo.On("Find", new(models.Namespace)).Return(nil)
+		repo.On("Find", &[]*models.License{}).Return(nil)
 
 		producer := new(producerMock)
 		producer.msgs = make(chan *kafka.Message, 1)
 
-		assert.NoError(Worker(repo, store, producer)(ctx, qData))
+		assert.NoError(Worker(repo, store, producer, nil)(ctx, qData))
 		msg := <-producer.ProduceChannel()
 
 		expectMsg, err := json.Marshal(page)
@@ -235,14 +414,14 @@ func TestPagevisibility(t *testing.T) {
 		assert.Equal(pagevisibilityTestKey, string(msg.Key))
 	})
 
-	t.Run("wikinews license", func(t *testing.T) {
+	t.Run("project license", func(t *testing.T) {
 		pagevisibilityTestDbName = "arwikinews"
 		pagevisibilityTestSiteName = "ويكي_الأخبار"
 		pagevisibilityTestSiteURL = "https://ar.wikinews.org"
 		pagevisibilityTestSiteCode = "wikinews"
 		pagevisibilityTestKey = `{"name":"Earth","is_part_of":"arwikinews"}`
 
-		path = fmt.Sprintf("%s/%s.json", pagevisibilityTestDbName, pagevisibilityTestTitle)
+		path = fmt.Sprintf("json/%s/%s.json", pagevisibilityTestDbName, pagevisibilityTestTitle)
 
 		page := newPage()
 		page.ArticleBody = nil
@@ -255,7 +434,7 @@ func TestPagevisibility(t *testing.T) {
 		page.License = []*schema.License{
 			{
 				Name:       "Attribution 2.5 Generic",
-				Identifier: "CC BY 2.5",
+				Identifier: "CC-BY-2.5",
 				URL:        "https://creativecommons.org/licenses/by/2.5/",
 			},
 		}
@@ -266,15 +445,25 @@ func TestPagevisibility(t *testing.T) {
 		store := new(storageMock)
 		store.On("Get", path).Return(string(""), errors.New("can't find the page"))
 
-		repo := new(repoMock)
+		repo := &repoMock{
+			lics: []*models.License{
+				{
+					DbName:     pagevisibilityTestDbName,
+					Name:       "Attribution 2.5 Generic",
+					Identifier: "CC-BY-2.5",
+					URL:        "https://creativecommons.org/licenses/by/2.5/",
+				},
+			},
+		}
 		model := new(models.Project)
 		repo.On("Find", model).Return(nil)
 		repo.On("Find", new(models.Namespace)).Return(nil)
+		repo.On("Find", &[]*models.License{}).Return(nil)
 
 		producer := new(producerMock)
 		producer.msgs = make(chan *kafka.Message, 1)
 
-		assert.NoError(Worker(repo, store, producer)(ctx, qData))
+		assert.NoError(Worker(repo, store, producer, nil)(ctx, qData))
 		msg := <-producer.ProduceChannel()
 
 		expectMsg, err := json.Marshal(page)
@@ -306,7 +495,7 @@ func TestPagevisibility(t *testing.T) {
 		producer := new(producerMock)
 		producer.msgs = make(chan *kafka.Message, 1)
 
-		assert.Equal(errRepo, Worker(repo, store, producer)(ctx, qData))
+		assert.Equal(errRepo, Worker(repo, store, producer, nil)(ctx, qData))
 	})
 
 	t.Run("worker db namespace error", func(t *testing.T) {
@@ -332,6 +521,6 @@ func TestPagevisibility(t *testing.T) {
 		producer := new(producerMock)
 		producer.msgs = make(chan *kafka.Message, 1)
 
-		assert.Equal(errRepo, Worker(repo, store, producer)(ctx, qData))
+		assert.Equal(errRepo, Worker(repo, store, producer, nil)(ctx, qData))
 	})
 }
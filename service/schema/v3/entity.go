@@ -2,7 +2,10 @@ package schema
 
 // Entity schema for wikidata item
 type Entity struct {
-	Identifier string   `json:"identifier,omitempty"`
-	URL        string   `json:"url,omitempty"`
-	Aspects    []string `json:"aspects,omitempty"`
+	Identifier  string    `json:"identifier,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	Description string    `json:"description,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	Aspects     []string  `json:"aspects,omitempty"`
+	InstanceOf  []*Entity `json:"instance_of,omitempty"`
 }
@@ -0,0 +1,12 @@
+package schema
+
+// File media file metadata for the pages in file namespace
+type File struct {
+	URL      string   `json:"url,omitempty"`
+	MimeType string   `json:"mime_type,omitempty"`
+	Width    int      `json:"width,omitempty"`
+	Height   int      `json:"height,omitempty"`
+	Size     int      `json:"size,omitempty"`
+	Uploader *Editor  `json:"uploader,omitempty"`
+	License  *License `json:"license,omitempty"`
+}
@@ -16,23 +16,29 @@ type ArticleBody struct {
 
 // Page schema
 type Page struct {
-	Name               string        `json:"name"`
-	Identifier         int           `json:"identifier,omitempty"`
-	DateModified       *time.Time    `json:"date_modified,omitempty"`
-	Protection         []*Protection `json:"protection,omitempty"`
-	Version            *Version      `json:"version,omitempty"`
-	URL                string        `json:"url,omitempty"`
-	Namespace          *Namespace    `json:"namespace,omitempty"`
-	InLanguage         *Language     `json:"in_language,omitempty"`
-	MainEntity         *Entity       `json:"main_entity,omitempty"`
-	AdditionalEntities []*Entity     `json:"additional_entities,omitempty"`
-	Categories         []*Page       `json:"categories,omitempty"`
-	Templates          []*Page       `json:"templates,omitempty"`
-	Redirects          []*Page       `json:"redirects,omitempty"`
-	IsPartOf           *Project      `json:"is_part_of,omitempty"`
-	ArticleBody        *ArticleBody  `json:"article_body,omitempty"`
-	License            []*License    `json:"license,omitempty"`
-	Visibility         *Visibility   `json:"visibility,omitempty"`
+	Name               string          `json:"name"`
+	Identifier         int             `json:"identifier,omitempty"`
+	DateModified       *time.Time      `json:"date_modified,omitempty"`
+	Protection         []*Protection   `json:"protection,omitempty"`
+	Version            *Version        `json:"version,omitempty"`
+	LatestVersion      *Version        `json:"latest_version,omitempty"`
+	URL                string          `json:"url,omitempty"`
+	Namespace          *Namespace      `json:"namespace,omitempty"`
+	InLanguage         *Language       `json:"in_language,omitempty"`
+	MainEntity         *Entity         `json:"main_entity,omitempty"`
+	AdditionalEntities []*Entity       `json:"additional_entities,omitempty"`
+	Categories         []*Page         `json:"categories,omitempty"`
+	Templates          []*Page         `json:"templates,omitempty"`
+	TemplateData       []*TemplateData `json:"template_data,omitempty"`
+	Redirects          []*Page         `json:"redirects,omitempty"`
+	LangLinks          []*Page         `json:"lang_links,omitempty"`
+	Images             []*Page         `json:"images,omitempty"`
+	File               *File           `json:"file,omitempty"`
+	IsPartOf           *Project        `json:"is_part_of,omitempty"`
+	ArticleBody        *ArticleBody    `json:"article_body,omitempty"`
+	License            []*License      `json:"license,omitempty"`
+	Visibility         *Visibility     `json:"visibility,omitempty"`
+	ContentHash        string          `json:"content_hash,omitempty"`
 }
 
 // SetHTML set html body
@@ -44,3 +50,22 @@ func (p *Page) SetHTML(html string) {
 func (p *Page) SetWikitext(wikitext string) {
 	p.ArticleBody.Wikitext = wikitext
 }
+
+// Redact remove parts of the page hidden by the visibility flags
+func (p *Page) Redact(vis *Visibility) {
+	if vis == nil {
+		return
+	}
+
+	if !vis.Text {
+		p.ArticleBody = nil
+	}
+
+	if p.Version != nil && !vis.User {
+		p.Version.Editor = nil
+	}
+
+	if p.Version != nil && !vis.Comment {
+		p.Version.Comment = ""
+	}
+}
@@ -0,0 +1,7 @@
+package schema
+
+// Snapshot dated copy of the project export that diffs are applied to
+type Snapshot struct {
+	Date    string `json:"date"`
+	Version string `json:"version"`
+}
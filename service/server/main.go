@@ -8,6 +8,7 @@ import (
 	"okapi-data-service/lib/elastic"
 	"okapi-data-service/lib/env"
 	"okapi-data-service/lib/pg"
+	"okapi-data-service/lib/redis"
 	"okapi-data-service/server/namespaces"
 	"okapi-data-service/server/pages"
 	"okapi-data-service/server/projects"
@@ -43,6 +44,7 @@ func main() {
 		elastic.Init,
 		aws.Init,
 		pg.Init,
+		redis.Init,
 	}
 
 	for _, init := range setup {
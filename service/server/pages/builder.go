@@ -1,7 +1,10 @@
 package pages
 
 import (
+	"okapi-data-service/pkg/multipart"
+
 	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/go-redis/redis/v8"
 	"github.com/protsack-stephan/dev-toolkit/pkg/repository"
 	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
 	dumps "github.com/protsack-stephan/mediawiki-dumps-client"
@@ -20,7 +23,7 @@ func NewBuilder() *Builder {
 }
 
 // JSONStorage set new json storage for the server
-func (bu *Builder) JSONStorage(store storage.Storage) *Builder {
+func (bu *Builder) JSONStorage(store PageStorage) *Builder {
 	bu.srv.jsonStore = store
 	return bu
 }
@@ -31,6 +34,12 @@ func (bu *Builder) GenStorage(store storage.Storage) *Builder {
 	return bu
 }
 
+// MultipartStorage set new remote storage for streamed multipart uploads
+func (bu *Builder) MultipartStorage(up multipart.Uploader) *Builder {
+	bu.srv.multipart = up
+	return bu
+}
+
 // RemoteStorage set new remote storage
 func (bu *Builder) RemoteStorage(store storage.Storage) *Builder {
 	bu.srv.remoteStore = store
@@ -55,6 +64,12 @@ func (bu *Builder) Elastic(elastic *elasticsearch.Client) *Builder {
 	return bu
 }
 
+// Cache set redis cache client
+func (bu *Builder) Cache(cache redis.Cmdable) *Builder {
+	bu.srv.cache = cache
+	return bu
+}
+
 // Build create new server instance with custom params
 func (bu *Builder) Build() *Server {
 	return bu.srv
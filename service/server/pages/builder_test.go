@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/go-redis/redis/v8"
 	"github.com/protsack-stephan/dev-toolkit/pkg/repository"
 	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
 	dumps "github.com/protsack-stephan/mediawiki-dumps-client"
@@ -16,6 +17,7 @@ var builderTestGenStore = new(storage.Mock)
 var builderTestRepo = new(repository.Mock)
 var builderTestDumps = new(dumps.Client)
 var builderTestElastic = new(elasticsearch.Client)
+var builderTestCache = new(redis.Client)
 
 func TestBuilder(t *testing.T) {
 	client := NewBuilder().
@@ -25,6 +27,7 @@ func TestBuilder(t *testing.T) {
 		Repository(builderTestRepo).
 		Dumps(builderTestDumps).
 		Elastic(builderTestElastic).
+		Cache(builderTestCache).
 		Build()
 
 	assert := assert.New(t)
@@ -34,4 +37,5 @@ func TestBuilder(t *testing.T) {
 	assert.Equal(builderTestRepo, client.repo)
 	assert.Equal(builderTestDumps, client.dumps)
 	assert.Equal(builderTestElastic, client.elastic)
+	assert.Equal(builderTestCache, client.cache)
 }
@@ -15,6 +15,8 @@ const copyNumWorkers = 10
 // e.g., export/enwiki/enwiki_14.json -> export/enwiki/enwiki_group_1_14.json
 // export/enwiki/enwiki_json_0.tar.gz -> export/enwiki/enwiki_group_1_json_0.tar.gz
 // public/exports_0.json -> public/exports_group_1_0.json
+// with parquet format export/enwiki/enwiki_parquet_0.parquet -> export/enwiki/enwiki_group_1_parquet_0.parquet
+// with metadata profile export/enwiki/enwiki_json_metadata_0.tar.gz -> export/enwiki/enwiki_group_1_json_metadata_0.tar.gz
 func Copy(ctx context.Context, req *pb.CopyRequest, store storage.CopierWithContext, suffix string) (*pb.CopyResponse, error) {
 	if req.Workers == 0 {
 		req.Workers = copyNumWorkers
@@ -24,6 +26,18 @@ func Copy(ctx context.Context, req *pb.CopyRequest, store storage.CopierWithCont
 	paths := make(map[string]string) // Store source-destination path pairs to copy
 
 	for _, db := range req.DbNames {
+		if req.Format == ExportFormatParquet {
+			paths[fmt.Sprintf("export/%s/%s_parquet_%d.json", db, db, req.Ns)] = fmt.Sprintf("export/%s/%s%s_parquet_%d.json", db, db, suffix, req.Ns)
+			paths[fmt.Sprintf("export/%s/%s_parquet_%d.parquet", db, db, req.Ns)] = fmt.Sprintf("export/%s/%s%s_parquet_%d.parquet", db, db, suffix, req.Ns)
+			continue
+		}
+
+		if len(req.Profile) > 0 && req.Profile != ExportProfileFull {
+			paths[fmt.Sprintf("export/%s/%s_%s_%d.json", db, db, req.Profile, req.Ns)] = fmt.Sprintf("export/%s/%s%s_%s_%d.json", db, db, suffix, req.Profile, req.Ns)
+			paths[fmt.Sprintf("export/%s/%s_json_%s_%d.tar.gz", db, db, req.Profile, req.Ns)] = fmt.Sprintf("export/%s/%s%s_json_%s_%d.tar.gz", db, db, suffix, req.Profile, req.Ns)
+			continue
+		}
+
 		paths[fmt.Sprintf("export/%s/%s_%d.json", db, db, req.Ns)] = fmt.Sprintf("export/%s/%s%s_%d.json", db, db, suffix, req.Ns)
 		paths[fmt.Sprintf("export/%s/%s_json_%d.tar.gz", db, db, req.Ns)] = fmt.Sprintf("export/%s/%s%s_json_%d.tar.gz", db, db, suffix, req.Ns)
 	}
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	pb "okapi-data-service/server/pages/protos"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -69,3 +70,60 @@ func TestCopyError(t *testing.T) {
 
 	remote.AssertNumberOfCalls(t, "CopyWithContext", 4) // Copied project tar + project metadata + global metadata
 }
+
+// copyPathsMock records copied paths.
+type copyPathsMock struct {
+	mu    sync.Mutex
+	paths map[string]string
+}
+
+// CopyWithContext records source and destination of the copy.
+func (s *copyPathsMock) CopyWithContext(_ context.Context, src string, dst string, options ...map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paths[src] = dst
+	return nil
+}
+
+// TestCopyParquet verifies that parquet format copies parquet export and its metadata.
+func TestCopyParquet(t *testing.T) {
+	ctx := context.Background()
+	req := new(pb.CopyRequest)
+	req.Ns = int32(ns)
+	req.Workers = workers
+	req.DbNames = dbs[:1]
+	req.Format = ExportFormatParquet
+
+	remote := &copyPathsMock{paths: map[string]string{}}
+
+	res, err := Copy(ctx, req, remote, dstFileSuffix)
+	assert := assert.New(t)
+	assert.NoError(err)
+	assert.Equal(2, int(res.Total))
+	assert.Zero(res.Errors)
+	assert.Equal(map[string]string{
+		"export/enwiki/enwiki_parquet_0.parquet": "export/enwiki/enwiki_group_1_parquet_0.parquet",
+		"export/enwiki/enwiki_parquet_0.json":    "export/enwiki/enwiki_group_1_parquet_0.json",
+	}, remote.paths)
+}
+
+func TestCopyProfile(t *testing.T) {
+	ctx := context.Background()
+	req := new(pb.CopyRequest)
+	req.Ns = int32(ns)
+	req.Workers = workers
+	req.DbNames = dbs[:1]
+	req.Profile = ExportProfileMetadata
+
+	remote := &copyPathsMock{paths: map[string]string{}}
+
+	res, err := Copy(ctx, req, remote, dstFileSuffix)
+	assert := assert.New(t)
+	assert.NoError(err)
+	assert.Equal(2, int(res.Total))
+	assert.Zero(res.Errors)
+	assert.Equal(map[string]string{
+		"export/enwiki/enwiki_json_metadata_0.tar.gz": "export/enwiki/enwiki_group_1_json_metadata_0.tar.gz",
+		"export/enwiki/enwiki_metadata_0.json":        "export/enwiki/enwiki_group_1_metadata_0.json",
+	}, remote.paths)
+}
@@ -0,0 +1,188 @@
+package pages
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
+	dumps "github.com/protsack-stephan/mediawiki-dumps-client"
+)
+
+// dumpDateFormat format of the dump date in the fetch request
+const dumpDateFormat = "2006-01-02"
+
+// dumpRemotePrefix prefix of the dump paths that have to be read from the remote storage
+const dumpRemotePrefix = "s3://"
+
+// ErrDumpStorageIsNil dump path was provided but there is no storage to read it from
+var ErrDumpStorageIsNil = errors.New("dump storage is nil")
+
+// ErrDumpPathInvalid dump path tries to leave the dump volume
+var ErrDumpPathInvalid = errors.New("dump path can't contain '..'")
+
+// dumpRowExpr matches the page_id, page_namespace and page_title columns of the page table insert tuples
+var dumpRowExpr = regexp.MustCompile(`\((\d+),(-?\d+),'((?:[^'\\]|\\.)*)'`)
+
+// dumpUnescape reverts mysqldump string escaping
+var dumpUnescape = strings.NewReplacer(`\'`, `'`, `\"`, `"`, `\\`, `\`)
+
+// DumpStorage storages to read local or mirrored dump files from
+type DumpStorage struct {
+	Local  storage.Getter
+	Remote storage.Getter
+}
+
+// Titles read page titles from the dump file, paths starting with "s3://" are read from the remote storage
+// and the rest from the local dump volume
+func (ds *DumpStorage) Titles(ctx context.Context, path string, prefixes map[int]string, cb func(*dumps.Page)) error {
+	if ds == nil {
+		return ErrDumpStorageIsNil
+	}
+
+	for _, part := range strings.Split(path, "/") {
+		if part == ".." {
+			return ErrDumpPathInvalid
+		}
+	}
+
+	from, key := ds.Local, strings.TrimPrefix(path, "/")
+
+	if strings.HasPrefix(path, dumpRemotePrefix) {
+		from, key = ds.Remote, strings.TrimPrefix(path, dumpRemotePrefix)
+	}
+
+	if from == nil {
+		return ErrDumpStorageIsNil
+	}
+
+	file, err := from.Get(key)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	var rdr io.Reader = file
+
+	if strings.HasSuffix(path, ".gz") {
+		gzr, err := gzip.NewReader(file)
+
+		if err != nil {
+			return err
+		}
+
+		defer gzr.Close()
+		rdr = gzr
+	}
+
+	if strings.Contains(path, ".sql") {
+		return dumpTable(ctx, rdr, prefixes, cb)
+	}
+
+	return dumpTitles(ctx, rdr, prefixes, cb)
+}
+
+// dumpTitles parse all-titles dump, lines contain either a title or namespace id and a title
+func dumpTitles(ctx context.Context, rdr io.Reader, prefixes map[int]string, cb func(*dumps.Page)) error {
+	scn := bufio.NewScanner(rdr)
+
+	for scn.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		fields := strings.Fields(scn.Text())
+
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "page_") {
+			continue
+		}
+
+		if len(fields) == 1 {
+			cb(&dumps.Page{Title: fields[0]})
+			continue
+		}
+
+		ns, err := strconv.Atoi(fields[0])
+
+		if err != nil {
+			return err
+		}
+
+		cb(&dumps.Page{Title: dumpTitle(prefixes, ns, fields[1]), Ns: ns})
+	}
+
+	return scn.Err()
+}
+
+// dumpTable parse page table sql dump, insert statements can be megabytes long so lines are read whole
+func dumpTable(ctx context.Context, rdr io.Reader, prefixes map[int]string, cb func(*dumps.Page)) error {
+	brd := bufio.NewReader(rdr)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line, err := brd.ReadString('\n')
+
+		if strings.HasPrefix(line, "INSERT INTO") {
+			for _, match := range dumpRowExpr.FindAllStringSubmatch(line, -1) {
+				ns, err := strconv.Atoi(match[2])
+
+				if err != nil {
+					return err
+				}
+
+				cb(&dumps.Page{Title: dumpTitle(prefixes, ns, dumpUnescape.Replace(match[3])), Ns: ns})
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// dumpTitle add namespace prefix to the title the same way dumps client does
+func dumpTitle(prefixes map[int]string, ns int, title string) string {
+	if prefix, ok := prefixes[ns]; ok && ns != 0 && len(prefix) > 0 {
+		return strings.ReplaceAll(prefix, " ", "_") + ":" + title
+	}
+
+	return title
+}
+
+// dumpDate resolve the date of the dump to fetch titles from, ns 0 titles are published daily and the rest twice a month
+func dumpDate(req string, ns int32, now time.Time) ([]time.Time, error) {
+	if len(req) > 0 {
+		date, err := time.Parse(dumpDateFormat, req)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []time.Time{date}, nil
+	}
+
+	if ns == 0 {
+		return []time.Time{now, now.Add(-24 * time.Hour)}, nil
+	}
+
+	if now.Day() > 20 {
+		return []time.Time{time.Date(now.Year(), now.Month(), 20, 0, 0, 0, 0, time.UTC)}, nil
+	}
+
+	return []time.Time{time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)}, nil
+}
@@ -0,0 +1,92 @@
+package pages
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/protsack-stephan/dev-toolkit/lib/fs"
+	dumps "github.com/protsack-stephan/mediawiki-dumps-client"
+	"github.com/stretchr/testify/assert"
+)
+
+const dumpTestTitles = `page_namespace	page_title
+0	Earth
+0	Moon
+14	Planets
+`
+
+const dumpTestTable = `-- MySQL dump
+INSERT INTO ` + "`page`" + ` VALUES (1,0,'Earth','',0,0,0.1,'20210101000000',NULL,1,10,'wikitext',NULL),(2,14,'Planets','',0,0,0.2,'20210101000000',NULL,2,20,'wikitext',NULL),(3,0,'Rock_\'n\'_roll','',0,0,0.3,'20210101000000',NULL,3,30,'wikitext',NULL);
+`
+
+func TestDumpTitles(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	prefixes := map[int]string{14: "Category"}
+
+	t.Run("all titles", func(t *testing.T) {
+		pages := []*dumps.Page{}
+
+		assert.NoError(dumpTitles(ctx, strings.NewReader(dumpTestTitles), prefixes, func(p *dumps.Page) {
+			pages = append(pages, p)
+		}))
+		assert.Equal([]*dumps.Page{
+			{Title: "Earth"},
+			{Title: "Moon"},
+			{Title: "Category:Planets", Ns: 14},
+		}, pages)
+	})
+
+	t.Run("page table", func(t *testing.T) {
+		pages := []*dumps.Page{}
+
+		assert.NoError(dumpTable(ctx, strings.NewReader(dumpTestTable), prefixes, func(p *dumps.Page) {
+			pages = append(pages, p)
+		}))
+		assert.Equal([]*dumps.Page{
+			{Title: "Earth"},
+			{Title: "Category:Planets", Ns: 14},
+			{Title: "Rock_'n'_roll"},
+		}, pages)
+	})
+
+	t.Run("storage is nil", func(t *testing.T) {
+		var store *DumpStorage
+		assert.Equal(ErrDumpStorageIsNil, store.Titles(ctx, "s3://dumps/titles.gz", prefixes, func(*dumps.Page) {}))
+	})
+
+	t.Run("local storage is not configured", func(t *testing.T) {
+		store := &DumpStorage{Remote: fs.NewStorage(t.TempDir())}
+		assert.Equal(ErrDumpStorageIsNil, store.Titles(ctx, "/etc/passwd", prefixes, func(*dumps.Page) {}))
+	})
+
+	t.Run("path leaves the volume", func(t *testing.T) {
+		store := &DumpStorage{Local: fs.NewStorage(t.TempDir())}
+
+		for _, path := range []string{"../../etc/passwd", "titles/../../secret.gz", "s3://dumps/../export/enwiki.json"} {
+			assert.Equal(ErrDumpPathInvalid, store.Titles(ctx, path, prefixes, func(*dumps.Page) {}))
+		}
+	})
+}
+
+func TestDumpDate(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2021, 3, 25, 10, 0, 0, 0, time.UTC)
+
+	dates, err := dumpDate("", 0, now)
+	assert.NoError(err)
+	assert.Equal([]time.Time{now, now.Add(-24 * time.Hour)}, dates)
+
+	dates, err = dumpDate("", 14, now)
+	assert.NoError(err)
+	assert.Equal([]time.Time{time.Date(2021, 3, 20, 0, 0, 0, 0, time.UTC)}, dates)
+
+	dates, err = dumpDate("2021-02-01", 14, now)
+	assert.NoError(err)
+	assert.Equal([]time.Time{time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)}, dates)
+
+	_, err = dumpDate("20210201", 0, now)
+	assert.Error(err)
+}
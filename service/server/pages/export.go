@@ -8,15 +8,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
 	"log"
 	"math"
 	"okapi-data-service/models"
+	"okapi-data-service/pkg/checksum"
+	"okapi-data-service/pkg/compress"
+	"okapi-data-service/pkg/multipart"
+	"okapi-data-service/pkg/titlepath"
 	"okapi-data-service/schema/v3"
 	pb "okapi-data-service/server/pages/protos"
 	"os"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -31,6 +38,13 @@ import (
 // ExportMaxFileSize max file size for single export ndjson file
 const ExportMaxFileSize = 1000000000 * 10
 
+// exportFileMode file mode of the files inside export archive
+const exportFileMode = 0644
+
+// exportModTime modification time of the files inside export archive,
+// fixed so that archive bytes (and version in the meta) only change with the data
+var exportModTime = time.Unix(0, 0).UTC()
+
 // ErrExportFileIsNil signal that export returned nil (for stubs and new interfaces)
 var ErrExportFileIsNil = errors.New("io.ReadWriteCloser is equal to nil")
 
@@ -42,6 +56,7 @@ type ExportStorage struct {
 	Loc      string // path to the files directory (where to get data from)
 	Dest     string // path to the destination directory (where to put the dump)
 	MetaDest string // path to the meta destination directory (where to put the dump meta)
+	Format   string // encoding format of the dump noted in the meta
 	To       interface {
 		storage.Getter
 		storage.Stater
@@ -52,7 +67,9 @@ type ExportStorage struct {
 		storage.Getter
 		storage.Walker
 	}
-	Remote storage.Putter
+	Remote    storage.Putter
+	Multipart multipart.Uploader // remote storage to stream the archive into, used when request asks for streaming
+	Tracker   multipart.Tracker  // storage for the progress of streamed uploads
 }
 
 type exportRepo interface {
@@ -86,8 +103,14 @@ func newTmpFile(rc io.ReadCloser) (*tmpFile, error) {
 
 // Export generate new export file and upload it to the storage
 func Export(ctx context.Context, req *pb.ExportRequest, repo exportRepo, store *ExportStorage) (*pb.ExportResponse, error) {
+	profile, err := getExportProfile(req.Profile)
+
+	if err != nil {
+		return nil, err
+	}
+
 	proj := new(models.Project)
-	err := repo.Find(ctx, proj, func(q *orm.Query) *orm.Query {
+	err = repo.Find(ctx, proj, func(q *orm.Query) *orm.Query {
 		return q.
 			Where("db_name = ?", req.DbName)
 	})
@@ -96,22 +119,18 @@ func Export(ctx context.Context, req *pb.ExportRequest, repo exportRepo, store *
 		return nil, err
 	}
 
-	file, err := store.To.Create(store.Dest)
+	file, err := newExportArchive(req, store)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if file == nil {
-		return nil, ErrExportFileIsNil
-	}
-
 	res := new(pb.ExportResponse)
-	writeWg, readWg, copyWg := new(sync.WaitGroup), new(sync.WaitGroup), new(sync.WaitGroup)
+	writeWg, copyWg := new(sync.WaitGroup), new(sync.WaitGroup)
 	files := make(chan []byte, int(req.Workers))
-	paths := make(chan string, int(req.Workers))
 	ndfiles := make(chan ndFile, int(req.Workers))
 	failed := new(sync.Map)
+	chunks := []*schema.Chunk{}
 	gzip := pgzip.NewWriter(file)
 
 	if err := gzip.SetConcurrency(1<<20, runtime.NumCPU()*2); err != nil {
@@ -120,42 +139,6 @@ func Export(ctx context.Context, req *pb.ExportRequest, repo exportRepo, store *
 
 	tarbal := tar.NewWriter(gzip)
 
-	// spin up workers that will be responsible for reading files from the filesystem
-	readWg.Add(int(req.Workers))
-	for i := 1; i <= int(req.Workers); i++ {
-		go func() {
-			defer readWg.Done()
-			for path := range paths {
-				file, err := store.From.Get(path)
-
-				if err != nil {
-					log.Println(err)
-					continue
-				}
-
-				data, err := ioutil.ReadAll(file)
-				_ = file.Close()
-
-				if err != nil {
-					log.Printf("path: %s, err: %v", path, err)
-					continue
-				}
-
-				page := new(schema.Page)
-
-				if err := json.Unmarshal(data, page); err != nil {
-					log.Printf("path: %s, err: %v", path, err)
-					failed.Store(path[len(store.Loc)+1:len(path)-len(".json")], struct{}{})
-					continue
-				}
-
-				if page.Namespace.Identifier == int(req.Ns) {
-					files <- data
-				}
-			}
-		}()
-	}
-
 	// create sepperate worker that will get ndjson file and copy them to the tar.gz file
 	copyWg.Add(1)
 	go func() {
@@ -179,16 +162,27 @@ func Export(ctx context.Context, req *pb.ExportRequest, repo exportRepo, store *
 			header := &tar.Header{
 				Name:    file.name,
 				Size:    finfo.Size(),
-				Mode:    0766,
-				ModTime: time.Now().UTC(),
+				Mode:    exportFileMode,
+				ModTime: exportModTime,
 			}
 
+			sum := checksum.NewWriter()
+
 			if err := tarbal.WriteHeader(header); err != nil {
 				log.Println(err)
-			} else if _, err = io.Copy(tarbal, copyf); err != nil {
+			} else if _, err = io.Copy(io.MultiWriter(tarbal, sum), copyf); err != nil {
 				log.Println(err)
+			} else {
+				chunks = append(chunks, &schema.Chunk{
+					Name:   file.name,
+					SHA256: sum.Sum(),
+					Size:   sum.Size(),
+					Lines:  sum.Lines(),
+				})
 			}
 
+			_ = copyf.Close()
+
 			if err := store.To.Delete(file.path); err != nil {
 				log.Println(err)
 			}
@@ -264,54 +258,179 @@ func Export(ctx context.Context, req *pb.ExportRequest, repo exportRepo, store *
 		}
 	}()
 
-	err = store.From.Walk(store.Loc, func(path string) {
-		paths <- path
+	paths, err := exportWalk(store, req.DbName)
+
+	// files are read concurrently but passed to the writer in title order, so that unchanged project gives the same archive
+	exportOrdered(paths, int(req.Workers), func(path string) interface{} {
+		data, err := exportRead(store, path)
+
+		if err != nil {
+			log.Printf("path: %s, err: %v", path, err)
+			return nil
+		}
+
+		page := new(schema.Page)
+
+		if err := json.Unmarshal(data, page); err != nil {
+			log.Printf("path: %s, err: %v", path, err)
+			failed.Store(pathTitle(req.DbName, path), struct{}{})
+			return nil
+		}
+
+		if page.Namespace == nil || page.Namespace.Identifier != int(req.Ns) {
+			return nil
+		}
+
+		if data, err = profile.project(data); err != nil {
+			log.Printf("path: %s, err: %v", path, err)
+			return nil
+		}
+
+		return data
+	}, func(data interface{}) {
+		files <- data.([]byte)
 	})
 
-	close(paths)
-	readWg.Wait()
 	close(files)
 	writeWg.Wait()
 	close(ndfiles)
 	copyWg.Wait()
-	_ = tarbal.Close()
-	_ = gzip.Close()
-	_ = file.Close()
+	if cerr := tarbal.Close(); err == nil {
+		err = cerr
+	}
 
-	if err != nil {
+	if cerr := gzip.Close(); err == nil {
+		err = cerr
+	}
+
+	if err := file.publish(proj, store, err, res.Total, chunks); err != nil {
 		return nil, err
 	}
 
-	if res.Total == 0 {
-		return res, nil
+	titles := []string{}
+
+	failed.Range(func(key, value interface{}) bool {
+		switch title := key.(type) {
+		case string:
+			titles = append(titles, title)
+		}
+
+		return true
+	})
+
+	if len(titles) > 0 {
+		query := func(q *orm.Query) *orm.Query {
+			return q.Set("failed = true").Where("db_name = ? and title in (?)", req.DbName, pg.Strings(titles))
+		}
+
+		if _, err := repo.Update(ctx, new(models.Page), query); err != nil {
+			log.Printf("titles: %v, err: %v", titles, err)
+		}
 	}
 
-	export, err := store.To.Get(store.Dest)
+	return res, nil
+}
+
+// exportArchive destination of the export, either local file uploaded once it's written
+// or multipart upload the archive is streamed into (hashed on the way for the metadata)
+type exportArchive struct {
+	file   io.WriteCloser
+	stream *multipart.Writer
+	md5    hash.Hash
+	sum    *checksum.Writer
+}
+
+// newExportArchive open multipart upload when request asks for streaming and storage supports it, local file otherwise
+func newExportArchive(req *pb.ExportRequest, store *ExportStorage) (*exportArchive, error) {
+	if req.Stream && store.Multipart != nil {
+		stream, err := multipart.NewWriter(store.Multipart, store.Tracker, store.Dest)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &exportArchive{stream: stream, md5: md5.New(), sum: checksum.NewWriter()}, nil // #nosec G401
+	}
+
+	file, err := store.To.Create(store.Dest)
 
 	if err != nil {
 		return nil, err
 	}
 
-	defer export.Close()
+	if file == nil {
+		return nil, ErrExportFileIsNil
+	}
 
-	if err := store.Remote.Put(store.Dest, export); err != nil {
-		return nil, err
+	return &exportArchive{file: file}, nil
+}
+
+// Write add data to the archive
+func (a *exportArchive) Write(p []byte) (int, error) {
+	if a.stream == nil {
+		return a.file.Write(p)
 	}
 
-	info, err := store.To.Stat(store.Dest)
+	_, _ = a.md5.Write(p)
+	_, _ = a.sum.Write(p)
+	return a.stream.Write(p)
+}
+
+// publish finish the archive and upload its metadata, werr is the error of writing the archive
+// (unfinished stream is kept, so the next run of the same export can resume it)
+func (a *exportArchive) publish(proj *models.Project, store *ExportStorage, werr error, total int32, chunks []*schema.Chunk) error {
+	if a.stream == nil {
+		_ = a.file.Close()
+
+		if werr != nil || total == 0 {
+			return werr
+		}
+
+		return exportPublish(proj, store, chunks)
+	}
+
+	if werr != nil {
+		return werr
+	}
+
+	if total == 0 {
+		return a.stream.Abort()
+	}
+
+	if err := a.stream.Close(); err != nil {
+		return err
+	}
+
+	if reused := a.stream.Reused(); reused > 0 {
+		log.Printf("path: %s, reused parts: %d", store.Dest, reused)
+	}
+
+	return exportMeta(proj, store, fmt.Sprintf("%x", a.md5.Sum(nil)), a.sum, chunks)
+}
+
+// exportPublish upload generated export file and its metadata to the remote storage
+func exportPublish(proj *models.Project, store *ExportStorage, chunks []*schema.Chunk) error {
+	export, err := store.To.Get(store.Dest)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// generate body md5 hash
+	defer export.Close()
+
+	// generate body md5 hash and sha256 for the manifest while uploading
 	h := md5.New() // #nosec G401
-	if _, err = io.Copy(h, export); err != nil {
-		return nil, err
+	sum := checksum.NewWriter()
+	if err := store.Remote.Put(store.Dest, io.TeeReader(export, io.MultiWriter(h, sum))); err != nil {
+		return err
 	}
 
-	size := math.Round((((float64)(info.Size())/1024)/1024)*100) / 100
-	version := fmt.Sprintf("%x", h.Sum(nil))
+	return exportMeta(proj, store, fmt.Sprintf("%x", h.Sum(nil)), sum, chunks)
+}
+
+// exportMeta upload metadata of the export with the manifest of the archive and its chunks
+func exportMeta(proj *models.Project, store *ExportStorage, version string, sum *checksum.Writer, chunks []*schema.Chunk) error {
+	size := math.Round((((float64)(sum.Size())/1024)/1024)*100) / 100
 	datetime := time.Now().UTC()
 	meta := schema.Project{
 		Name:         proj.SiteName,
@@ -323,6 +442,11 @@ func Export(ctx context.Context, req *pb.ExportRequest, repo exportRepo, store *
 			Value:    size,
 			UnitText: "MB",
 		},
+		EncodingFormat: store.Format,
+		Manifest: &schema.Manifest{
+			SHA256: sum.Sum(),
+			Chunks: chunks,
+		},
 	}
 
 	if proj.Language != nil {
@@ -335,33 +459,112 @@ func Export(ctx context.Context, req *pb.ExportRequest, repo exportRepo, store *
 	metadata, err := json.Marshal(meta)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if err := store.Remote.Put(store.MetaDest, bytes.NewReader(metadata)); err != nil {
-		return nil, err
+	return store.Remote.Put(store.MetaDest, bytes.NewReader(metadata))
+}
+
+// exportJob page file handed to the read workers, result is sent back on its own channel to keep the order
+type exportJob struct {
+	path string
+	res  chan interface{}
+}
+
+// exportWalk list page files of the export location sorted by title
+func exportWalk(store *ExportStorage, dbName string) ([]string, error) {
+	type item struct {
+		path  string
+		title string
 	}
 
-	titles := []string{}
+	items := []item{}
+	err := store.From.Walk(store.Loc, func(path string) {
+		items = append(items, item{path, pathTitle(dbName, path)})
+	})
 
-	failed.Range(func(key, value interface{}) bool {
-		switch title := key.(type) {
-		case string:
-			titles = append(titles, title)
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].title == items[j].title {
+			return items[i].path < items[j].path
 		}
 
-		return true
+		return items[i].title < items[j].title
 	})
 
-	if len(titles) > 0 {
-		query := func(q *orm.Query) *orm.Query {
-			return q.Set("failed = true").Where("db_name = ? and title in (?)", req.DbName, pg.Strings(titles))
-		}
+	paths := make([]string, 0, len(items))
 
-		if _, err := repo.Update(ctx, new(models.Page), query); err != nil {
-			log.Printf("titles: %v, err: %v", titles, err)
+	for _, item := range items {
+		paths = append(paths, item.path)
+	}
+
+	return paths, err
+}
+
+// exportOrdered process paths with concurrent workers and pass non nil results to out in the order of paths
+func exportOrdered(paths []string, workers int, work func(path string) interface{}, out func(interface{})) {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	jobs := make(chan *exportJob, workers)
+	queue := make(chan *exportJob, workers*2)
+	done := make(chan struct{})
+	wg := new(sync.WaitGroup)
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.res <- work(job.path)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(done)
+		for job := range queue {
+			if res := <-job.res; res != nil {
+				out(res)
+			}
 		}
+	}()
+
+	for _, path := range paths {
+		job := &exportJob{path, make(chan interface{}, 1)}
+		queue <- job
+		jobs <- job
 	}
 
-	return res, nil
+	close(jobs)
+	close(queue)
+	wg.Wait()
+	<-done
+}
+
+// exportRead get page file contents
+func exportRead(store *ExportStorage, path string) ([]byte, error) {
+	file, err := store.From.Get(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if file, err = compress.NewReader(file); err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+	return ioutil.ReadAll(file)
+}
+
+// pathTitle get page title out of the json file path
+func pathTitle(dbName string, path string) string {
+	name := strings.TrimSuffix(strings.TrimPrefix(path, fmt.Sprintf("json/%s/", dbName)), ".json")
+
+	if title, err := titlepath.Decode(name); err == nil {
+		return title
+	}
+
+	return name
 }
@@ -1,21 +1,29 @@
 package pages
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/md5" // #nosec G501
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"okapi-data-service/schema/v3"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"io"
 	"okapi-data-service/models"
+	"okapi-data-service/pkg/multipart"
 	pb "okapi-data-service/server/pages/protos"
 	"testing"
 
 	"github.com/go-pg/pg/v10/orm"
+	"github.com/protsack-stephan/dev-toolkit/lib/fs"
 	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -207,7 +215,7 @@ func TestExport(t *testing.T) {
 	to.On("Delete", exportTestNdFile).Return(nil)
 
 	remote := new(exportRemoteStorageMock)
-	remote.On("Put", exportTestDest, export).Return(nil)
+	remote.On("Put", exportTestDest, mock.Anything).Return(nil)
 	remote.On("Put", exportTestMetaDest, mock.MatchedBy(func(body io.Reader) bool {
 		// validate md5 version hash of the dump body
 		metadata := schema.Project{}
@@ -242,3 +250,132 @@ func TestExport(t *testing.T) {
 	assert.Equal(int(res.Total), len(exportTestPages))
 	assert.Zero(res.Errors)
 }
+
+func TestExportDeterministic(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	vol := t.TempDir()
+	loc := filepath.Join(vol, "json", exportTestProject.DbName)
+	assert.NoError(os.MkdirAll(loc, 0766))
+
+	for _, title := range []string{"Okapi", "Earth", "Zebra", "Main", "Ninja"} {
+		body := fmt.Sprintf(`{"name":"%s","namespace":{"identifier":0}}`, title)
+		assert.NoError(ioutil.WriteFile(filepath.Join(loc, fmt.Sprintf("%s.json", title)), []byte(body), 0644))
+	}
+
+	var chunk []byte
+	export := func() (*exportParquetRemoteMock, []string) {
+		repo := new(exportRepoMock)
+		repo.On("Find", mock.AnythingOfType("*models.Project")).Return(nil)
+		repo.On("Update").Return(nil)
+		remote := &exportParquetRemoteMock{files: map[string][]byte{}}
+		remote.On("Put", exportTestDest).Return(nil)
+		remote.On("Put", exportTestMetaDest).Return(nil)
+		store := &ExportStorage{
+			Loc:      fmt.Sprintf("json/%s", exportTestProject.DbName),
+			Dest:     exportTestDest,
+			MetaDest: exportTestMetaDest,
+			From:     fs.NewStorage(vol),
+			To:       fs.NewStorage(t.TempDir()),
+			Remote:   remote,
+		}
+
+		res, err := Export(ctx, &pb.ExportRequest{DbName: exportTestProject.DbName, Workers: 4}, repo, store)
+		assert.NoError(err)
+		assert.Equal(int32(5), res.Total)
+
+		gzr, err := gzip.NewReader(bytes.NewReader(remote.files[exportTestDest]))
+		assert.NoError(err)
+		tr := tar.NewReader(gzr)
+		hdr, err := tr.Next()
+		assert.NoError(err)
+		assert.Equal(exportModTime, hdr.ModTime.UTC())
+		data, err := ioutil.ReadAll(tr)
+		assert.NoError(err)
+		chunk = data
+
+		titles := []string{}
+
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			page := new(schema.Page)
+			assert.NoError(json.Unmarshal([]byte(line), page))
+			titles = append(titles, page.Name)
+		}
+
+		return remote, titles
+	}
+
+	first, titles := export()
+	second, _ := export()
+	assert.Equal([]string{"Earth", "Main", "Ninja", "Okapi", "Zebra"}, titles)
+	assert.Equal(first.files[exportTestDest], second.files[exportTestDest])
+
+	meta := new(schema.Project)
+	assert.NoError(json.Unmarshal(first.files[exportTestMetaDest], meta))
+	assert.Equal(fmt.Sprintf("%x", md5.Sum(first.files[exportTestDest])), *meta.Version) // #nosec G401
+	assert.Equal(fmt.Sprintf("%x", sha256.Sum256(first.files[exportTestDest])), meta.Manifest.SHA256)
+	assert.Len(meta.Manifest.Chunks, 1)
+	assert.Equal(fmt.Sprintf("%s_0.ndjson", exportTestProject.DbName), meta.Manifest.Chunks[0].Name)
+	assert.Equal(5, meta.Manifest.Chunks[0].Lines)
+	assert.Equal(fmt.Sprintf("%x", sha256.Sum256(chunk)), meta.Manifest.Chunks[0].SHA256)
+}
+
+func TestExportStream(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	vol := t.TempDir()
+	loc := filepath.Join(vol, "json", exportTestProject.DbName)
+	assert.NoError(os.MkdirAll(loc, 0766))
+
+	for _, title := range []string{"Earth", "Moon", "Mars"} {
+		body := fmt.Sprintf(`{"name":"%s","namespace":{"identifier":0}}`, title)
+		assert.NoError(ioutil.WriteFile(filepath.Join(loc, fmt.Sprintf("%s.json", title)), []byte(body), 0644))
+	}
+
+	export := func(stream bool) ([]byte, *schema.Project) {
+		repo := new(exportRepoMock)
+		repo.On("Find", mock.AnythingOfType("*models.Project")).Return(nil)
+		remote := &exportParquetRemoteMock{files: map[string][]byte{}}
+		remote.On("Put", exportTestDest).Return(nil)
+		remote.On("Put", exportTestMetaDest).Return(nil)
+		remoteVol, genVol := t.TempDir(), t.TempDir()
+		store := &ExportStorage{
+			Loc:       fmt.Sprintf("json/%s", exportTestProject.DbName),
+			Dest:      exportTestDest,
+			MetaDest:  exportTestMetaDest,
+			From:      fs.NewStorage(vol),
+			To:        fs.NewStorage(genVol),
+			Remote:    remote,
+			Multipart: multipart.NewFS(remoteVol),
+			Tracker:   fs.NewStorage(genVol),
+		}
+
+		res, err := Export(ctx, &pb.ExportRequest{DbName: exportTestProject.DbName, Workers: 2, Stream: stream}, repo, store)
+		assert.NoError(err)
+		assert.Equal(int32(3), res.Total)
+
+		meta := new(schema.Project)
+		assert.NoError(json.Unmarshal(remote.files[exportTestMetaDest], meta))
+
+		if !stream {
+			return remote.files[exportTestDest], meta
+		}
+
+		// nothing is left on the local volume, archive goes straight to the remote one
+		_, err = os.Stat(filepath.Join(genVol, exportTestDest))
+		assert.True(os.IsNotExist(err))
+		assert.NotContains(remote.files, exportTestDest)
+
+		data, err := ioutil.ReadFile(filepath.Join(remoteVol, exportTestDest))
+		assert.NoError(err)
+
+		return data, meta
+	}
+
+	local, localMeta := export(false)
+	streamed, streamedMeta := export(true)
+	assert.Equal(local, streamed)
+	assert.Equal(*localMeta.Version, *streamedMeta.Version)
+	assert.Equal(localMeta.Manifest, streamedMeta.Manifest)
+	assert.Equal(localMeta.Size, streamedMeta.Size)
+}
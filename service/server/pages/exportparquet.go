@@ -0,0 +1,185 @@
+package pages
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"okapi-data-service/models"
+	"okapi-data-service/schema/v3"
+	pb "okapi-data-service/server/pages/protos"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// ExportFormatParquet export request format that produces single parquet file instead of tar.gz of ndjson files
+const ExportFormatParquet = "parquet"
+
+// exportParquetRowGroupSize row group size of the parquet export (rows are buffered in memory until the group is full)
+const exportParquetRowGroupSize = 128 * 1024 * 1024
+
+// ExportParquetPage row of the parquet export, columns follow schema.Page field names
+type ExportParquetPage struct {
+	Name         string                   `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Identifier   int64                    `parquet:"name=identifier, type=INT64"`
+	DateModified *int64                   `parquet:"name=date_modified, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	URL          string                   `parquet:"name=url, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Version      *ExportParquetVersion    `parquet:"name=version"`
+	Namespace    *ExportParquetNamespace  `parquet:"name=namespace"`
+	InLanguage   *string                  `parquet:"name=in_language, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Categories   []*ExportParquetCategory `parquet:"name=categories, type=LIST"`
+	HTML         *string                  `parquet:"name=article_body_html, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Wikitext     *string                  `parquet:"name=article_body_wikitext, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// ExportParquetVersion version group of the parquet export row
+type ExportParquetVersion struct {
+	Identifier      int64    `parquet:"name=identifier, type=INT64"`
+	Comment         string   `parquet:"name=comment, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Tags            []string `parquet:"name=tags, type=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	IsMinorEdit     bool     `parquet:"name=is_minor_edit, type=BOOLEAN"`
+	IsFlaggedStable bool     `parquet:"name=is_flagged_stable, type=BOOLEAN"`
+	EditorName      *string  `parquet:"name=editor_name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	EditorID        *int64   `parquet:"name=editor_identifier, type=INT64"`
+}
+
+// ExportParquetNamespace namespace group of the parquet export row
+type ExportParquetNamespace struct {
+	Name       string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Identifier int32  `parquet:"name=identifier, type=INT32"`
+}
+
+// ExportParquetCategory element of the categories list of the parquet export row
+type ExportParquetCategory struct {
+	Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	URL  string `parquet:"name=url, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// NewExportParquetPage convert page into the parquet export row
+func NewExportParquetPage(page *schema.Page) *ExportParquetPage {
+	row := &ExportParquetPage{
+		Name:       page.Name,
+		Identifier: int64(page.Identifier),
+		URL:        page.URL,
+	}
+
+	if page.DateModified != nil {
+		millis := page.DateModified.UnixNano() / 1e6
+		row.DateModified = &millis
+	}
+
+	if page.Version != nil {
+		row.Version = &ExportParquetVersion{
+			Identifier:      int64(page.Version.Identifier),
+			Comment:         page.Version.Comment,
+			Tags:            page.Version.Tags,
+			IsMinorEdit:     page.Version.IsMinorEdit,
+			IsFlaggedStable: page.Version.IsFlaggedStable,
+		}
+
+		if page.Version.Editor != nil {
+			id := int64(page.Version.Editor.Identifier)
+			row.Version.EditorName = &page.Version.Editor.Name
+			row.Version.EditorID = &id
+		}
+	}
+
+	if page.Namespace != nil {
+		row.Namespace = &ExportParquetNamespace{
+			Name:       page.Namespace.Name,
+			Identifier: int32(page.Namespace.Identifier),
+		}
+	}
+
+	if page.InLanguage != nil {
+		row.InLanguage = &page.InLanguage.Identifier
+	}
+
+	for _, cat := range page.Categories {
+		row.Categories = append(row.Categories, &ExportParquetCategory{Name: cat.Name, URL: cat.URL})
+	}
+
+	if page.ArticleBody != nil {
+		row.HTML = &page.ArticleBody.HTML
+		row.Wikitext = &page.ArticleBody.Wikitext
+	}
+
+	return row
+}
+
+// ExportParquet generate parquet export file of the namespace and upload it to the storage
+func ExportParquet(ctx context.Context, req *pb.ExportRequest, repo exportRepo, store *ExportStorage) (*pb.ExportResponse, error) {
+	if len(req.Profile) > 0 && req.Profile != ExportProfileFull {
+		return nil, ErrExportProfileFormat
+	}
+
+	proj := new(models.Project)
+	err := repo.Find(ctx, proj, func(q *orm.Query) *orm.Query {
+		return q.
+			Where("db_name = ?", req.DbName)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := newExportArchive(req, store)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pw, err := writer.NewParquetWriterFromWriter(file, new(ExportParquetPage), int64(req.Workers))
+
+	if err != nil {
+		_ = file.publish(proj, store, err, 0, nil)
+		return nil, err
+	}
+
+	pw.RowGroupSize = exportParquetRowGroupSize
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+
+	res := new(pb.ExportResponse)
+	paths, err := exportWalk(store, req.DbName)
+
+	// rows are converted concurrently, but parquet writer is not safe for concurrent use and rows go in title order
+	exportOrdered(paths, int(req.Workers), func(path string) interface{} {
+		data, err := exportRead(store, path)
+
+		if err != nil {
+			log.Printf("path: %s, err: %v", path, err)
+			return nil
+		}
+
+		page := new(schema.Page)
+
+		if err := json.Unmarshal(data, page); err != nil {
+			log.Printf("path: %s, err: %v", path, err)
+			return nil
+		}
+
+		if page.Namespace == nil || page.Namespace.Identifier != int(req.Ns) {
+			return nil
+		}
+
+		return NewExportParquetPage(page)
+	}, func(row interface{}) {
+		res.Total++
+
+		if err := pw.Write(row); err != nil {
+			res.Errors++
+			log.Println(err)
+		}
+	})
+
+	if cerr := pw.WriteStop(); err == nil {
+		err = cerr
+	}
+
+	if err := file.publish(proj, store, err, res.Total, nil); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
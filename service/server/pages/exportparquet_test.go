@@ -0,0 +1,142 @@
+package pages
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"okapi-data-service/schema/v3"
+	pb "okapi-data-service/server/pages/protos"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/protsack-stephan/dev-toolkit/lib/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+const exportParquetTestDbName = "ninja"
+const exportParquetTestDest = "export/ninja/ninja_parquet_0.parquet"
+const exportParquetTestMetaDest = "export/ninja/ninja_parquet_0.json"
+
+var exportParquetTestPages = map[string]string{
+	"Earth": `{"name":"Earth","identifier":1,"date_modified":"2021-04-24T16:52:59Z","version":{"identifier":10,"tags":["mobile edit"],"editor":{"identifier":5,"name":"Ninja"}},"namespace":{"name":"Article","identifier":0},"categories":[{"name":"Category:Planets","url":"https://en.wikipedia.org/wiki/Category:Planets"}],"article_body":{"html":"<p>Earth</p>","wikitext":"Earth"}}`,
+	"Moon":  `{"name":"Moon","identifier":2,"version":{"identifier":20},"namespace":{"name":"Article","identifier":0}}`,
+	"Rocks": `{"name":"Category:Rocks","identifier":3,"version":{"identifier":30},"namespace":{"name":"Category","identifier":14}}`,
+}
+
+type exportParquetRemoteMock struct {
+	mock.Mock
+	files map[string][]byte
+}
+
+func (s *exportParquetRemoteMock) Put(path string, body io.Reader) error {
+	data, err := ioutil.ReadAll(body)
+
+	if err != nil {
+		return err
+	}
+
+	s.files[path] = data
+	return s.Called(path).Error(0)
+}
+
+// exportParquetTestFile read only in memory parquet file
+type exportParquetTestFile struct {
+	*bytes.Reader
+	data []byte
+}
+
+func (f *exportParquetTestFile) Write(_ []byte) (int, error) {
+	return 0, errors.New("read only file")
+}
+
+func (f *exportParquetTestFile) Close() error {
+	return nil
+}
+
+func (f *exportParquetTestFile) Open(_ string) (source.ParquetFile, error) {
+	return &exportParquetTestFile{bytes.NewReader(f.data), f.data}, nil
+}
+
+func (f *exportParquetTestFile) Create(_ string) (source.ParquetFile, error) {
+	return nil, errors.New("read only file")
+}
+
+func TestExportParquet(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	vol := t.TempDir()
+	loc := filepath.Join(vol, "json", exportParquetTestDbName)
+	assert.NoError(os.MkdirAll(loc, 0766))
+
+	for title, data := range exportParquetTestPages {
+		assert.NoError(ioutil.WriteFile(filepath.Join(loc, fmt.Sprintf("%s.json", title)), []byte(data), 0644))
+	}
+
+	repo := new(exportRepoMock)
+	repo.On("Find", mock.AnythingOfType("*models.Project")).Return(nil)
+	remote := &exportParquetRemoteMock{files: map[string][]byte{}}
+	remote.On("Put", exportParquetTestDest).Return(nil)
+	remote.On("Put", exportParquetTestMetaDest).Return(nil)
+	store := &ExportStorage{
+		Loc:      fmt.Sprintf("json/%s", exportParquetTestDbName),
+		Dest:     exportParquetTestDest,
+		MetaDest: exportParquetTestMetaDest,
+		Format:   ExportFormatParquet,
+		From:     fs.NewStorage(vol),
+		To:       fs.NewStorage(t.TempDir()),
+		Remote:   remote,
+	}
+
+	res, err := ExportParquet(ctx, &pb.ExportRequest{DbName: exportParquetTestDbName, Workers: 2, Format: ExportFormatParquet}, repo, store)
+	assert.NoError(err)
+	assert.Equal(int32(2), res.Total)
+	assert.Zero(res.Errors)
+
+	meta := new(schema.Project)
+	assert.NoError(json.Unmarshal(remote.files[exportParquetTestMetaDest], meta))
+	assert.Equal(exportParquetTestDbName, meta.Identifier)
+	assert.Equal(ExportFormatParquet, meta.EncodingFormat)
+	assert.Equal(fmt.Sprintf("%x", sha256.Sum256(remote.files[exportParquetTestDest])), meta.Manifest.SHA256)
+	assert.Empty(meta.Manifest.Chunks)
+
+	data := remote.files[exportParquetTestDest]
+	pr, err := reader.NewParquetReader(&exportParquetTestFile{bytes.NewReader(data), data}, new(ExportParquetPage), 1)
+	assert.NoError(err)
+	defer pr.ReadStop()
+
+	rows := make([]ExportParquetPage, pr.GetNumRows())
+	assert.NoError(pr.Read(&rows))
+	assert.Len(rows, 2)
+
+	pages := map[string]ExportParquetPage{}
+
+	for _, row := range rows {
+		pages[row.Name] = row
+	}
+
+	earth := pages["Earth"]
+	assert.Equal(int64(10), earth.Version.Identifier)
+	assert.Equal([]string{"mobile edit"}, earth.Version.Tags)
+	assert.Equal("Ninja", *earth.Version.EditorName)
+	assert.Equal("Article", earth.Namespace.Name)
+	assert.Len(earth.Categories, 1)
+	assert.Equal("Category:Planets", earth.Categories[0].Name)
+	assert.Equal("<p>Earth</p>", *earth.HTML)
+	assert.Equal(int64(1619283179000), *earth.DateModified)
+
+	moon := pages["Moon"]
+	assert.Nil(moon.HTML)
+	assert.Nil(moon.Wikitext)
+	assert.Nil(moon.DateModified)
+	assert.Nil(moon.Version.EditorName)
+	assert.Empty(moon.Categories)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log"
 	"math"
+	"okapi-data-service/lib/env"
 	"okapi-data-service/models"
 	"okapi-data-service/pkg/page"
 	"okapi-data-service/server/pages/fetch"
@@ -26,6 +27,7 @@ type fetchRepo interface {
 	repository.Creator
 	repository.Finder
 	repository.Updater
+	repository.Deleter
 }
 
 type fetchStorage interface {
@@ -33,8 +35,8 @@ type fetchStorage interface {
 	storage.Deleter
 }
 
-// Fetch get page titles from the dumps and add the to the storage and database
-func Fetch(ctx context.Context, req *pb.FetchRequest, repo fetchRepo, mwdump *dumps.Client, store fetchStorage, fetcher fetch.FetcherFactory) (*pb.FetchResponse, error) {
+// Fetch get page titles from the dumps (or a dump file when path is provided) and add the to the storage and database
+func Fetch(ctx context.Context, req *pb.FetchRequest, repo fetchRepo, mwdump *dumps.Client, dstore *DumpStorage, store fetchStorage, fetcher fetch.FetcherFactory) (*pb.FetchResponse, error) {
 	res := new(pb.FetchResponse)
 	proj := new(models.Project)
 	err := repo.Find(ctx, proj, func(q *orm.Query) *orm.Query {
@@ -58,6 +60,12 @@ func Fetch(ctx context.Context, req *pb.FetchRequest, repo fetchRepo, mwdump *du
 		return nil, err
 	}
 
+	license, err := page.License(ctx, repo, proj.DbName, ns.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
 	if req.Batch > 50 {
 		return nil, errBatchSizeToBig
 	}
@@ -95,20 +103,31 @@ func Fetch(ctx context.Context, req *pb.FetchRequest, repo fetchRepo, mwdump *du
 			}
 		}
 
-		if req.Ns == 0 {
-			if err := mwdump.PageTitles(ctx, req.DbName, time.Now().UTC(), filter); err != nil {
-				if err := mwdump.PageTitles(ctx, req.DbName, time.Now().UTC().Add(-24*time.Hour), filter); err != nil {
-					return nil, err
-				}
+		if len(req.DumpPath) > 0 {
+			if err := dstore.Titles(ctx, req.DumpPath, map[int]string{ns.ID: ns.Title}, filter); err != nil {
+				return nil, err
 			}
 		} else {
-			date := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.UTC)
+			dates, err := dumpDate(req.DumpDate, req.Ns, time.Now().UTC())
+
+			if err != nil {
+				return nil, err
+			}
+
+			for i, date := range dates {
+				if req.Ns == 0 {
+					err = mwdump.PageTitles(ctx, req.DbName, date, filter)
+				} else {
+					err = mwdump.PageTitlesNs(ctx, req.DbName, date, filter)
+				}
 
-			if time.Now().UTC().Day() > 20 {
-				date = time.Date(time.Now().Year(), time.Now().Month(), 20, 0, 0, 0, 0, time.UTC)
+				// fall back to the previous dump only if the current one is not published yet
+				if err == nil || i == len(dates)-1 {
+					break
+				}
 			}
 
-			if err := mwdump.PageTitlesNs(ctx, req.DbName, date, filter); err != nil {
+			if err != nil {
 				return nil, err
 			}
 		}
@@ -124,6 +143,8 @@ func Fetch(ctx context.Context, req *pb.FetchRequest, repo fetchRepo, mwdump *du
 			Project:   proj,
 			Language:  proj.Language,
 			Namespace: ns,
+			License:   license,
+			Templates: env.TemplateData,
 		},
 		store,
 		mwiki,
@@ -155,15 +176,14 @@ func Fetch(ctx context.Context, req *pb.FetchRequest, repo fetchRepo, mwdump *du
 
 	close(jobs)
 	res.Total = int32(length)
-	res.Redirects = int32(length)
 
 	for i := 1; i <= batches; i++ {
 		for title, err := range <-errs {
-			if err != nil {
+			if err == fetch.ErrPageRedirect {
+				res.Redirects++
+			} else if err != nil {
 				log.Printf("title: %s err: %v", title, err)
 				res.Errors++
-			} else {
-				res.Redirects--
 			}
 		}
 	}
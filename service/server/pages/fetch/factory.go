@@ -1,20 +1,48 @@
 package fetch
 
 import (
+	"net/http"
+	"okapi-data-service/lib/env"
+	"okapi-data-service/pkg/actions"
 	"okapi-data-service/pkg/page"
+	"okapi-data-service/pkg/wikidata"
+	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/protsack-stephan/mediawiki-api-client"
+	"github.com/protsack-stephan/mediawiki-ores-client"
 )
 
 // Factory for fetch worker
-type Factory struct{}
+type Factory struct {
+	Cache redis.Cmdable // wikidata entities, revision scores and editor profiles cache
+}
 
 // Create create new fetch worker
 func (f Factory) Create(fact *page.Factory, store Storage, mwiki *mediawiki.Client, repo Repo) Fetcher {
+	acts := actions.NewClient(fact.Project.SiteURL, map[string]string{
+		"User-Agent": env.MediawikiAPIUserAgent,
+	})
+	acts.HTTPClient.Timeout = time.Second * 30
+
+	wiki := wikidata.NewClient(env.WikidataURL, map[string]string{
+		"User-Agent": env.MediawikiAPIUserAgent,
+	})
+	wiki.HTTPClient.Timeout = time.Second * 30
+	wiki.Cache = f.Cache
+
 	return &Worker{
-		fact,
-		store,
-		mwiki,
-		repo,
+		fact:  fact,
+		store: store,
+		mwiki: mwiki,
+		repo:  repo,
+		acts:  acts,
+		wiki:  wiki,
+		addts: env.WikidataAdditionalEntities,
+		ores: ores.NewBuilder().
+			URL(env.ORESURL).
+			HTTPClient(&http.Client{Timeout: time.Second * 30}).
+			Build(),
+		cache: f.Cache,
 	}
 }
@@ -1,6 +1,8 @@
 package fetch
 
 import (
+	"okapi-data-service/lib/env"
+	"okapi-data-service/models"
 	"okapi-data-service/pkg/page"
 	"testing"
 
@@ -12,7 +14,11 @@ import (
 
 func TestFactory(t *testing.T) {
 	assert := assert.New(t)
-	pfact := new(page.Factory)
+	pfact := &page.Factory{
+		Project: &models.Project{
+			SiteURL: "https://af.wikibooks.org",
+		},
+	}
 	store := new(storage.Mock)
 	mwiki := new(mediawiki.Client)
 	repo := new(repository.Mock)
@@ -24,4 +30,7 @@ func TestFactory(t *testing.T) {
 	assert.Equal(fetcher.store, store)
 	assert.Equal(fetcher.mwiki, mwiki)
 	assert.Equal(fetcher.repo, repo)
+	assert.Equal(pfact.Project.SiteURL, fetcher.acts.URL)
+	assert.Equal(env.WikidataURL, fetcher.wiki.URL)
+	assert.NotNil(fetcher.ores)
 }
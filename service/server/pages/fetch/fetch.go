@@ -2,6 +2,7 @@ package fetch
 
 import (
 	"context"
+	"errors"
 	"okapi-data-service/pkg/page"
 	"okapi-data-service/schema/v3"
 
@@ -10,11 +11,15 @@ import (
 	"github.com/protsack-stephan/mediawiki-api-client"
 )
 
+// ErrPageRedirect signal that the title is a redirect and was saved as redirect record instead of the page
+var ErrPageRedirect = errors.New("page is a redirect")
+
 // Repo fetch repository
 type Repo interface {
 	repository.Updater
 	repository.Finder
 	repository.Creator
+	repository.Deleter
 }
 
 // Storage interface for communication with storage
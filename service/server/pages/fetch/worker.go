@@ -5,17 +5,32 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"math"
 	"okapi-data-service/models"
+	"okapi-data-service/pkg/actions"
+	"okapi-data-service/pkg/editors"
 	"okapi-data-service/pkg/page"
+	"okapi-data-service/pkg/titlepath"
+	"okapi-data-service/pkg/wikidata"
 	"okapi-data-service/schema/v3"
+	"strings"
+	"time"
 
 	"github.com/go-pg/pg/v10/orm"
+	"github.com/go-redis/redis/v8"
 	"github.com/protsack-stephan/mediawiki-api-client"
+	"github.com/protsack-stephan/mediawiki-ores-client"
 )
 
 const batchRequests = 10
 
+// batchScores max number of revisions in a single scores request
+const batchScores = 50
+
+// scoresExpire expire time of the cached revision scores
+const scoresExpire = time.Hour * 24 * 7
+
 type pageError struct {
 	title string
 	err   error
@@ -26,11 +41,22 @@ type response struct {
 	err  error
 }
 
+// latestRevision unreviewed latest revision of the page that was replaced with the stable one
+type latestRevision struct {
+	data mediawiki.PageData
+	html []byte
+}
+
 type Worker struct {
 	fact  *page.Factory
 	store Storage
 	mwiki *mediawiki.Client
 	repo  Repo
+	acts  *actions.Client
+	wiki  *wikidata.Client
+	addts bool // enrich additional entities as well
+	ores  *ores.Client
+	cache redis.Cmdable
 }
 
 func (w Worker) GetPagesData(ctx context.Context, titles []string) (map[string]mediawiki.PageData, error) {
@@ -54,7 +80,156 @@ func (w Worker) GetPagesModel(ctx context.Context, titles []string) (map[string]
 	return models, err
 }
 
+// GetPagesProps get interlanguage links, used files and redirect targets of the pages in one request
+func (w Worker) GetPagesProps(ctx context.Context, titles []string) (*actions.Props, error) {
+	if w.acts == nil {
+		return actions.NewProps(), nil
+	}
+
+	return w.acts.Props(ctx, titles)
+}
+
+// GetLangLinksProjects resolve target projects of the interlanguage links by site url
+func (w Worker) GetLangLinksProjects(ctx context.Context, links map[string][]actions.LangLink) (map[string]*models.Project, error) {
+	urls := map[string]struct{}{}
+
+	for _, llinks := range links {
+		for _, link := range llinks {
+			if siteURL, err := actions.SiteURL(link.URL); err == nil {
+				urls[siteURL] = struct{}{}
+			}
+		}
+	}
+
+	projects := map[string]*models.Project{}
+
+	if len(urls) == 0 {
+		return projects, nil
+	}
+
+	siteURLs := []string{}
+
+	for siteURL := range urls {
+		siteURLs = append(siteURLs, siteURL)
+	}
+
+	records := []*models.Project{}
+	err := w.repo.Find(ctx, &records, func(q *orm.Query) *orm.Query {
+		return q.
+			ColumnExpr("project.*, language.local_name as language__local_name, language.code as language__code").
+			Join("left join languages as language").
+			JoinOn("project.lang = language.code").
+			WhereIn("site_url in (?)", siteURLs)
+	})
+
+	for _, proj := range records {
+		projects[proj.SiteURL] = proj
+	}
+
+	return projects, err
+}
+
+// SetLangLinks attach interlanguage links to the page schemas
+func (w Worker) SetLangLinks(schemas map[string]*schema.Page, links map[string][]actions.LangLink, projects map[string]*models.Project) {
+	for title, llinks := range links {
+		page, ok := schemas[title]
+
+		if !ok {
+			continue
+		}
+
+		for _, link := range llinks {
+			llink := &schema.Page{
+				Name: link.Title,
+				URL:  link.URL,
+			}
+
+			siteURL, _ := actions.SiteURL(link.URL)
+
+			if proj, ok := projects[siteURL]; ok {
+				llink.IsPartOf = &schema.Project{
+					Name:       proj.SiteName,
+					Identifier: proj.DbName,
+				}
+
+				if proj.Language != nil {
+					llink.InLanguage = &schema.Language{
+						Name:       proj.Language.LocalName,
+						Identifier: proj.Language.Code,
+					}
+				}
+			}
+
+			if llink.InLanguage == nil {
+				llink.InLanguage = &schema.Language{
+					Identifier: link.Lang,
+				}
+			}
+
+			page.LangLinks = append(page.LangLinks, llink)
+		}
+	}
+}
+
+func (w Worker) GetPagesFiles(ctx context.Context, titles []string) (map[string]actions.ImageInfo, error) {
+	if w.acts == nil || w.fact.Namespace.ID != schema.NamespaceFile {
+		return map[string]actions.ImageInfo{}, nil
+	}
+
+	return w.acts.ImagesInfo(ctx, titles)
+}
+
+// SetImages attach list of used files to the page schemas
+func (w Worker) SetImages(schemas map[string]*schema.Page, images map[string][]string) {
+	for title, files := range images {
+		page, ok := schemas[title]
+
+		if !ok {
+			continue
+		}
+
+		for _, file := range files {
+			page.Images = append(page.Images, &schema.Page{
+				Name: file,
+				URL:  fmt.Sprintf("%s/wiki/%s", w.fact.Project.SiteURL, strings.ReplaceAll(file, " ", "_")),
+			})
+		}
+	}
+}
+
+// SetFiles attach file metadata to the page schemas, file license replaces the default page license
+func (w Worker) SetFiles(schemas map[string]*schema.Page, infos map[string]actions.ImageInfo) {
+	for title, info := range infos {
+		page, ok := schemas[title]
+
+		if !ok {
+			continue
+		}
+
+		page.File = w.fact.CreateFile(&info) // #nosec G601
+
+		if page.File.License != nil {
+			page.License = []*schema.License{page.File.License}
+		}
+	}
+}
+
 func (w Worker) GetPagesHTML(ctx context.Context, titles []string) map[string]*response {
+	return w.getPagesHTML(ctx, titles, map[string]int{})
+}
+
+// GetPagesRevisionHTML get html of the particular revisions of the pages
+func (w Worker) GetPagesRevisionHTML(ctx context.Context, revs map[string]int) map[string]*response {
+	titles := []string{}
+
+	for title := range revs {
+		titles = append(titles, title)
+	}
+
+	return w.getPagesHTML(ctx, titles, revs)
+}
+
+func (w Worker) getPagesHTML(ctx context.Context, titles []string, revs map[string]int) map[string]*response {
 	workers := int(math.Ceil(float64(len(titles)) / batchRequests))
 	data := make(chan map[string]*response, workers)
 
@@ -70,7 +245,13 @@ func (w Worker) GetPagesHTML(ctx context.Context, titles []string) map[string]*r
 
 			for _, title := range titles {
 				res := new(response)
-				res.data, res.err = w.mwiki.PageHTML(ctx, title)
+
+				if rev, ok := revs[title]; ok {
+					res.data, res.err = w.mwiki.PageHTML(ctx, title, rev)
+				} else {
+					res.data, res.err = w.mwiki.PageHTML(ctx, title)
+				}
+
 				resps[title] = res
 			}
 
@@ -89,6 +270,78 @@ func (w Worker) GetPagesHTML(ctx context.Context, titles []string) map[string]*r
 	return resps
 }
 
+// SetStable replace the latest revision of the pages with the flagged stable one for stable only projects,
+// returns original data and html of the replaced pages to keep the unreviewed latest revision.
+// Pages that were never reviewed stay on the latest revision, the same way readers see them.
+func (w Worker) SetStable(ctx context.Context, pages map[string]mediawiki.PageData, htmls map[string]*response) (map[string]*latestRevision, error) {
+	latest := map[string]*latestRevision{}
+
+	if w.acts == nil || !w.fact.Project.StableOnly {
+		return latest, nil
+	}
+
+	ids := []int{}
+
+	for _, pdata := range pages {
+		if pdata.Flagged.StableRevID > 0 && pdata.Flagged.StableRevID != pdata.LastRevID {
+			ids = append(ids, pdata.Flagged.StableRevID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return latest, nil
+	}
+
+	revs, err := w.acts.Revisions(ctx, ids)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stables := map[string]int{}
+
+	for title, pdata := range pages {
+		if _, ok := revs[pdata.Flagged.StableRevID]; ok && pdata.Flagged.StableRevID != pdata.LastRevID {
+			stables[title] = pdata.Flagged.StableRevID
+		}
+	}
+
+	for title, res := range w.GetPagesRevisionHTML(ctx, stables) {
+		prev := htmls[title]
+		htmls[title] = res
+
+		if res.err != nil {
+			continue
+		}
+
+		pdata := pages[title]
+		latest[title] = &latestRevision{data: pdata}
+
+		if prev != nil && prev.err == nil {
+			latest[title].html = prev.data
+		}
+
+		pdata.LastRevID = stables[title]
+		pdata.Revisions = []mediawiki.PageDataRevision{revs[stables[title]]}
+		pages[title] = pdata
+	}
+
+	return latest, nil
+}
+
+// SetLatest set unreviewed latest version of the pages that were replaced with the stable revision, including its article body
+func (w Worker) SetLatest(schemas map[string]*schema.Page, latest map[string]*latestRevision) {
+	for title, rev := range latest {
+		if page, ok := schemas[title]; ok && len(rev.data.Revisions) > 0 {
+			page.LatestVersion = w.fact.Version(rev.data.LastRevID, &rev.data.Revisions[0], &rev.data.Flagged)
+			page.LatestVersion.ArticleBody = &schema.ArticleBody{
+				HTML:     string(rev.html),
+				Wikitext: rev.data.Revisions[0].Slots.Main.Content,
+			}
+		}
+	}
+}
+
 func (w Worker) UpdatePages(ctx context.Context, pages []*models.Page) map[string]error {
 	errs := map[string]error{}
 
@@ -119,6 +372,320 @@ func (w Worker) CreatePages(ctx context.Context, pages []*models.Page) map[strin
 	return errs
 }
 
+// redirectTitle title in the form used by the pages table (underscores instead of spaces)
+func redirectTitle(title string) string {
+	return strings.ReplaceAll(title, " ", "_")
+}
+
+// SaveRedirects create or update redirect records, records of the titles that are regular pages now get removed.
+// Titles and targets are saved in the same form as page titles, so they can be matched against each other.
+func (w Worker) SaveRedirects(ctx context.Context, found map[string]actions.Redirect, pages map[string]mediawiki.PageData) error {
+	titles := []string{}
+	redirects := map[string]actions.Redirect{}
+
+	for title, target := range found {
+		target.Title = redirectTitle(target.Title)
+		redirects[redirectTitle(title)] = target
+		titles = append(titles, redirectTitle(title))
+	}
+
+	for title := range pages {
+		titles = append(titles, redirectTitle(title))
+	}
+
+	if len(titles) == 0 {
+		return nil
+	}
+
+	existing := []*models.Redirect{}
+	err := w.repo.Find(ctx, &existing, func(q *orm.Query) *orm.Query {
+		return q.
+			Where("db_name = ?", w.fact.Project.DbName).
+			WhereIn("title in (?)", titles)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	saved, deletes := map[string]bool{}, []string{}
+
+	for _, red := range existing {
+		target, ok := redirects[red.Title]
+
+		if !ok {
+			deletes = append(deletes, red.Title)
+			continue
+		}
+
+		saved[red.Title] = true
+
+		if red.Target == target.Title && red.Fragment == target.Fragment {
+			continue
+		}
+
+		red.Target = target.Title
+		red.Fragment = target.Fragment
+		_, err := w.repo.Update(ctx, red, func(q *orm.Query) *orm.Query {
+			return q.Where("id = ?", red.ID)
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	creates := []*models.Redirect{}
+
+	for title, target := range redirects {
+		if !saved[title] {
+			creates = append(creates, &models.Redirect{
+				DbName:   w.fact.Project.DbName,
+				Title:    title,
+				NsID:     w.fact.Namespace.ID,
+				Target:   target.Title,
+				Fragment: target.Fragment,
+			})
+		}
+	}
+
+	if len(creates) > 0 {
+		if _, err := w.repo.Create(ctx, &creates); err != nil {
+			return err
+		}
+	}
+
+	if len(deletes) > 0 {
+		_, err := w.repo.Delete(ctx, new(models.Redirect), func(q *orm.Query) *orm.Query {
+			return q.
+				Where("db_name = ?", w.fact.Project.DbName).
+				WhereIn("title in (?)", deletes)
+		})
+
+		return err
+	}
+
+	return nil
+}
+
+// SetEntities enrich page entities with wikidata labels, descriptions and instance of values
+func (w Worker) SetEntities(ctx context.Context, schemas map[string]*schema.Page) error {
+	if w.wiki == nil {
+		return nil
+	}
+
+	ents, ids := []*schema.Entity{}, []string{}
+
+	for _, sch := range schemas {
+		if sch.MainEntity != nil {
+			ents = append(ents, sch.MainEntity)
+		}
+
+		if w.addts {
+			ents = append(ents, sch.AdditionalEntities...)
+		}
+	}
+
+	if len(ents) == 0 {
+		return nil
+	}
+
+	for _, ent := range ents {
+		ids = append(ids, ent.Identifier)
+	}
+
+	items, err := w.wiki.Entities(ctx, w.fact.Project.Lang, ids...)
+
+	if err != nil {
+		return err
+	}
+
+	classIDs := []string{}
+
+	for _, item := range items {
+		classIDs = append(classIDs, item.InstanceOf...)
+	}
+
+	classes, err := w.wiki.Entities(ctx, w.fact.Project.Lang, classIDs...)
+
+	if err != nil {
+		return err
+	}
+
+	for _, ent := range ents {
+		item, ok := items[ent.Identifier]
+
+		if !ok {
+			continue
+		}
+
+		ent.Name = item.Label
+		ent.Description = item.Description
+		ent.InstanceOf = nil
+
+		for _, id := range item.InstanceOf {
+			class := &schema.Entity{
+				Identifier: id,
+				URL:        fmt.Sprintf("%s%s", page.WikidataURL, id),
+			}
+
+			if citem, ok := classes[id]; ok {
+				class.Name = citem.Label
+			}
+
+			ent.InstanceOf = append(ent.InstanceOf, class)
+		}
+	}
+
+	return nil
+}
+
+// SetEditors fill editor profiles from the shared editors cache
+func (w Worker) SetEditors(ctx context.Context, schemas map[string]*schema.Page) {
+	for _, sch := range schemas {
+		if sch.Version != nil {
+			editors.Fill(ctx, w.cache, w.fact.Project.DbName, sch.Version.Editor)
+		}
+	}
+}
+
+// SetScores backfill revision scores from ORES for the wikis that support it, scores are cached by revision
+func (w Worker) SetScores(ctx context.Context, schemas map[string]*schema.Page) error {
+	dbName := w.fact.Project.DbName
+
+	if w.ores == nil || !ores.ModelDamaging.Supports(dbName) {
+		return nil
+	}
+
+	versions := map[int][]*schema.Version{}
+	revs := []int{}
+
+	for _, sch := range schemas {
+		if sch.Version == nil || sch.Version.Identifier == 0 || sch.Version.Scores != nil {
+			continue
+		}
+
+		if scores := w.getScores(ctx, sch.Version.Identifier); scores != nil {
+			sch.Version.Scores = scores
+			continue
+		}
+
+		if _, ok := versions[sch.Version.Identifier]; !ok {
+			revs = append(revs, sch.Version.Identifier)
+		}
+
+		versions[sch.Version.Identifier] = append(versions[sch.Version.Identifier], sch.Version)
+	}
+
+	scoreModels := []ores.Model{ores.ModelDamaging}
+
+	if ores.ModelGoodFaith.Supports(dbName) {
+		scoreModels = append(scoreModels, ores.ModelGoodFaith)
+	}
+
+	for i := 0; i < len(revs); i += batchScores {
+		end := int(math.Min(float64(i+batchScores), float64(len(revs))))
+		res, err := w.ores.ScoreMany(ctx, dbName, scoreModels, revs[i:end]...)
+
+		if err != nil {
+			return err
+		}
+
+		if res == nil {
+			continue
+		}
+
+		for rev, score := range res.Scores {
+			scores := new(schema.Scores)
+
+			if score.Damaging != nil && score.Damaging.Score != nil {
+				scores.Damaging = score.Damaging.Score
+			}
+
+			if score.Goodfaith.Score != nil {
+				scores.GoodFaith = score.Goodfaith.Score
+			}
+
+			if scores.Damaging == nil && scores.GoodFaith == nil {
+				continue
+			}
+
+			for _, version := range versions[rev] {
+				version.Scores = scores
+			}
+
+			w.setScores(ctx, rev, scores)
+		}
+	}
+
+	return nil
+}
+
+func (w Worker) getScores(ctx context.Context, rev int) *schema.Scores {
+	if w.cache == nil {
+		return nil
+	}
+
+	data, err := w.cache.Get(ctx, scoresKey(w.fact.Project.DbName, rev)).Bytes()
+
+	if err != nil {
+		return nil
+	}
+
+	scores := new(schema.Scores)
+
+	if err := json.Unmarshal(data, scores); err != nil {
+		return nil
+	}
+
+	return scores
+}
+
+func (w Worker) setScores(ctx context.Context, rev int, scores *schema.Scores) {
+	if w.cache == nil {
+		return
+	}
+
+	if data, err := json.Marshal(scores); err == nil {
+		w.cache.Set(ctx, scoresKey(w.fact.Project.DbName, rev), data, scoresExpire)
+	}
+}
+
+func scoresKey(dbName string, rev int) string {
+	return fmt.Sprintf("scores/%s/%d", dbName, rev)
+}
+
+// SetHashes calculate content hashes of the page schemas
+func (w Worker) SetHashes(schemas map[string]*schema.Page) {
+	for _, sch := range schemas {
+		if hash, err := page.Hash(sch); err == nil {
+			sch.ContentHash = hash
+		}
+	}
+}
+
+// GetChanged filter out page schemas that are already stored with the same content hash
+func (w Worker) GetChanged(schemas map[string]*schema.Page, records map[string]*models.Page) map[string]*schema.Page {
+	changed := map[string]*schema.Page{}
+
+	for title, sch := range schemas {
+		if !isUnchanged(sch, records[title]) {
+			changed[title] = sch
+		}
+	}
+
+	return changed
+}
+
+func isUnchanged(sch *schema.Page, record *models.Page) bool {
+	return sch != nil &&
+		record != nil &&
+		!record.Failed &&
+		len(record.Path) > 0 &&
+		len(sch.ContentHash) > 0 &&
+		sch.ContentHash == record.ContentHash
+}
+
 func (w Worker) SaveSchemas(ctx context.Context, pages map[string]*schema.Page) map[string]*response {
 	semaphore := make(chan int, len(pages))
 	resps := make(chan map[string]*response, len(pages))
@@ -126,7 +693,7 @@ func (w Worker) SaveSchemas(ctx context.Context, pages map[string]*schema.Page)
 	for title, page := range pages {
 		semaphore <- 1
 		go func(title string, page *schema.Page) {
-			path := fmt.Sprintf("json/%s/%s.json", w.fact.Project.DbName, title)
+			path := fmt.Sprintf("json/%s/%s.json", w.fact.Project.DbName, titlepath.Encode(title))
 			data, err := json.Marshal(page)
 
 			if err != nil {
@@ -159,9 +726,54 @@ func (w Worker) SaveSchemas(ctx context.Context, pages map[string]*schema.Page)
 	return data
 }
 
+// SaveRevisions append new page versions to the revisions history
+func (w Worker) SaveRevisions(ctx context.Context, pages map[string]*schema.Page) error {
+	revs, ids := map[int]*models.Revision{}, []int{}
+
+	for _, page := range pages {
+		if page.Version != nil && page.Version.Identifier != 0 {
+			revs[page.Version.Identifier] = models.NewRevision(page, w.fact.Project)
+			ids = append(ids, page.Version.Identifier)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	existing := []*models.Revision{}
+	err := w.repo.Find(ctx, &existing, func(q *orm.Query) *orm.Query {
+		return q.
+			Column("revision").
+			Where("db_name = ?", w.fact.Project.DbName).
+			WhereIn("revision in (?)", ids)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, rev := range existing {
+		delete(revs, rev.Revision)
+	}
+
+	creates := []*models.Revision{}
+
+	for _, rev := range revs {
+		creates = append(creates, rev)
+	}
+
+	if len(creates) == 0 {
+		return nil
+	}
+
+	_, err = w.repo.Create(ctx, &creates)
+	return err
+}
+
 // Fetch bulk download and update data
 func (w Worker) Fetch(ctx context.Context, titles ...string) (map[string]*schema.Page, map[string]error, error) {
-	reqs := make(chan error, 3)
+	reqs := make(chan error, 5)
 	htmls := map[string]*response{}
 
 	go func() {
@@ -185,15 +797,41 @@ func (w Worker) Fetch(ctx context.Context, titles ...string) (map[string]*schema
 		reqs <- err
 	}()
 
-	for i := 0; i < 3; i++ {
+	props := actions.NewProps()
+	go func() {
+		data, err := w.GetPagesProps(ctx, titles)
+		props = data
+		reqs <- err
+	}()
+
+	files := make(map[string]actions.ImageInfo)
+	go func() {
+		data, err := w.GetPagesFiles(ctx, titles)
+		files = data
+		reqs <- err
+	}()
+
+	for i := 0; i < 5; i++ {
 		if err := <-reqs; err != nil {
 			return nil, nil, err
 		}
 	}
 
+	latest, err := w.SetStable(ctx, pages, htmls)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
 	errs := map[string]error{}
 	schemas := map[string]*schema.Page{}
 
+	for title := range props.Redirects {
+		if _, ok := pages[title]; !ok {
+			errs[title] = ErrPageRedirect
+		}
+	}
+
 	for title, pdata := range pages {
 		res, ok := htmls[title]
 
@@ -204,16 +842,45 @@ func (w Worker) Fetch(ctx context.Context, titles ...string) (map[string]*schema
 		}
 	}
 
+	projects, err := w.GetLangLinksProjects(ctx, props.LangLinks)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w.SetLatest(schemas, latest)
+	w.SetLangLinks(schemas, props.LangLinks, projects)
+	w.SetImages(schemas, props.Images)
+	w.SetFiles(schemas, files)
+
+	if err := w.SetEntities(ctx, schemas); err != nil {
+		log.Printf("db_name: %s, err: %v", w.fact.Project.DbName, err)
+	}
+
+	w.SetEditors(ctx, schemas)
+
+	if err := w.SetScores(ctx, schemas); err != nil {
+		log.Printf("db_name: %s, err: %v", w.fact.Project.DbName, err)
+	}
+
+	w.SetHashes(schemas)
+
 	updates, creates := []*models.Page{}, []*models.Page{}
-	paths := w.SaveSchemas(ctx, schemas)
+	paths := w.SaveSchemas(ctx, w.GetChanged(schemas, records))
 
 	for title, pdata := range pages {
 		record, isUpdate := records[title]
 		page := models.NewPage(title, &pdata, w.fact.Project, record) // #nosec G601
 
+		if sch, ok := schemas[title]; ok {
+			page.ContentHash = sch.ContentHash
+		}
+
 		if path, ok := paths[title]; ok {
 			page.Failed = path.err != nil
 			page.Path = string(path.data)
+		} else if isUnchanged(schemas[title], record) {
+			page.Path = record.Path
 		} else {
 			page.Failed = true
 		}
@@ -251,5 +918,13 @@ func (w Worker) Fetch(ctx context.Context, titles ...string) (map[string]*schema
 		errs[pErr.title] = pErr.err
 	}
 
+	if err := w.SaveRevisions(ctx, schemas); err != nil {
+		log.Printf("db_name: %s, err: %v", w.fact.Project.DbName, err)
+	}
+
+	if err := w.SaveRedirects(ctx, props.Redirects, pages); err != nil {
+		log.Printf("db_name: %s, err: %v", w.fact.Project.DbName, err)
+	}
+
 	return schemas, errs, nil
 }
@@ -11,12 +11,18 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"okapi-data-service/models"
+	"okapi-data-service/pkg/actions"
+	"okapi-data-service/pkg/editors"
 	"okapi-data-service/pkg/page"
+	"okapi-data-service/pkg/wikidata"
 	"okapi-data-service/schema/v3"
 	"testing"
+	"time"
 
 	"github.com/go-pg/pg/v10/orm"
+	"github.com/go-redis/redis/v8"
 	"github.com/protsack-stephan/mediawiki-api-client"
+	"github.com/protsack-stephan/mediawiki-ores-client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -27,7 +33,9 @@ const workerTestWikitext = "...%s wikitext goes here..."
 var workerTestTitles = []string{"Earth", "Ninja", "Moon"}
 var workerTestProject = &models.Project{
 	DbName:   "afwikibooks",
+	Lang:     "af",
 	SiteName: "Wikibooks",
+	SiteURL:  "https://af.wikibooks.org",
 }
 var workerTestLanguage = &models.Language{
 	Code:      "af",
@@ -38,6 +46,25 @@ var workerTestNamespace = &models.Namespace{
 	Title: "Article",
 }
 
+var workerTestRevisions = []int{100, 101}
+
+var workerTestLangLinkProject = &models.Project{
+	DbName:   "dewiki",
+	SiteName: "Wikipedia",
+	SiteURL:  "https://de.wikipedia.org",
+	Language: &models.Language{
+		Code:      "de",
+		LocalName: "Deutsch",
+	},
+}
+
+var workerTestLangLinks = map[string][]actions.LangLink{
+	"Earth": {
+		{Lang: "de", Title: "Erde", URL: "https://de.wikipedia.org/wiki/Erde"},
+		{Lang: "fr", Title: "Terre", URL: "https://fr.wikipedia.org/wiki/Terre"},
+	},
+}
+
 type workerStorageMock struct {
 	mock.Mock
 }
@@ -58,9 +85,14 @@ func (s *workerStorageMock) Delete(path string) error {
 
 type workerRepoMock struct {
 	mock.Mock
+	reds []*models.Redirect
 }
 
 func (r *workerRepoMock) Update(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, _ ...interface{}) (orm.Result, error) {
+	if red, ok := model.(*models.Redirect); ok {
+		return nil, r.Called(red).Error(0)
+	}
+
 	return nil, r.Called(model.(*models.Page).Title).Error(0)
 }
 
@@ -74,6 +106,12 @@ func (r *workerRepoMock) Find(_ context.Context, model interface{}, _ func(*orm.
 			for _, title := range workerTestTitles {
 				*pages = append(*pages, &models.Page{Title: title})
 			}
+		case *[]*models.Project:
+			*pages = append(*pages, workerTestLangLinkProject)
+		case *[]*models.Revision:
+			*pages = append(*pages, &models.Revision{Revision: workerTestRevisions[0]})
+		case *[]*models.Redirect:
+			*pages = append(*pages, r.reds...)
 		}
 	}
 
@@ -84,6 +122,10 @@ func (r *workerRepoMock) Create(_ context.Context, model interface{}, _ ...inter
 	return nil, r.Called(model).Error(0)
 }
 
+func (r *workerRepoMock) Delete(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, _ ...interface{}) (orm.Result, error) {
+	return nil, r.Called(model).Error(0)
+}
+
 func createFetchServer() http.Handler {
 	router := http.NewServeMux()
 
@@ -108,6 +150,104 @@ func createFetchServer() http.Handler {
 	return router
 }
 
+func createRedirectServer() http.Handler {
+	router := http.NewServeMux()
+
+	router.HandleFunc("/w/api.php", func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(`{"query": {
+			"redirects": [{"from": "Planet Earth", "to": "Earth"}],
+			"pages": [{"pageid": 9228, "ns": 0, "title": "Earth"}]
+		}}`))
+	})
+
+	return router
+}
+
+const workerTestStableHTML = "...stable html goes here..."
+
+func createStableServer() http.Handler {
+	router := http.NewServeMux()
+
+	router.HandleFunc("/w/api.php", func(rw http.ResponseWriter, r *http.Request) {
+		if r.FormValue("revids") != "100" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_, _ = rw.Write([]byte(`{"query": {"pages": [{"title": "Earth", "revisions": [
+			{"revid": 100, "user": "Reviewer", "userid": 10, "comment": "stable", "slots": {"main": {"content": "stable wikitext"}}}
+		]}]}}`))
+	})
+
+	router.HandleFunc("/api/rest_v1/page/html/Earth/100", func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(workerTestStableHTML))
+	})
+
+	return router
+}
+
+type workerCacheMock struct {
+	redis.Cmdable
+	data map[string]string
+}
+
+func (c *workerCacheMock) Get(_ context.Context, key string) *redis.StringCmd {
+	if val, ok := c.data[key]; ok {
+		return redis.NewStringResult(val, nil)
+	}
+
+	return redis.NewStringResult("", redis.Nil)
+}
+
+func (c *workerCacheMock) Set(_ context.Context, key string, value interface{}, _ time.Duration) *redis.StatusCmd {
+	c.data[key] = string(value.([]byte))
+	return redis.NewStatusResult("OK", nil)
+}
+
+func createScoresServer(calls *int) http.Handler {
+	router := http.NewServeMux()
+
+	router.HandleFunc("/v3/scores/enwiki", func(rw http.ResponseWriter, r *http.Request) {
+		*calls++
+
+		if r.URL.Query().Get("models") != "damaging|goodfaith" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		data, err := ioutil.ReadFile("./testdata/ores_scores.json")
+
+		if err != nil {
+			log.Panic(err)
+		}
+
+		_, _ = rw.Write(data)
+	})
+
+	return router
+}
+
+func createWikidataServer() http.Handler {
+	router := http.NewServeMux()
+
+	router.HandleFunc("/w/api.php", func(rw http.ResponseWriter, r *http.Request) {
+		if r.FormValue("languages") != workerTestProject.Lang {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		data, err := ioutil.ReadFile("./testdata/wikidata_entities.json")
+
+		if err != nil {
+			log.Panic(err)
+		}
+
+		_, _ = rw.Write(data)
+	})
+
+	return router
+}
+
 func TestWorker(t *testing.T) {
 	assert := assert.New(t)
 	srv := httptest.NewServer(createFetchServer())
@@ -180,6 +320,107 @@ func TestWorker(t *testing.T) {
 		assert.Zero(len(data))
 	})
 
+	t.Run("get lang links projects success", func(t *testing.T) {
+		repo := new(workerRepoMock)
+		repo.On("Find", &[]*models.Project{}).Return(nil)
+
+		worker := new(Worker)
+		worker.repo = repo
+
+		projects, err := worker.GetLangLinksProjects(ctx, workerTestLangLinks)
+		assert.NoError(err)
+		assert.Equal(workerTestLangLinkProject, projects[workerTestLangLinkProject.SiteURL])
+		repo.AssertNumberOfCalls(t, "Find", 1)
+	})
+
+	t.Run("get lang links projects empty", func(t *testing.T) {
+		repo := new(workerRepoMock)
+
+		worker := new(Worker)
+		worker.repo = repo
+
+		projects, err := worker.GetLangLinksProjects(ctx, map[string][]actions.LangLink{})
+		assert.NoError(err)
+		assert.Empty(projects)
+		repo.AssertNumberOfCalls(t, "Find", 0)
+	})
+
+	t.Run("set lang links", func(t *testing.T) {
+		schemas := map[string]*schema.Page{
+			"Earth": {Name: "Earth"},
+		}
+
+		worker := new(Worker)
+		worker.SetLangLinks(schemas, workerTestLangLinks, map[string]*models.Project{
+			workerTestLangLinkProject.SiteURL: workerTestLangLinkProject,
+		})
+
+		assert.Len(schemas["Earth"].LangLinks, 2)
+		assert.Equal("Erde", schemas["Earth"].LangLinks[0].Name)
+		assert.Equal(workerTestLangLinkProject.DbName, schemas["Earth"].LangLinks[0].IsPartOf.Identifier)
+		assert.Equal("Deutsch", schemas["Earth"].LangLinks[0].InLanguage.Name)
+		assert.Equal("Terre", schemas["Earth"].LangLinks[1].Name)
+		assert.Nil(schemas["Earth"].LangLinks[1].IsPartOf)
+		assert.Equal("fr", schemas["Earth"].LangLinks[1].InLanguage.Identifier)
+	})
+
+	t.Run("set images", func(t *testing.T) {
+		schemas := map[string]*schema.Page{
+			"Earth": {Name: "Earth"},
+		}
+
+		worker := new(Worker)
+		worker.fact = fact
+		worker.SetImages(schemas, map[string][]string{
+			"Earth": {"File:Blue Marble.jpg"},
+			"Moon":  {"File:Moon.jpg"},
+		})
+
+		assert.Len(schemas["Earth"].Images, 1)
+		assert.Equal("File:Blue Marble.jpg", schemas["Earth"].Images[0].Name)
+		assert.Equal(fmt.Sprintf("%s/wiki/File:Blue_Marble.jpg", workerTestProject.SiteURL), schemas["Earth"].Images[0].URL)
+		assert.NotContains(schemas, "Moon")
+	})
+
+	t.Run("set files", func(t *testing.T) {
+		schemas := map[string]*schema.Page{
+			"File:Earth.jpg": {Name: "File:Earth.jpg", License: []*schema.License{schema.NewLicense()}},
+			"File:Moon.jpg":  {Name: "File:Moon.jpg", License: []*schema.License{schema.NewLicense()}},
+		}
+
+		worker := new(Worker)
+		worker.fact = fact
+		worker.SetFiles(schemas, map[string]actions.ImageInfo{
+			"File:Earth.jpg": {
+				URL:  "https://upload.wikimedia.org/Earth.jpg",
+				Mime: "image/jpeg",
+				ExtMetadata: map[string]actions.ExtMetadata{
+					"License":          {Value: "pd"},
+					"LicenseShortName": {Value: "Public domain"},
+				},
+			},
+			"File:Moon.jpg": {
+				URL:  "https://upload.wikimedia.org/Moon.jpg",
+				Mime: "image/jpeg",
+			},
+		})
+
+		assert.Equal("https://upload.wikimedia.org/Earth.jpg", schemas["File:Earth.jpg"].File.URL)
+		assert.Equal([]*schema.License{{Name: "Public domain", Identifier: "PD"}}, schemas["File:Earth.jpg"].License)
+		assert.Equal("https://upload.wikimedia.org/Moon.jpg", schemas["File:Moon.jpg"].File.URL)
+		assert.Equal([]*schema.License{schema.NewLicense()}, schemas["File:Moon.jpg"].License)
+	})
+
+	t.Run("get pages files skipped outside of file namespace", func(t *testing.T) {
+		worker := new(Worker)
+		worker.fact = fact
+		worker.acts = actions.NewClient("http://localhost:0", map[string]string{})
+
+		files, err := worker.GetPagesFiles(ctx, workerTestTitles)
+		assert.NoError(err)
+		assert.Empty(files)
+	})
+
 	t.Run("get pages html success", func(t *testing.T) {
 		worker := new(Worker)
 		worker.mwiki = mediawiki.NewClient(srv.URL)
@@ -209,6 +450,72 @@ func TestWorker(t *testing.T) {
 		}
 	})
 
+	t.Run("set stable", func(t *testing.T) {
+		ssrv := httptest.NewServer(createStableServer())
+		defer ssrv.Close()
+
+		proj := *workerTestProject
+		proj.StableOnly = true
+
+		worker := new(Worker)
+		worker.fact = &page.Factory{Project: &proj, Language: workerTestLanguage, Namespace: workerTestNamespace}
+		worker.mwiki = mediawiki.NewClient(ssrv.URL)
+		worker.acts = actions.NewClient(ssrv.URL, map[string]string{})
+
+		pages := map[string]mediawiki.PageData{
+			"Earth": {Title: "Earth", LastRevID: 101, Revisions: []mediawiki.PageDataRevision{{RevID: 101, Comment: "latest", Slots: mediawiki.PageDataRevisionSlots{Main: mediawiki.PageDataRevisionMainSlot{Content: "latest wikitext"}}}}},
+			"Ninja": {Title: "Ninja", LastRevID: 200, Revisions: []mediawiki.PageDataRevision{{RevID: 200}}},
+			"Moon":  {Title: "Moon", LastRevID: 300, Revisions: []mediawiki.PageDataRevision{{RevID: 300}}},
+		}
+		earth, ninja := pages["Earth"], pages["Ninja"]
+		earth.Flagged.StableRevID = 100
+		ninja.Flagged.StableRevID = 200
+		pages["Earth"], pages["Ninja"] = earth, ninja
+		htmls := map[string]*response{"Earth": {data: []byte("latest html")}}
+
+		latest, err := worker.SetStable(ctx, pages, htmls)
+		assert.NoError(err)
+		assert.Len(latest, 1)
+		assert.Equal(100, pages["Earth"].LastRevID)
+		assert.Equal("stable", pages["Earth"].Revisions[0].Comment)
+		assert.Equal(workerTestStableHTML, string(htmls["Earth"].data))
+		assert.Equal(200, pages["Ninja"].LastRevID)
+		assert.Equal(300, pages["Moon"].LastRevID)
+
+		earth = pages["Earth"]
+		schemas := map[string]*schema.Page{
+			"Earth": worker.fact.Create(&earth, string(htmls["Earth"].data)),
+		}
+		worker.SetLatest(schemas, latest)
+
+		assert.Equal(100, schemas["Earth"].Version.Identifier)
+		assert.True(schemas["Earth"].Version.IsFlaggedStable)
+		assert.Equal("stable wikitext", schemas["Earth"].ArticleBody.Wikitext)
+		assert.Equal(101, schemas["Earth"].LatestVersion.Identifier)
+		assert.Equal("latest", schemas["Earth"].LatestVersion.Comment)
+		assert.False(schemas["Earth"].LatestVersion.IsFlaggedStable)
+		assert.Equal("latest html", schemas["Earth"].LatestVersion.ArticleBody.HTML)
+		assert.Equal("latest wikitext", schemas["Earth"].LatestVersion.ArticleBody.Wikitext)
+	})
+
+	t.Run("set stable disabled", func(t *testing.T) {
+		worker := new(Worker)
+		worker.fact = fact
+		worker.acts = actions.NewClient(srv.URL, map[string]string{})
+
+		pages := map[string]mediawiki.PageData{
+			"Earth": {Title: "Earth", LastRevID: 101},
+		}
+		earth := pages["Earth"]
+		earth.Flagged.StableRevID = 100
+		pages["Earth"] = earth
+
+		latest, err := worker.SetStable(ctx, pages, map[string]*response{})
+		assert.NoError(err)
+		assert.Empty(latest)
+		assert.Equal(101, pages["Earth"].LastRevID)
+	})
+
 	t.Run("update pages success", func(t *testing.T) {
 		repo := new(workerRepoMock)
 		pages := []*models.Page{}
@@ -389,9 +696,319 @@ func TestWorker(t *testing.T) {
 		}
 	})
 
+	t.Run("set entities", func(t *testing.T) {
+		wsrv := httptest.NewServer(createWikidataServer())
+		defer wsrv.Close()
+
+		schemas := map[string]*schema.Page{
+			"Earth": {
+				Name:               "Earth",
+				MainEntity:         &schema.Entity{Identifier: "Q2"},
+				AdditionalEntities: []*schema.Entity{{Identifier: "Q3504248"}},
+			},
+			"Ninja": {
+				Name: "Ninja",
+			},
+		}
+
+		worker := new(Worker)
+		worker.fact = fact
+		worker.wiki = wikidata.NewClient(wsrv.URL, map[string]string{})
+
+		assert.NoError(worker.SetEntities(ctx, schemas))
+
+		ent := schemas["Earth"].MainEntity
+		assert.Equal("Aarde", ent.Name)
+		assert.Equal("third planet from the Sun in the Solar System", ent.Description)
+		assert.Len(ent.InstanceOf, 1)
+		assert.Equal("Q3504248", ent.InstanceOf[0].Identifier)
+		assert.Equal("inner planet", ent.InstanceOf[0].Name)
+		assert.Equal(fmt.Sprintf("%s%s", page.WikidataURL, "Q3504248"), ent.InstanceOf[0].URL)
+		assert.Empty(schemas["Earth"].AdditionalEntities[0].Name)
+
+		worker.addts = true
+		assert.NoError(worker.SetEntities(ctx, schemas))
+		assert.Equal("inner planet", schemas["Earth"].AdditionalEntities[0].Name)
+	})
+
+	t.Run("set entities error", func(t *testing.T) {
+		wsrv := httptest.NewServer(createWikidataServer())
+		defer wsrv.Close()
+
+		worker := new(Worker)
+		worker.fact = &page.Factory{Project: &models.Project{Lang: "en"}}
+		worker.wiki = wikidata.NewClient(wsrv.URL, map[string]string{})
+
+		assert.Error(worker.SetEntities(ctx, map[string]*schema.Page{
+			"Earth": {MainEntity: &schema.Entity{Identifier: "Q2"}},
+		}))
+	})
+
+	t.Run("set editors", func(t *testing.T) {
+		profile, err := json.Marshal(&schema.Editor{
+			Identifier: 10,
+			EditCount:  100,
+			Groups:     []string{"sysop"},
+		})
+		assert.NoError(err)
+
+		schemas := map[string]*schema.Page{
+			workerTestTitles[0]: {Version: &schema.Version{Editor: &schema.Editor{Identifier: 10}}},
+			workerTestTitles[1]: {Version: &schema.Version{Editor: &schema.Editor{Identifier: 11}}},
+			workerTestTitles[2]: {},
+		}
+
+		worker := new(Worker)
+		worker.fact = fact
+		worker.cache = &workerCacheMock{data: map[string]string{
+			editors.Key(workerTestProject.DbName, 10): string(profile),
+		}}
+		worker.SetEditors(ctx, schemas)
+
+		assert.Equal(100, schemas[workerTestTitles[0]].Version.Editor.EditCount)
+		assert.Equal([]string{"sysop"}, schemas[workerTestTitles[0]].Version.Editor.Groups)
+		assert.Zero(schemas[workerTestTitles[1]].Version.Editor.EditCount)
+	})
+
+	t.Run("set scores", func(t *testing.T) {
+		calls := 0
+		ssrv := httptest.NewServer(createScoresServer(&calls))
+		defer ssrv.Close()
+
+		schemas := map[string]*schema.Page{}
+
+		for i, title := range workerTestTitles[:2] {
+			schemas[title] = &schema.Page{
+				Name:    title,
+				Version: &schema.Version{Identifier: workerTestRevisions[i]},
+			}
+		}
+
+		cache := &workerCacheMock{data: map[string]string{}}
+		worker := new(Worker)
+		worker.fact = &page.Factory{Project: &models.Project{DbName: "enwiki"}}
+		worker.ores = ores.NewBuilder().URL(fmt.Sprintf("%s/v3/scores", ssrv.URL)).Build()
+		worker.cache = cache
+
+		assert.NoError(worker.SetScores(ctx, schemas))
+		assert.Equal(1, calls)
+
+		scores := schemas[workerTestTitles[0]].Version.Scores
+		assert.NotNil(scores)
+		assert.Equal(0.1, scores.Damaging.Probability.True)
+		assert.Equal(0.8, scores.GoodFaith.Probability.True)
+		assert.Nil(schemas[workerTestTitles[1]].Version.Scores)
+		assert.Contains(cache.data, fmt.Sprintf("scores/enwiki/%d", workerTestRevisions[0]))
+
+		cached := map[string]*schema.Page{
+			workerTestTitles[0]: {
+				Name:    workerTestTitles[0],
+				Version: &schema.Version{Identifier: workerTestRevisions[0]},
+			},
+		}
+
+		assert.NoError(worker.SetScores(ctx, cached))
+		assert.Equal(1, calls)
+		assert.Equal(scores, cached[workerTestTitles[0]].Version.Scores)
+	})
+
+	t.Run("set scores not supported", func(t *testing.T) {
+		calls := 0
+		ssrv := httptest.NewServer(createScoresServer(&calls))
+		defer ssrv.Close()
+
+		worker := new(Worker)
+		worker.fact = fact
+		worker.ores = ores.NewBuilder().URL(fmt.Sprintf("%s/v3/scores", ssrv.URL)).Build()
+
+		assert.NoError(worker.SetScores(ctx, map[string]*schema.Page{
+			workerTestTitles[0]: {Version: &schema.Version{Identifier: workerTestRevisions[0]}},
+		}))
+		assert.Equal(0, calls)
+	})
+
+	t.Run("set scores error", func(t *testing.T) {
+		calls := 0
+		ssrv := httptest.NewServer(createScoresServer(&calls))
+		defer ssrv.Close()
+
+		worker := new(Worker)
+		worker.fact = &page.Factory{Project: &models.Project{DbName: "enwiki"}}
+		worker.ores = ores.NewBuilder().URL(ssrv.URL).Build()
+
+		assert.Error(worker.SetScores(ctx, map[string]*schema.Page{
+			workerTestTitles[0]: {Version: &schema.Version{Identifier: workerTestRevisions[0]}},
+		}))
+	})
+
+	t.Run("get changed schemas", func(t *testing.T) {
+		schemas := map[string]*schema.Page{}
+
+		for _, title := range workerTestTitles {
+			schemas[title] = &schema.Page{Name: title}
+		}
+
+		worker := new(Worker)
+		worker.SetHashes(schemas)
+
+		for _, sch := range schemas {
+			assert.Len(sch.ContentHash, 64)
+		}
+
+		records := map[string]*models.Page{
+			workerTestTitles[0]: {
+				Path:        "json/test/Earth.json",
+				ContentHash: schemas[workerTestTitles[0]].ContentHash,
+			},
+			workerTestTitles[1]: {
+				Path:        "json/test/Ninja.json",
+				ContentHash: schemas[workerTestTitles[1]].ContentHash,
+				Failed:      true,
+			},
+		}
+
+		changed := worker.GetChanged(schemas, records)
+		assert.Len(changed, 2)
+		assert.NotContains(changed, workerTestTitles[0])
+		assert.Contains(changed, workerTestTitles[1])
+		assert.Contains(changed, workerTestTitles[2])
+	})
+
+	t.Run("save revisions success", func(t *testing.T) {
+		pages := map[string]*schema.Page{}
+
+		for i, title := range workerTestTitles[:2] {
+			pages[title] = &schema.Page{
+				Name:    title,
+				Version: &schema.Version{Identifier: workerTestRevisions[i]},
+			}
+		}
+
+		pages[workerTestTitles[2]] = &schema.Page{Name: workerTestTitles[2]}
+
+		repo := new(workerRepoMock)
+		repo.On("Find", &[]*models.Revision{}).Return(nil)
+		repo.On("Create", mock.MatchedBy(func(revs *[]*models.Revision) bool {
+			return len(*revs) == 1 && (*revs)[0].Revision == workerTestRevisions[1] && (*revs)[0].DbName == workerTestProject.DbName
+		})).Return(nil)
+
+		worker := new(Worker)
+		worker.fact = fact
+		worker.repo = repo
+
+		assert.NoError(worker.SaveRevisions(ctx, pages))
+		repo.AssertNumberOfCalls(t, "Find", 1)
+		repo.AssertNumberOfCalls(t, "Create", 1)
+	})
+
+	t.Run("save revisions error", func(t *testing.T) {
+		errFind := errors.New("find error")
+		repo := new(workerRepoMock)
+		repo.On("Find", &[]*models.Revision{}).Return(errFind)
+
+		worker := new(Worker)
+		worker.fact = fact
+		worker.repo = repo
+
+		assert.Equal(errFind, worker.SaveRevisions(ctx, map[string]*schema.Page{
+			workerTestTitles[0]: {
+				Name:    workerTestTitles[0],
+				Version: &schema.Version{Identifier: workerTestRevisions[1]},
+			},
+		}))
+		repo.AssertNumberOfCalls(t, "Create", 0)
+	})
+
+	t.Run("get pages props without actions client", func(t *testing.T) {
+		worker := new(Worker)
+
+		props, err := worker.GetPagesProps(ctx, workerTestTitles)
+		assert.NoError(err)
+		assert.Empty(props.LangLinks)
+		assert.Empty(props.Images)
+		assert.Empty(props.Redirects)
+	})
+
+	t.Run("save redirects success", func(t *testing.T) {
+		unchanged := &models.Redirect{ID: 1, Title: "Planet_Earth", Target: "Earth"}
+		changed := &models.Redirect{ID: 2, Title: "Moon_rock", Target: "Moon"}
+		page := &models.Redirect{ID: 3, Title: "Ninja", Target: "Shinobi"}
+		repo := &workerRepoMock{reds: []*models.Redirect{unchanged, changed, page}}
+		repo.On("Find", &[]*models.Redirect{}).Return(nil)
+		repo.On("Update", changed).Return(nil)
+		repo.On("Create", mock.MatchedBy(func(reds *[]*models.Redirect) bool {
+			return len(*reds) == 1 &&
+				(*reds)[0].Title == "Ninja_star" &&
+				(*reds)[0].Target == "Shuriken" &&
+				(*reds)[0].Fragment == "Types" &&
+				(*reds)[0].DbName == workerTestProject.DbName
+		})).Return(nil)
+		repo.On("Delete", new(models.Redirect)).Return(nil)
+
+		worker := new(Worker)
+		worker.fact = fact
+		worker.repo = repo
+
+		err := worker.SaveRedirects(ctx, map[string]actions.Redirect{
+			"Planet_Earth": {Title: "Earth"},
+			"Moon rock":    {Title: "Lunar rock"},
+			"Ninja star":   {Title: "Shuriken", Fragment: "Types"},
+		}, map[string]mediawiki.PageData{
+			"Ninja": {Title: "Ninja"},
+		})
+		assert.NoError(err)
+		assert.Equal("Lunar_rock", changed.Target)
+		repo.AssertNumberOfCalls(t, "Update", 1)
+		repo.AssertNumberOfCalls(t, "Create", 1)
+		repo.AssertNumberOfCalls(t, "Delete", 1)
+	})
+
+	t.Run("save redirects error", func(t *testing.T) {
+		errFind := errors.New("find error")
+		repo := new(workerRepoMock)
+		repo.On("Find", &[]*models.Redirect{}).Return(errFind)
+
+		worker := new(Worker)
+		worker.fact = fact
+		worker.repo = repo
+
+		assert.Equal(errFind, worker.SaveRedirects(ctx, map[string]actions.Redirect{
+			"Planet Earth": {Title: "Earth"},
+		}, map[string]mediawiki.PageData{}))
+		repo.AssertNumberOfCalls(t, "Create", 0)
+	})
+
+	t.Run("fetch redirect", func(t *testing.T) {
+		srv := httptest.NewServer(createRedirectServer())
+		defer srv.Close()
+
+		repo := new(workerRepoMock)
+		repo.On("Find", &[]*models.Page{}).Return(nil)
+		repo.On("Find", &[]*models.Redirect{}).Return(nil)
+		repo.On("Create", mock.MatchedBy(func(reds *[]*models.Redirect) bool {
+			return len(*reds) == 1 && (*reds)[0].Title == "Planet_Earth" && (*reds)[0].Target == "Earth"
+		})).Return(nil)
+
+		worker := new(Worker)
+		worker.fact = fact
+		worker.repo = repo
+		worker.store = new(workerStorageMock)
+		worker.mwiki = mediawiki.NewClient(srv.URL)
+		worker.acts = actions.NewClient(srv.URL, map[string]string{})
+
+		data, errs, err := worker.Fetch(ctx, "Planet Earth")
+		assert.NoError(err)
+		assert.Empty(data)
+		assert.Equal(ErrPageRedirect, errs["Planet Earth"])
+		repo.AssertNumberOfCalls(t, "Create", 1)
+	})
+
 	t.Run("fetch success", func(t *testing.T) {
 		repo := new(workerRepoMock)
 		repo.On("Find", &[]*models.Page{}).Return(nil)
+		repo.On("Find", &[]*models.Revision{}).Return(nil)
+		repo.On("Find", &[]*models.Redirect{}).Return(nil)
+		repo.On("Create", mock.Anything).Return(nil)
 
 		store := new(workerStorageMock)
 
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/go-pg/pg/v10/orm"
+	"github.com/protsack-stephan/dev-toolkit/lib/fs"
 	"github.com/protsack-stephan/mediawiki-api-client"
 	dumps "github.com/protsack-stephan/mediawiki-dumps-client"
 	"github.com/stretchr/testify/assert"
@@ -64,6 +65,10 @@ func (r *fetchRepoMock) Update(_ context.Context, model interface{}, _ func(*orm
 	return nil, r.Called(model).Error(0)
 }
 
+func (r *fetchRepoMock) Delete(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, _ ...interface{}) (orm.Result, error) {
+	return nil, r.Called(model).Error(0)
+}
+
 type fetchStorageMock struct{}
 
 func (s *fetchStorageMock) Put(_ string, _ io.Reader) error {
@@ -121,6 +126,7 @@ func TestFetch(t *testing.T) {
 	repo := new(fetchRepoMock)
 	repo.On("Find", new(models.Project)).Return(nil)
 	repo.On("Find", new(models.Namespace)).Return(nil)
+	repo.On("Find", &[]*models.License{}).Return(nil)
 
 	errs := map[string]error{}
 
@@ -128,6 +134,8 @@ func TestFetch(t *testing.T) {
 		errs[title] = nil
 	}
 
+	errs[fetchTestTitles[0]] = fetch.ErrPageRedirect
+
 	worker := new(fetchWorkerMock)
 	worker.On("Fetch", fetchTestTitles).Return(errs, nil)
 
@@ -137,9 +145,26 @@ func TestFetch(t *testing.T) {
 	store := new(fetchStorageMock)
 	mwiki := dumps.NewBuilder().URL(srv.URL).Build()
 
-	res, err := Fetch(ctx, req, repo, mwiki, store, factory)
+	res, err := Fetch(ctx, req, repo, mwiki, nil, store, factory)
 	assert.NoError(err)
 	assert.NotZero(res.Total)
-	assert.Zero(res.Redirects)
+	assert.Equal(int32(1), res.Redirects)
 	assert.Zero(res.Errors)
+
+	t.Run("dump path", func(t *testing.T) {
+		req.DumpPath = "titles.gz"
+		defer func() { req.DumpPath = "" }()
+
+		res, err := Fetch(ctx, req, repo, nil, &DumpStorage{Local: fs.NewStorage("./testdata")}, store, factory)
+		assert.NoError(err)
+		assert.Equal(int32(len(fetchTestTitles)), res.Total)
+	})
+
+	t.Run("dump date", func(t *testing.T) {
+		req.DumpDate = time.Now().UTC().Add(-48 * time.Hour).Format(dumpDateFormat)
+		defer func() { req.DumpDate = "" }()
+
+		_, err := Fetch(ctx, req, repo, mwiki, nil, store, factory)
+		assert.Error(err)
+	})
 }
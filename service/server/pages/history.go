@@ -0,0 +1,100 @@
+package pages
+
+import (
+	"context"
+	"okapi-data-service/models"
+	pb "okapi-data-service/server/pages/protos"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/protsack-stephan/dev-toolkit/pkg/repository"
+)
+
+// historyLimit default number of revisions in the history response
+const historyLimit = 100
+
+// History get version timeline of the page, latest revisions first,
+// revisions are looked up by page id, so the page keeps its history after a rename
+func History(ctx context.Context, req *pb.HistoryRequest, repo repository.Finder) (*pb.HistoryResponse, error) {
+	if req.Limit <= 0 {
+		req.Limit = historyLimit
+	}
+
+	title := strings.ReplaceAll(req.Title, " ", "_")
+	page := new(models.Page)
+	err := repo.Find(ctx, page, func(q *orm.Query) *orm.Query {
+		return q.
+			Column("pid").
+			Where("db_name = ? and title = ?", req.DbName, title).
+			Limit(1)
+	})
+
+	if err != nil && err != pg.ErrNoRows {
+		return nil, err
+	}
+
+	filter := func(q *orm.Query) *orm.Query {
+		if page.PID > 0 {
+			q = q.Where("db_name = ? and pid = ?", req.DbName, page.PID)
+		} else {
+			q = q.Where("db_name = ? and title = ?", req.DbName, title)
+		}
+
+		if req.Since > 0 {
+			q = q.Where("revision_dt >= ?", time.Unix(req.Since, 0).UTC())
+		}
+
+		return q
+	}
+
+	total := 0
+	err = repo.Find(ctx, new(models.Revision), func(q *orm.Query) *orm.Query {
+		return filter(q).ColumnExpr("count(*)")
+	}, &total)
+
+	if err != nil {
+		return nil, err
+	}
+
+	revs := make([]*models.Revision, 0)
+	err = repo.Find(ctx, &revs, func(q *orm.Query) *orm.Query {
+		return filter(q).
+			Order("revision_dt desc").
+			Limit(int(req.Limit))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.HistoryResponse{
+		Total:     int32(total),
+		Revisions: make([]*pb.HistoryRevision, 0, len(revs)),
+	}
+
+	for _, rev := range revs {
+		item := &pb.HistoryRevision{
+			Identifier:   int32(rev.Revision),
+			DateModified: rev.RevisionDt.Unix(),
+			EditorId:     int32(rev.EditorID),
+			EditorName:   rev.EditorName,
+			Comment:      rev.Comment,
+			Tags:         rev.Tags,
+			ContentHash:  rev.ContentHash,
+		}
+
+		if rev.Scores != nil && rev.Scores.Damaging != nil {
+			item.Damaging = rev.Scores.Damaging.Probability.True
+		}
+
+		if rev.Scores != nil && rev.Scores.GoodFaith != nil {
+			item.Goodfaith = rev.Scores.GoodFaith.Probability.True
+		}
+
+		res.Revisions = append(res.Revisions, item)
+	}
+
+	return res, nil
+}
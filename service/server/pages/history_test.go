@@ -0,0 +1,134 @@
+package pages
+
+import (
+	"context"
+	"errors"
+	"okapi-data-service/models"
+	"okapi-data-service/schema/v3"
+	pb "okapi-data-service/server/pages/protos"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/protsack-stephan/mediawiki-ores-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const historyTestDbName = "enwiki"
+const historyTestTitle = "Earth"
+
+var historyTestDate = time.Date(2021, 10, 19, 9, 0, 0, 0, time.UTC)
+
+const historyTestPID = 9228
+const historyTestTotal = 250
+
+type historyRepoMock struct {
+	mock.Mock
+}
+
+func (r *historyRepoMock) Find(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, values ...interface{}) error {
+	args := r.Called(model)
+
+	if page, ok := model.(*models.Page); ok && args.Error(0) == nil {
+		page.PID = historyTestPID
+	}
+
+	if _, ok := model.(*models.Revision); ok {
+		*values[0].(*int) = historyTestTotal
+	}
+
+	if revs, ok := model.(*[]*models.Revision); ok {
+		damaging := new(ores.ScoreDamaging)
+		damaging.Probability.True = 0.2
+
+		*revs = append(*revs, &models.Revision{
+			Revision:    101,
+			RevisionDt:  historyTestDate,
+			Title:       historyTestTitle,
+			PID:         historyTestPID,
+			DbName:      historyTestDbName,
+			EditorID:    10,
+			EditorName:  "Editor",
+			Comment:     "comment",
+			Tags:        []string{"mobile edit"},
+			ContentHash: "hash",
+			Scores:      &schema.Scores{Damaging: damaging},
+		}, &models.Revision{
+			Revision:   100,
+			RevisionDt: historyTestDate.Add(-time.Hour),
+			Title:      "Old_Earth",
+			PID:        historyTestPID,
+			DbName:     historyTestDbName,
+		})
+	}
+
+	return args.Error(0)
+}
+
+func TestHistory(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	req := &pb.HistoryRequest{
+		DbName: historyTestDbName,
+		Title:  historyTestTitle,
+	}
+
+	t.Run("history success", func(t *testing.T) {
+		repo := new(historyRepoMock)
+		repo.On("Find", mock.Anything).Return(nil)
+
+		res, err := History(ctx, req, repo)
+		assert.NoError(err)
+		assert.Equal(int32(historyTestTotal), res.Total)
+		assert.Len(res.Revisions, 2)
+
+		rev := res.Revisions[0]
+		assert.Equal(int32(101), rev.Identifier)
+		assert.Equal(historyTestDate.Unix(), rev.DateModified)
+		assert.Equal(int32(10), rev.EditorId)
+		assert.Equal("Editor", rev.EditorName)
+		assert.Equal("comment", rev.Comment)
+		assert.Equal([]string{"mobile edit"}, rev.Tags)
+		assert.Equal("hash", rev.ContentHash)
+		assert.Equal(0.2, rev.Damaging)
+		assert.Zero(rev.Goodfaith)
+		assert.Equal(int32(100), res.Revisions[1].Identifier)
+		assert.Equal(int32(historyLimit), req.Limit)
+		repo.AssertNumberOfCalls(t, "Find", 3)
+	})
+
+	t.Run("history page not found", func(t *testing.T) {
+		repo := new(historyRepoMock)
+		repo.On("Find", new(models.Page)).Return(pg.ErrNoRows)
+		repo.On("Find", new(models.Revision)).Return(nil)
+		repo.On("Find", &[]*models.Revision{}).Return(nil)
+
+		res, err := History(ctx, req, repo)
+		assert.NoError(err)
+		assert.Equal(int32(historyTestTotal), res.Total)
+		assert.Len(res.Revisions, 2)
+	})
+
+	t.Run("history error", func(t *testing.T) {
+		errFind := errors.New("find error")
+		repo := new(historyRepoMock)
+		repo.On("Find", new(models.Page)).Return(nil)
+		repo.On("Find", new(models.Revision)).Return(nil)
+		repo.On("Find", &[]*models.Revision{}).Return(errFind)
+
+		_, err := History(ctx, req, repo)
+		assert.Equal(errFind, err)
+	})
+
+	t.Run("history count error", func(t *testing.T) {
+		errFind := errors.New("count error")
+		repo := new(historyRepoMock)
+		repo.On("Find", new(models.Page)).Return(nil)
+		repo.On("Find", new(models.Revision)).Return(errFind)
+
+		_, err := History(ctx, req, repo)
+		assert.Equal(errFind, err)
+	})
+}
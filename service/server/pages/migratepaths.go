@@ -0,0 +1,101 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"okapi-data-service/models"
+	"okapi-data-service/pkg/titlepath"
+	pb "okapi-data-service/server/pages/protos"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/protsack-stephan/dev-toolkit/pkg/repository"
+	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
+)
+
+// migratePathsBatch number of pages loaded from the database at once
+const migratePathsBatch = 1000
+
+type migratePathsRepo interface {
+	repository.Finder
+	repository.Updater
+}
+
+type migratePathsStorage interface {
+	storage.Getter
+	storage.Putter
+	storage.Deleter
+}
+
+// MigratePaths move page json files stored under raw titles to the encoded paths
+func MigratePaths(ctx context.Context, req *pb.MigratePathsRequest, repo migratePathsRepo, store migratePathsStorage) (*pb.MigratePathsResponse, error) {
+	res := new(pb.MigratePathsResponse)
+	pointer := 0
+
+	for {
+		pages := []*models.Page{}
+		err := repo.Find(ctx, &pages, func(q *orm.Query) *orm.Query {
+			return q.
+				Column("id", "title", "path").
+				Where("db_name = ? and id > ? and path != ''", req.DbName, pointer).
+				Where("title ~ ?", `[%/\\]`).
+				Order("id asc").
+				Limit(migratePathsBatch)
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(pages) == 0 {
+			return res, nil
+		}
+
+		for _, page := range pages {
+			path := fmt.Sprintf("json/%s/%s.json", req.DbName, titlepath.Encode(page.Title))
+
+			if page.Path == path {
+				continue
+			}
+
+			if err := migratePath(ctx, repo, store, page, path); err != nil {
+				log.Printf("title: %s, err: %v", page.Title, err)
+				res.Errors++
+				continue
+			}
+
+			res.Total++
+		}
+
+		pointer = pages[len(pages)-1].ID
+	}
+}
+
+// migratePath copy the file to the new path, point database record to it and remove the old file
+func migratePath(ctx context.Context, repo repository.Updater, store migratePathsStorage, page *models.Page, path string) error {
+	file, err := store.Get(page.Path)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	if err := store.Put(path, file); err != nil {
+		return err
+	}
+
+	_, err = repo.Update(ctx, new(models.Page), func(q *orm.Query) *orm.Query {
+		return q.Set("path = ?", path).Where("id = ?", page.ID)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if err := store.Delete(page.Path); err != nil {
+		log.Printf("path: %s, err: %v", page.Path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"okapi-data-service/models"
+	pb "okapi-data-service/server/pages/protos"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/protsack-stephan/dev-toolkit/lib/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const migratePathsTestDbName = "enwiki"
+
+type migratePathsRepoMock struct {
+	mock.Mock
+	pages []*models.Page
+}
+
+func (r *migratePathsRepoMock) Find(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, _ ...interface{}) error {
+	switch model := model.(type) {
+	case *[]*models.Page:
+		*model = append(*model, r.pages...)
+		r.pages = nil
+	}
+
+	return r.Called(model).Error(0)
+}
+
+func (r *migratePathsRepoMock) Update(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, _ ...interface{}) (orm.Result, error) {
+	return nil, r.Called(model).Error(0)
+}
+
+func TestMigratePaths(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	vol := t.TempDir()
+	loc := filepath.Join(vol, "json", migratePathsTestDbName, "AC")
+	assert.NoError(os.MkdirAll(loc, 0766))
+	assert.NoError(ioutil.WriteFile(filepath.Join(loc, "DC.json"), []byte(`{"name":"AC/DC"}`), 0644))
+
+	repo := &migratePathsRepoMock{
+		pages: []*models.Page{
+			{ID: 1, Title: "AC/DC", Path: fmt.Sprintf("json/%s/AC/DC.json", migratePathsTestDbName)},
+			{ID: 2, Title: "100%", Path: fmt.Sprintf("json/%s/100%%25.json", migratePathsTestDbName)},
+			{ID: 3, Title: "Foo/Bar", Path: fmt.Sprintf("json/%s/Foo/Bar.json", migratePathsTestDbName)},
+		},
+	}
+	repo.On("Find", mock.AnythingOfType("*[]*models.Page")).Return(nil)
+	repo.On("Update", new(models.Page)).Return(nil)
+
+	store := fs.NewStorage(vol)
+	res, err := MigratePaths(ctx, &pb.MigratePathsRequest{DbName: migratePathsTestDbName}, repo, store)
+	assert.NoError(err)
+	assert.Equal(int32(1), res.Total)
+	assert.Equal(int32(1), res.Errors)
+	repo.AssertNumberOfCalls(t, "Update", 1)
+
+	rc, err := store.Get(fmt.Sprintf("json/%s/AC%%2FDC.json", migratePathsTestDbName))
+	assert.NoError(err)
+	data, err := ioutil.ReadAll(rc)
+	assert.NoError(err)
+	assert.Equal(`{"name":"AC/DC"}`, string(data))
+
+	_, err = os.Stat(filepath.Join(loc, "DC.json"))
+	assert.True(os.IsNotExist(err))
+}
@@ -0,0 +1,61 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"log"
+	pb "okapi-data-service/server/pages/protos"
+
+	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
+)
+
+// PackStorage storage to move page json files from (file per page layout) and to (segment storage)
+type PackStorage struct {
+	From interface {
+		storage.Getter
+		storage.Walker
+	}
+	To interface {
+		storage.Putter
+		Compact(dbName string) error
+	}
+}
+
+// Pack copy page json files of the project into segment storage
+func Pack(ctx context.Context, req *pb.PackRequest, store *PackStorage) (*pb.PackResponse, error) {
+	res := new(pb.PackResponse)
+	err := store.From.Walk(fmt.Sprintf("%s/%s", "json", req.DbName), func(path string) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		file, err := store.From.Get(path)
+
+		if err != nil {
+			log.Printf("path: %s, err: %v", path, err)
+			res.Errors++
+			return
+		}
+
+		defer file.Close()
+
+		if err := store.To.Put(path, file); err != nil {
+			log.Printf("path: %s, err: %v", path, err)
+			res.Errors++
+			return
+		}
+
+		res.Total++
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// rewrite the segments so that pages are laid out in namespace order
+	return res, store.To.Compact(req.DbName)
+}
@@ -0,0 +1,55 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"okapi-data-service/pkg/segment"
+	pb "okapi-data-service/server/pages/protos"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/protsack-stephan/dev-toolkit/lib/fs"
+	"github.com/stretchr/testify/assert"
+)
+
+const packTestDbName = "afwikibooks"
+
+var packTestPages = map[string]string{
+	"Earth":         `{"name":"Earth","namespace":{"identifier":0}}`,
+	"Category:Moon": `{"name":"Category:Moon","namespace":{"identifier":14}}`,
+	"Broken":        `{"name":"Broken"`,
+}
+
+func TestPack(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	vol := t.TempDir()
+	loc := filepath.Join(vol, "json", packTestDbName)
+	assert.NoError(os.MkdirAll(loc, 0766))
+
+	for title, body := range packTestPages {
+		assert.NoError(ioutil.WriteFile(filepath.Join(loc, fmt.Sprintf("%s.json", title)), []byte(body), 0644))
+	}
+
+	to := segment.NewStorage(vol)
+	defer to.Close()
+
+	res, err := Pack(ctx, &pb.PackRequest{DbName: packTestDbName}, &PackStorage{From: fs.NewStorage(vol), To: to})
+	assert.NoError(err)
+	assert.Equal(int32(2), res.Total)
+	assert.Equal(int32(1), res.Errors)
+
+	paths := []string{}
+	assert.NoError(to.Walk(segment.NsLoc(packTestDbName, 14), func(path string) {
+		paths = append(paths, path)
+	}))
+	assert.Equal([]string{fmt.Sprintf("json/%s/Category:Moon.json", packTestDbName)}, paths)
+
+	rc, err := to.Get(fmt.Sprintf("json/%s/Earth.json", packTestDbName))
+	assert.NoError(err)
+	data, err := ioutil.ReadAll(rc)
+	assert.NoError(err)
+	assert.Equal(packTestPages["Earth"], string(data))
+}
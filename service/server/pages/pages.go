@@ -7,11 +7,17 @@ import (
 	"okapi-data-service/lib/elastic"
 	"okapi-data-service/lib/env"
 	"okapi-data-service/lib/pg"
+	"okapi-data-service/lib/redis"
+	"okapi-data-service/pkg/multipart"
 	"okapi-data-service/pkg/page"
+	"okapi-data-service/pkg/segment"
 	"okapi-data-service/server/pages/fetch"
 	pb "okapi-data-service/server/pages/protos"
+	"time"
 
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
 	"github.com/elastic/go-elasticsearch/v7"
+	goredis "github.com/go-redis/redis/v8"
 	"github.com/protsack-stephan/dev-toolkit/pkg/repository"
 
 	dumps "github.com/protsack-stephan/mediawiki-dumps-client"
@@ -19,21 +25,30 @@ import (
 
 	"github.com/protsack-stephan/dev-toolkit/lib/db"
 	"github.com/protsack-stephan/dev-toolkit/lib/fs"
-	"github.com/protsack-stephan/dev-toolkit/lib/s3"
 	"github.com/protsack-stephan/dev-toolkit/pkg/server"
 	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
 )
 
+// PageStorage local storage for page json files
+type PageStorage interface {
+	storage.Getter
+	storage.Putter
+	storage.Deleter
+	storage.Walker
+}
+
 // Server for pages manipulation
 type Server struct {
 	pb.UnimplementedPagesServer
 	server.Sequential
 	remoteStore storage.Storage
-	jsonStore   storage.Storage
+	jsonStore   PageStorage
 	genStore    storage.Storage
+	multipart   multipart.Uploader
 	repo        repository.Repository
 	dumps       *dumps.Client
 	elastic     *elasticsearch.Client
+	cache       goredis.Cmdable
 }
 
 // Index index all the pages from the database
@@ -58,8 +73,9 @@ func (srv *Server) Fetch(ctx context.Context, req *pb.FetchRequest) (*pb.FetchRe
 			req,
 			srv.repo,
 			srv.dumps,
-			&page.Storage{Local: srv.jsonStore, Remote: srv.remoteStore},
-			new(fetch.Factory))
+			srv.dumpStorage(),
+			&page.Storage{Local: srv.jsonStore, Remote: srv.remoteStore, Encoding: env.JSONEncoding},
+			&fetch.Factory{Cache: srv.cache})
 		return
 	})
 
@@ -71,14 +87,36 @@ func (srv *Server) Fetch(ctx context.Context, req *pb.FetchRequest) (*pb.FetchRe
 func (srv *Server) Export(ctx context.Context, req *pb.ExportRequest) (*pb.ExportResponse, error) {
 	var res *pb.ExportResponse
 
-	err := srv.Once(fmt.Sprintf("%s/%s/%d", "export", req.DbName, req.Ns), func() (err error) {
+	err := srv.Once(fmt.Sprintf("%s/%s/%d/%s/%s", "export", req.DbName, req.Ns, req.Format, req.Profile), func() (err error) {
+		loc := fmt.Sprintf("%s/%s", "json", req.DbName)
+
+		// segment storage can range scan single namespace instead of walking the whole project
+		if _, ok := srv.jsonStore.(*segment.Storage); ok {
+			loc = segment.NsLoc(req.DbName, int(req.Ns))
+		}
+
 		store := &ExportStorage{
-			From:     srv.jsonStore,
-			MetaDest: fmt.Sprintf("export/%s/%s_%d.json", req.DbName, req.DbName, req.Ns),
-			Dest:     fmt.Sprintf("export/%s/%s_%s_%d.tar.gz", req.DbName, req.DbName, "json", req.Ns),
-			To:       srv.genStore,
-			Remote:   srv.remoteStore,
-			Loc:      fmt.Sprintf("%s/%s", "json", req.DbName),
+			From:      srv.jsonStore,
+			MetaDest:  fmt.Sprintf("export/%s/%s_%d.json", req.DbName, req.DbName, req.Ns),
+			Dest:      fmt.Sprintf("export/%s/%s_%s_%d.tar.gz", req.DbName, req.DbName, "json", req.Ns),
+			To:        srv.genStore,
+			Remote:    srv.remoteStore,
+			Multipart: srv.multipart,
+			Tracker:   srv.genStore,
+			Loc:       loc,
+		}
+
+		if len(req.Profile) > 0 && req.Profile != ExportProfileFull {
+			store.Dest = fmt.Sprintf("export/%s/%s_%s_%s_%d.tar.gz", req.DbName, req.DbName, "json", req.Profile, req.Ns)
+			store.MetaDest = fmt.Sprintf("export/%s/%s_%s_%d.json", req.DbName, req.DbName, req.Profile, req.Ns)
+		}
+
+		if req.Format == ExportFormatParquet {
+			store.Dest = fmt.Sprintf("export/%s/%s_%s_%d.parquet", req.DbName, req.DbName, ExportFormatParquet, req.Ns)
+			store.MetaDest = fmt.Sprintf("export/%s/%s_%s_%d.json", req.DbName, req.DbName, ExportFormatParquet, req.Ns)
+			store.Format = ExportFormatParquet
+			res, err = ExportParquet(ctx, req, srv.repo, store)
+			return
 		}
 
 		res, err = Export(ctx, req, srv.repo, store)
@@ -93,7 +131,7 @@ func (srv *Server) Export(ctx context.Context, req *pb.ExportRequest) (*pb.Expor
 func (srv *Server) Copy(ctx context.Context, req *pb.CopyRequest) (*pb.CopyResponse, error) {
 	var res *pb.CopyResponse
 
-	err := srv.Once(fmt.Sprintf("copy/%d", req.Ns), func() (err error) {
+	err := srv.Once(fmt.Sprintf("copy/%d/%s/%s", req.Ns, req.Format, req.Profile), func() (err error) {
 		res, err = Copy(ctx, req, srv.remoteStore, fmt.Sprintf("_%s", env.Group))
 		return
 	})
@@ -101,16 +139,148 @@ func (srv *Server) Copy(ctx context.Context, req *pb.CopyRequest) (*pb.CopyRespo
 	return res, err
 }
 
+// History get version history of the page
+func (srv *Server) History(ctx context.Context, req *pb.HistoryRequest) (*pb.HistoryResponse, error) {
+	return History(ctx, req, srv.repo)
+}
+
+// Redact remove suppressed revisions from already published exports and diffs of the project
+func (srv *Server) Redact(ctx context.Context, req *pb.RedactRequest) (*pb.RedactResponse, error) {
+	var res *pb.RedactResponse
+
+	err := srv.Once(fmt.Sprintf("%s/%s", "redact", req.DbName), func() (err error) {
+		remote, ok := srv.remoteStore.(redactRemote)
+
+		if !ok {
+			return ErrRedactRemote
+		}
+
+		store := &RedactStorage{
+			Local:  srv.genStore,
+			Remote: remote,
+		}
+
+		res, err = Redact(ctx, req, srv.repo, store)
+		return
+	})
+
+	return res, err
+}
+
+// ExportRedirects bundle and upload redirects of the project to storage
+func (srv *Server) ExportRedirects(ctx context.Context, req *pb.ExportRedirectsRequest) (*pb.ExportRedirectsResponse, error) {
+	var res *pb.ExportRedirectsResponse
+
+	err := srv.Once(fmt.Sprintf("%s/%s", "redirects", req.DbName), func() (err error) {
+		store := &RedirectsStorage{
+			Dest:     fmt.Sprintf("export/%s/%s_json_redirects.tar.gz", req.DbName, req.DbName),
+			MetaDest: fmt.Sprintf("export/%s/%s_redirects.json", req.DbName, req.DbName),
+			Local:    srv.genStore,
+			Remote:   srv.remoteStore,
+		}
+
+		res, err = ExportRedirects(ctx, req, srv.repo, store)
+		return
+	})
+
+	return res, err
+}
+
+// Pack move page json files of the project from the filesystem layout into segment storage
+func (srv *Server) Pack(ctx context.Context, req *pb.PackRequest) (*pb.PackResponse, error) {
+	var res *pb.PackResponse
+
+	err := srv.Once(fmt.Sprintf("%s/%s", "pack", req.DbName), func() (err error) {
+		to, ok := srv.jsonStore.(*segment.Storage)
+
+		if !ok {
+			to = segment.NewStorage(env.JSONVol)
+			defer to.Close()
+		}
+
+		res, err = Pack(ctx, req, &PackStorage{From: fs.NewStorage(env.JSONVol), To: to})
+		return
+	})
+
+	return res, err
+}
+
+// MigratePaths move page json files of the project to the encoded title paths
+func (srv *Server) MigratePaths(ctx context.Context, req *pb.MigratePathsRequest) (*pb.MigratePathsResponse, error) {
+	var res *pb.MigratePathsResponse
+
+	err := srv.Once(fmt.Sprintf("%s/%s", "migratepaths", req.DbName), func() (err error) {
+		res, err = MigratePaths(ctx, req, srv.repo, &page.Storage{Local: srv.jsonStore, Remote: srv.remoteStore, Encoding: env.JSONEncoding})
+		return
+	})
+
+	return res, err
+}
+
+// Verify compare pages of the project in the database, local json storage and S3 and optionally repair the differences
+func (srv *Server) Verify(ctx context.Context, req *pb.VerifyRequest) (*pb.VerifyResponse, error) {
+	var res *pb.VerifyResponse
+
+	err := srv.Once(fmt.Sprintf("%s/%s", "verify", req.DbName), func() (err error) {
+		remote, ok := srv.remoteStore.(verifyRemote)
+
+		if !ok {
+			return ErrVerifyRemote
+		}
+
+		store := &VerifyStorage{
+			Local:  srv.jsonStore,
+			Remote: remote,
+		}
+
+		res, err = Verify(ctx, req, srv.repo, store, srv.cache)
+		return
+	})
+
+	return res, err
+}
+
+// Snapshot keep dated copy of the project export for diffs to be applied to and drop the old ones
+func (srv *Server) Snapshot(ctx context.Context, req *pb.SnapshotRequest) (*pb.SnapshotResponse, error) {
+	var res *pb.SnapshotResponse
+
+	err := srv.Once(fmt.Sprintf("%s/%s/%d", "snapshot", req.DbName, req.Ns), func() (err error) {
+		res, err = Snapshot(ctx, req, srv.remoteStore, time.Now().UTC().Format(dumpDateFormat))
+		return
+	})
+
+	return res, err
+}
+
+// dumpStorage storage for the dump files, local files are read only from the dump volume
+func (srv *Server) dumpStorage() *DumpStorage {
+	store := &DumpStorage{Remote: srv.remoteStore}
+
+	if len(env.DumpVol) > 0 {
+		store.Local = fs.NewStorage(env.DumpVol)
+	}
+
+	return store
+}
+
 // Init initialize new pages server
 func Init(srv grpc.ServiceRegistrar) {
+	var json PageStorage = fs.NewStorage(env.JSONVol)
+
+	if env.JSONStore == segment.Name {
+		json = segment.NewStorage(env.JSONVol)
+	}
+
 	pb.RegisterPagesServer(
 		srv,
 		NewBuilder().
-			RemoteStorage(s3.NewStorage(aws.Session(), env.AWSBucket)).
+			RemoteStorage(page.NewS3(aws.Session(), env.AWSBucket)).
+			MultipartStorage(multipart.NewS3(awss3.New(aws.Session()), env.AWSBucket)).
 			GenStorage(fs.NewStorage(env.GenVol)).
-			JSONStorage(fs.NewStorage(env.JSONVol)).
+			JSONStorage(json).
 			Repository(db.NewRepository(pg.Conn())).
 			Elastic(elastic.Client()).
 			Dumps(dumps.NewClient()).
+			Cache(redis.Client()).
 			Build())
 }
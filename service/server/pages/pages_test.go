@@ -65,6 +65,15 @@ func TestPages(t *testing.T) {
 
 	_, err = client.Copy(ctx, new(pb.CopyRequest))
 	assert.NoError(err)
+
+	_, err = client.History(ctx, new(pb.HistoryRequest))
+	assert.NoError(err)
+
+	_, err = client.Redact(ctx, new(pb.RedactRequest))
+	assert.Error(err)
+
+	_, err = client.ExportRedirects(ctx, new(pb.ExportRedirectsRequest))
+	assert.Error(err)
 }
 
 func TestMain(m *testing.M) {
@@ -0,0 +1,215 @@
+package pages
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Export profiles, each one is a set of page fields published as a separate export
+const (
+	ExportProfileFull     = "full"
+	ExportProfileMetadata = "metadata"
+	ExportProfileHTML     = "html"
+	ExportProfileWikitext = "wikitext"
+	ExportProfileText     = "text"
+)
+
+// ErrUnknownExportProfile export request asks for profile that doesn't exist
+var ErrUnknownExportProfile = errors.New("unknown export profile, available profiles: 'full', 'metadata', 'html', 'wikitext', 'text'")
+
+// ErrExportProfileFormat profiles other than full are only available for ndjson exports
+var ErrExportProfileFormat = errors.New("export profiles are only supported for ndjson format")
+
+// exportProfile page fields (by json name) kept in the export, nil keeps all of them
+type exportProfile struct {
+	fields map[string]bool
+	body   map[string]bool
+	text   bool // replace article body with plain text extracted from the html
+}
+
+// exportProfileIdentity fields that identify the page and its version, part of every profile
+var exportProfileIdentity = []string{"name", "identifier", "url", "date_modified", "version", "namespace", "in_language", "is_part_of"}
+
+var exportProfiles = map[string]*exportProfile{
+	ExportProfileFull:     {},
+	ExportProfileMetadata: {body: map[string]bool{}},
+	ExportProfileHTML:     newExportProfile("html"),
+	ExportProfileWikitext: newExportProfile("wikitext"),
+	ExportProfileText:     newExportTextProfile(),
+}
+
+// newExportProfile profile of page identity fields and article body fields
+func newExportProfile(body ...string) *exportProfile {
+	profile := &exportProfile{
+		fields: map[string]bool{"article_body": true},
+		body:   map[string]bool{},
+	}
+
+	for _, field := range exportProfileIdentity {
+		profile.fields[field] = true
+	}
+
+	for _, field := range body {
+		profile.body[field] = true
+	}
+
+	return profile
+}
+
+// newExportTextProfile profile of page identity fields and plain text of the article
+func newExportTextProfile() *exportProfile {
+	profile := newExportProfile("html")
+	profile.text = true
+	return profile
+}
+
+// getExportProfile find profile by name, empty name stands for the full profile
+func getExportProfile(name string) (*exportProfile, error) {
+	if len(name) == 0 {
+		name = ExportProfileFull
+	}
+
+	profile, ok := exportProfiles[name]
+
+	if !ok {
+		return nil, ErrUnknownExportProfile
+	}
+
+	return profile, nil
+}
+
+// project drop the page fields that are not part of the profile
+func (p *exportProfile) project(data []byte) ([]byte, error) {
+	if p.fields == nil && p.body == nil {
+		return data, nil
+	}
+
+	page := map[string]json.RawMessage{}
+
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, err
+	}
+
+	for field := range page {
+		if p.fields != nil && !p.fields[field] {
+			delete(page, field)
+		}
+	}
+
+	if body, ok := page["article_body"]; ok && p.body != nil {
+		if len(p.body) == 0 {
+			delete(page, "article_body")
+		} else {
+			fields := map[string]json.RawMessage{}
+
+			if err := json.Unmarshal(body, &fields); err != nil {
+				return nil, err
+			}
+
+			for field := range fields {
+				if !p.body[field] {
+					delete(fields, field)
+				}
+			}
+
+			if p.text {
+				markup := ""
+
+				if raw, ok := fields["html"]; ok {
+					if err := json.Unmarshal(raw, &markup); err != nil {
+						return nil, err
+					}
+				}
+
+				text, err := json.Marshal(exportText(markup))
+
+				if err != nil {
+					return nil, err
+				}
+
+				fields = map[string]json.RawMessage{"text": text}
+			}
+
+			body, err := json.Marshal(fields)
+
+			if err != nil {
+				return nil, err
+			}
+
+			page["article_body"] = body
+		}
+	}
+
+	return json.Marshal(page)
+}
+
+// exportTextSkip elements without readable text
+var exportTextSkip = map[string]bool{"head": true, "script": true, "style": true, "title": true}
+
+// exportTextRaw skipped elements that are read as raw text up to the end tag, even when self closing
+var exportTextRaw = map[string]bool{"script": true, "style": true, "title": true}
+
+// exportTextBlock elements that start a new line of the text
+var exportTextBlock = map[string]bool{
+	"blockquote": true, "br": true, "div": true, "li": true, "p": true, "pre": true, "section": true, "table": true, "tr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// exportTextCell elements separated from the previous text by space
+var exportTextCell = map[string]bool{"td": true, "th": true}
+
+// exportText strip the markup from the article html, blocks are separated by new lines
+func exportText(markup string) string {
+	lines := []string{}
+	line := new(strings.Builder)
+	skip := 0
+	tkn := html.NewTokenizer(strings.NewReader(markup))
+
+	flush := func() {
+		if text := strings.Join(strings.Fields(line.String()), " "); len(text) > 0 {
+			lines = append(lines, text)
+		}
+
+		line.Reset()
+	}
+
+	for {
+		switch tt := tkn.Next(); tt {
+		case html.ErrorToken:
+			flush()
+			return strings.Join(lines, "\n")
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := tkn.TagName()
+
+			// self closing element has no end tag, unless tokenizer reads it as raw text up to the end tag
+			if exportTextSkip[string(name)] && (tt == html.StartTagToken || exportTextRaw[string(name)]) {
+				skip++
+			}
+
+			if exportTextBlock[string(name)] {
+				flush()
+			}
+
+			if exportTextCell[string(name)] {
+				_ = line.WriteByte(' ')
+			}
+		case html.EndTagToken:
+			name, _ := tkn.TagName()
+
+			if exportTextSkip[string(name)] && skip > 0 {
+				skip--
+			}
+
+			if exportTextBlock[string(name)] {
+				flush()
+			}
+		case html.TextToken:
+			if skip == 0 {
+				_, _ = line.Write(tkn.Text())
+			}
+		}
+	}
+}
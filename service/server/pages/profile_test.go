@@ -0,0 +1,118 @@
+package pages
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	pb "okapi-data-service/server/pages/protos"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const profileTestPage = `{"name":"Earth","identifier":9228,"url":"https://en.wikipedia.org/wiki/Earth","version":{"identifier":1,"comment":"fix"},"namespace":{"identifier":0},"categories":[{"name":"Category:Planets"}],"article_body":{"html":"<p>Earth</p>","wikitext":"Earth"}}`
+
+func TestExportProfile(t *testing.T) {
+	assert := assert.New(t)
+
+	decode := func(data []byte) map[string]interface{} {
+		page := map[string]interface{}{}
+		assert.NoError(json.Unmarshal(data, &page))
+		return page
+	}
+
+	t.Run("full profile", func(t *testing.T) {
+		for _, name := range []string{"", ExportProfileFull} {
+			profile, err := getExportProfile(name)
+			assert.NoError(err)
+
+			data, err := profile.project([]byte(profileTestPage))
+			assert.NoError(err)
+			assert.Equal(profileTestPage, string(data))
+		}
+	})
+
+	t.Run("metadata profile", func(t *testing.T) {
+		profile, err := getExportProfile(ExportProfileMetadata)
+		assert.NoError(err)
+
+		data, err := profile.project([]byte(profileTestPage))
+		assert.NoError(err)
+
+		page := decode(data)
+		assert.NotContains(page, "article_body")
+		assert.Contains(page, "categories")
+		assert.Contains(page, "version")
+	})
+
+	t.Run("body profiles", func(t *testing.T) {
+		for name, body := range map[string][]string{
+			ExportProfileHTML:     {"html"},
+			ExportProfileWikitext: {"wikitext"},
+			ExportProfileText:     {"text"},
+		} {
+			profile, err := getExportProfile(name)
+			assert.NoError(err)
+
+			data, err := profile.project([]byte(profileTestPage))
+			assert.NoError(err)
+
+			page := decode(data)
+			assert.Equal("Earth", page["name"])
+			assert.Contains(page, "version")
+			assert.NotContains(page, "categories")
+			assert.Len(page["article_body"], len(body))
+
+			for _, field := range body {
+				assert.Contains(page["article_body"], field)
+			}
+		}
+	})
+
+	t.Run("text profile", func(t *testing.T) {
+		profile, err := getExportProfile(ExportProfileText)
+		assert.NoError(err)
+
+		data, err := profile.project([]byte(profileTestPage))
+		assert.NoError(err)
+
+		page := decode(data)
+		assert.Equal(map[string]interface{}{"text": "Earth"}, page["article_body"])
+	})
+
+	t.Run("plain text", func(t *testing.T) {
+		markup := `<html><head><title>Earth</title><style>.a{}</style></head><body>` +
+			`<h2>Orbit</h2><p>Earth is the <b>third</b>  planet<sup>[1]</sup>.</p><script>x()</script>` +
+			`<ul><li>Moon</li><li>Sun</li></ul>Rock<br/>Water<table><tr><th>Mass</th><td>5.97</td></tr></table></body></html>`
+		assert.Equal("Orbit\nEarth is the third planet[1].\nMoon\nSun\nRock\nWater\nMass 5.97", exportText(markup))
+		assert.Empty(exportText(""))
+	})
+
+	t.Run("plain text self closing skipped element", func(t *testing.T) {
+		assert.Equal("Earth\nMoon", exportText(`<head/><p>Earth</p><p>Moon</p>`))
+		assert.Equal("Earth\nMoon", exportText(`<p>Earth</p><style/>.a{}</style><p>Moon</p>`))
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := getExportProfile("images")
+		assert.Equal(ErrUnknownExportProfile, err)
+	})
+
+	t.Run("export unknown profile", func(t *testing.T) {
+		_, err := Export(context.Background(), &pb.ExportRequest{Profile: "images"}, nil, nil)
+		assert.Equal(ErrUnknownExportProfile, err)
+	})
+
+	t.Run("parquet export with profile", func(t *testing.T) {
+		_, err := ExportParquet(context.Background(), &pb.ExportRequest{Profile: ExportProfileHTML}, nil, nil)
+		assert.Equal(ErrExportProfileFormat, err)
+	})
+
+	t.Run("invalid page", func(t *testing.T) {
+		profile, err := getExportProfile(ExportProfileHTML)
+		assert.NoError(err)
+
+		_, err = profile.project([]byte("{"))
+		assert.Error(err)
+	})
+}
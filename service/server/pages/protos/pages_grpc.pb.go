@@ -26,6 +26,13 @@ type PagesClient interface {
 	Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*FetchResponse, error)
 	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (*ExportResponse, error)
 	Copy(ctx context.Context, in *CopyRequest, opts ...grpc.CallOption) (*CopyResponse, error)
+	History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error)
+	Redact(ctx context.Context, in *RedactRequest, opts ...grpc.CallOption) (*RedactResponse, error)
+	ExportRedirects(ctx context.Context, in *ExportRedirectsRequest, opts ...grpc.CallOption) (*ExportRedirectsResponse, error)
+	Pack(ctx context.Context, in *PackRequest, opts ...grpc.CallOption) (*PackResponse, error)
+	MigratePaths(ctx context.Context, in *MigratePathsRequest, opts ...grpc.CallOption) (*MigratePathsResponse, error)
+	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
+	Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotResponse, error)
 }
 
 type pagesClient struct {
@@ -72,6 +79,69 @@ func (c *pagesClient) Copy(ctx context.Context, in *CopyRequest, opts ...grpc.Ca
 	return out, nil
 }
 
+func (c *pagesClient) History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error) {
+	out := new(HistoryResponse)
+	err := c.cc.Invoke(ctx, "/pages.Pages/History", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pagesClient) Redact(ctx context.Context, in *RedactRequest, opts ...grpc.CallOption) (*RedactResponse, error) {
+	out := new(RedactResponse)
+	err := c.cc.Invoke(ctx, "/pages.Pages/Redact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pagesClient) ExportRedirects(ctx context.Context, in *ExportRedirectsRequest, opts ...grpc.CallOption) (*ExportRedirectsResponse, error) {
+	out := new(ExportRedirectsResponse)
+	err := c.cc.Invoke(ctx, "/pages.Pages/ExportRedirects", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pagesClient) Pack(ctx context.Context, in *PackRequest, opts ...grpc.CallOption) (*PackResponse, error) {
+	out := new(PackResponse)
+	err := c.cc.Invoke(ctx, "/pages.Pages/Pack", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pagesClient) MigratePaths(ctx context.Context, in *MigratePathsRequest, opts ...grpc.CallOption) (*MigratePathsResponse, error) {
+	out := new(MigratePathsResponse)
+	err := c.cc.Invoke(ctx, "/pages.Pages/MigratePaths", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pagesClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error) {
+	out := new(VerifyResponse)
+	err := c.cc.Invoke(ctx, "/pages.Pages/Verify", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pagesClient) Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotResponse, error) {
+	out := new(SnapshotResponse)
+	err := c.cc.Invoke(ctx, "/pages.Pages/Snapshot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PagesServer is the server API for Pages service.
 // All implementations must embed UnimplementedPagesServer
 // for forward compatibility
@@ -80,6 +150,13 @@ type PagesServer interface {
 	Fetch(context.Context, *FetchRequest) (*FetchResponse, error)
 	Export(context.Context, *ExportRequest) (*ExportResponse, error)
 	Copy(context.Context, *CopyRequest) (*CopyResponse, error)
+	History(context.Context, *HistoryRequest) (*HistoryResponse, error)
+	Redact(context.Context, *RedactRequest) (*RedactResponse, error)
+	ExportRedirects(context.Context, *ExportRedirectsRequest) (*ExportRedirectsResponse, error)
+	Pack(context.Context, *PackRequest) (*PackResponse, error)
+	MigratePaths(context.Context, *MigratePathsRequest) (*MigratePathsResponse, error)
+	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
+	Snapshot(context.Context, *SnapshotRequest) (*SnapshotResponse, error)
 	mustEmbedUnimplementedPagesServer()
 }
 
@@ -99,6 +176,27 @@ func (UnimplementedPagesServer) Export(context.Context, *ExportRequest) (*Export
 func (UnimplementedPagesServer) Copy(context.Context, *CopyRequest) (*CopyResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Copy not implemented")
 }
+func (UnimplementedPagesServer) History(context.Context, *HistoryRequest) (*HistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method History not implemented")
+}
+func (UnimplementedPagesServer) Redact(context.Context, *RedactRequest) (*RedactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Redact not implemented")
+}
+func (UnimplementedPagesServer) ExportRedirects(context.Context, *ExportRedirectsRequest) (*ExportRedirectsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportRedirects not implemented")
+}
+func (UnimplementedPagesServer) Pack(context.Context, *PackRequest) (*PackResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pack not implemented")
+}
+func (UnimplementedPagesServer) MigratePaths(context.Context, *MigratePathsRequest) (*MigratePathsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MigratePaths not implemented")
+}
+func (UnimplementedPagesServer) Verify(context.Context, *VerifyRequest) (*VerifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Verify not implemented")
+}
+func (UnimplementedPagesServer) Snapshot(context.Context, *SnapshotRequest) (*SnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Snapshot not implemented")
+}
 func (UnimplementedPagesServer) mustEmbedUnimplementedPagesServer() {}
 
 // UnsafePagesServer may be embedded to opt out of forward compatibility for this service.
@@ -184,6 +282,132 @@ func _Pages_Copy_Handler(srv interface{}, ctx context.Context, dec func(interfac
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Pages_History_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PagesServer).History(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pages.Pages/History",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PagesServer).History(ctx, req.(*HistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Pages_Redact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RedactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PagesServer).Redact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pages.Pages/Redact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PagesServer).Redact(ctx, req.(*RedactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Pages_ExportRedirects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportRedirectsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PagesServer).ExportRedirects(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pages.Pages/ExportRedirects",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PagesServer).ExportRedirects(ctx, req.(*ExportRedirectsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Pages_Pack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PagesServer).Pack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pages.Pages/Pack",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PagesServer).Pack(ctx, req.(*PackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Pages_MigratePaths_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigratePathsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PagesServer).MigratePaths(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pages.Pages/MigratePaths",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PagesServer).MigratePaths(ctx, req.(*MigratePathsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Pages_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PagesServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pages.Pages/Verify",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PagesServer).Verify(ctx, req.(*VerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Pages_Snapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PagesServer).Snapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pages.Pages/Snapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PagesServer).Snapshot(ctx, req.(*SnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Pages_ServiceDesc is the grpc.ServiceDesc for Pages service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -207,6 +431,34 @@ var Pages_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Copy",
 			Handler:    _Pages_Copy_Handler,
 		},
+		{
+			MethodName: "History",
+			Handler:    _Pages_History_Handler,
+		},
+		{
+			MethodName: "Redact",
+			Handler:    _Pages_Redact_Handler,
+		},
+		{
+			MethodName: "ExportRedirects",
+			Handler:    _Pages_ExportRedirects_Handler,
+		},
+		{
+			MethodName: "Pack",
+			Handler:    _Pages_Pack_Handler,
+		},
+		{
+			MethodName: "MigratePaths",
+			Handler:    _Pages_MigratePaths_Handler,
+		},
+		{
+			MethodName: "Verify",
+			Handler:    _Pages_Verify_Handler,
+		},
+		{
+			MethodName: "Snapshot",
+			Handler:    _Pages_Snapshot_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "protos/pages.proto",
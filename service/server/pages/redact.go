@@ -0,0 +1,434 @@
+package pages
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5" // #nosec G501
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"okapi-data-service/models"
+	"okapi-data-service/pkg/checksum"
+	"okapi-data-service/pkg/page"
+	"okapi-data-service/schema/v3"
+	pb "okapi-data-service/server/pages/protos"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/klauspost/pgzip"
+	"github.com/protsack-stephan/dev-toolkit/pkg/repository"
+	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
+)
+
+// ErrRedactRemote remote storage can't list all the published archives
+var ErrRedactRemote = errors.New("remote storage doesn't support listing of all keys")
+
+// RedactStorage storage to patch published archives in
+type RedactStorage struct {
+	Local interface {
+		storage.Getter
+		storage.Creator
+		storage.Deleter
+	}
+	Remote redactRemote
+}
+
+type redactRemote interface {
+	page.Walker
+	storage.Lister
+	storage.Getter
+	storage.Putter
+}
+
+type redactRepo interface {
+	repository.Finder
+	repository.Updater
+}
+
+type redactRevision struct {
+	Version *struct {
+		Identifier int `json:"identifier"`
+	} `json:"version"`
+}
+
+// Redact remove suppressed revisions from already published export, snapshot and diff archives of the project
+// and update the archives metadata, every fixed archive is recorded in the redaction
+func Redact(ctx context.Context, req *pb.RedactRequest, repo redactRepo, store *RedactStorage) (*pb.RedactResponse, error) {
+	reds := []*models.Redaction{}
+	err := repo.Find(ctx, &reds, func(q *orm.Query) *orm.Query {
+		return q.
+			Where("db_name = ? and applied_at is null", req.DbName).
+			Order("id asc")
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(pb.RedactResponse)
+
+	if len(reds) == 0 {
+		return res, nil
+	}
+
+	revs := map[int]*models.Redaction{}
+
+	for _, red := range reds {
+		revs[red.Revision] = red
+	}
+
+	paths, err := redactPaths(store, req.DbName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		res.Total++
+		archive, err := redactArchive(store, path, revs)
+
+		if err != nil {
+			res.Errors++
+			log.Printf("path: %s, err: %v", path, err)
+			continue
+		}
+
+		if archive == nil {
+			continue
+		}
+
+		res.Archives = append(res.Archives, archive)
+		dt := time.Now().UTC()
+
+		for _, rev := range archive.Revisions {
+			revs[int(rev)].AddArtifact(archive.Path, archive.Version, dt)
+		}
+	}
+
+	for _, red := range reds {
+		columns := []string{"artifacts", "updated_at"}
+
+		// archives that failed will be picked up on the next run
+		if res.Errors == 0 {
+			dt := time.Now().UTC()
+			red.AppliedAt = &dt
+			columns = append(columns, "applied_at")
+		}
+
+		_, err := repo.Update(ctx, red, func(q *orm.Query) *orm.Query {
+			return q.Column(columns...).WherePK()
+		})
+
+		if err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
+
+// redactPaths list all published export (including parquet), snapshot and diff archives of the project
+func redactPaths(store *RedactStorage, dbName string) ([]string, error) {
+	paths := []string{}
+	collect := func(path string) {
+		if strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".parquet") {
+			paths = append(paths, path)
+		}
+	}
+
+	for _, prefix := range []string{"export", "snapshot"} {
+		if err := store.Remote.WalkAll(fmt.Sprintf("%s/%s/", prefix, dbName), collect); err != nil {
+			return nil, err
+		}
+	}
+
+	dates, err := store.Remote.List("diff/", map[string]interface{}{"delimiter": "/"})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, date := range dates {
+		if err := store.Remote.WalkAll(fmt.Sprintf("diff/%s/%s/", date, dbName), collect); err != nil {
+			return nil, err
+		}
+	}
+
+	return paths, nil
+}
+
+// redactArchive rewrite the archive without suppressed revisions and update its metadata,
+// returns nil if the archive did not contain any of them
+func redactArchive(store *RedactStorage, path string, revs map[int]*models.Redaction) (*pb.RedactedArchive, error) {
+	src, err := store.Remote.Get(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer src.Close()
+
+	dest := fmt.Sprintf("tmp/redact/%s", path)
+	rewrite := redactTar
+
+	if strings.HasSuffix(path, ".parquet") {
+		rewrite = redactParquet
+	}
+
+	found, chunks, err := rewrite(store, dest, src, revs)
+
+	defer func() {
+		if err := store.Local.Delete(dest); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
+
+	archive, err := store.Local.Get(dest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer archive.Close()
+
+	// generate body md5 hash and sha256 for the manifest while uploading
+	h := md5.New() // #nosec G401
+	sum := checksum.NewWriter()
+	if err := store.Remote.Put(path, io.TeeReader(archive, io.MultiWriter(h, sum))); err != nil {
+		return nil, err
+	}
+
+	res := &pb.RedactedArchive{
+		Path:    path,
+		Version: fmt.Sprintf("%x", h.Sum(nil)),
+	}
+
+	for rev := range found {
+		res.Revisions = append(res.Revisions, int32(rev))
+	}
+
+	sort.Slice(res.Revisions, func(i, j int) bool {
+		return res.Revisions[i] < res.Revisions[j]
+	})
+
+	return res, redactMeta(store, redactMetaPath(path), res.Version, sum, chunks)
+}
+
+// redactTar copy tar.gz archive into local storage without lines that belong to suppressed revisions,
+// returns found revisions and the checksums of the rewritten archive files
+func redactTar(store *RedactStorage, dest string, src io.Reader, revs map[int]*models.Redaction) (map[int]bool, []*schema.Chunk, error) {
+	gzr, err := pgzip.NewReader(src)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer gzr.Close()
+
+	file, err := store.Local.Create(dest)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if file == nil {
+		return nil, nil, ErrExportFileIsNil
+	}
+
+	defer file.Close()
+
+	found := map[int]bool{}
+	chunks := []*schema.Chunk{}
+	gzw := pgzip.NewWriter(file)
+	tarbal := tar.NewWriter(gzw)
+	tarr := tar.NewReader(gzr)
+
+	for {
+		header, err := tarr.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		entry := fmt.Sprintf("%s_%s", dest, header.Name)
+		header.Size, err = redactEntry(store, entry, tarr, revs, found)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		chunk, err := redactCopy(store, entry, header, tarbal)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		chunks = append(chunks, chunk)
+	}
+
+	if err := tarbal.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	return found, chunks, gzw.Close()
+}
+
+// redactEntry write ndjson entry into local storage without suppressed revisions and return its size
+func redactEntry(store *RedactStorage, path string, src io.Reader, revs map[int]*models.Redaction, found map[int]bool) (int64, error) {
+	file, err := store.Local.Create(path)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if file == nil {
+		return 0, ErrExportFileIsNil
+	}
+
+	defer file.Close()
+
+	size := int64(0)
+	rdr := bufio.NewReader(src)
+
+	for {
+		line, err := rdr.ReadBytes('\n')
+
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		if len(line) > 0 && !isRedacted(line, revs, found) {
+			n, err := file.Write(line)
+
+			if err != nil {
+				return 0, err
+			}
+
+			size += int64(n)
+		}
+
+		if err == io.EOF {
+			return size, nil
+		}
+	}
+}
+
+// redactCopy move redacted entry from local storage into the tar archive and get its checksum
+func redactCopy(store *RedactStorage, path string, header *tar.Header, tarbal *tar.Writer) (*schema.Chunk, error) {
+	defer func() {
+		if err := store.Local.Delete(path); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	entry, err := store.Local.Get(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer entry.Close()
+
+	if err := tarbal.WriteHeader(header); err != nil {
+		return nil, err
+	}
+
+	sum := checksum.NewWriter()
+
+	if _, err := io.Copy(io.MultiWriter(tarbal, sum), entry); err != nil {
+		return nil, err
+	}
+
+	return &schema.Chunk{
+		Name:   header.Name,
+		SHA256: sum.Sum(),
+		Size:   sum.Size(),
+		Lines:  sum.Lines(),
+	}, nil
+}
+
+// redactMeta update version, size and manifest of the archive metadata
+func redactMeta(store *RedactStorage, path string, version string, sum *checksum.Writer, chunks []*schema.Chunk) error {
+	mrc, err := store.Remote.Get(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer mrc.Close()
+
+	meta := new(schema.Project)
+
+	if err := json.NewDecoder(mrc).Decode(meta); err != nil {
+		return err
+	}
+
+	datetime := time.Now().UTC()
+	meta.Version = &version
+	meta.DateModified = &datetime
+	meta.Size = &schema.Size{
+		Value:    math.Round((((float64)(sum.Size())/1024)/1024)*100) / 100,
+		UnitText: "MB",
+	}
+	meta.Manifest = &schema.Manifest{
+		SHA256: sum.Sum(),
+		Chunks: chunks,
+	}
+
+	data, err := json.Marshal(meta)
+
+	if err != nil {
+		return err
+	}
+
+	return store.Remote.Put(path, bytes.NewReader(data))
+}
+
+// redactMetaPath get path to the archive metadata
+// e.g., export/enwiki/enwiki_group_1_json_0.tar.gz -> export/enwiki/enwiki_group_1_0.json
+// snapshot/enwiki/2021-01-01/enwiki_json_0.tar.gz -> snapshot/enwiki/2021-01-01/enwiki_0.json
+// diff/2021-01-01/enwiki/enwiki_json_0.tar.gz -> diff/2021-01-01/enwiki/enwiki_json_0.json
+// export/enwiki/enwiki_parquet_0.parquet -> export/enwiki/enwiki_parquet_0.json
+func redactMetaPath(path string) string {
+	if strings.HasSuffix(path, ".parquet") {
+		return fmt.Sprintf("%s.json", strings.TrimSuffix(path, ".parquet"))
+	}
+
+	meta := fmt.Sprintf("%s.json", strings.TrimSuffix(path, ".tar.gz"))
+
+	if i := strings.LastIndex(meta, "_json_"); !strings.HasPrefix(meta, "diff/") && i != -1 {
+		meta = meta[:i] + meta[i+len("_json"):]
+	}
+
+	return meta
+}
+
+func isRedacted(line []byte, revs map[int]*models.Redaction, found map[int]bool) bool {
+	page := new(redactRevision)
+
+	if err := json.Unmarshal(line, page); err != nil || page.Version == nil {
+		return false
+	}
+
+	if _, ok := revs[page.Version.Identifier]; ok {
+		found[page.Version.Identifier] = true
+		return true
+	}
+
+	return false
+}
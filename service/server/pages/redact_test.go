@@ -0,0 +1,363 @@
+package pages
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5" // #nosec G501
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"okapi-data-service/models"
+	"okapi-data-service/schema/v3"
+	pb "okapi-data-service/server/pages/protos"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/protsack-stephan/dev-toolkit/lib/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+const redactTestDbName = "enwiki"
+const redactTestDate = "2026-10-18"
+const redactTestRev = 2
+const redactTestPageSize = 2
+
+var redactTestExport = fmt.Sprintf("export/%s/%s_json_0.tar.gz", redactTestDbName, redactTestDbName)
+var redactTestExportMeta = fmt.Sprintf("export/%s/%s_0.json", redactTestDbName, redactTestDbName)
+var redactTestDiff = fmt.Sprintf("diff/%s/%s/%s_json_0.tar.gz", redactTestDate, redactTestDbName, redactTestDbName)
+var redactTestDiffMeta = fmt.Sprintf("diff/%s/%s/%s_json_0.json", redactTestDate, redactTestDbName, redactTestDbName)
+var redactTestParquet = fmt.Sprintf("export/%s/%s_parquet_0.parquet", redactTestDbName, redactTestDbName)
+var redactTestParquetMeta = fmt.Sprintf("export/%s/%s_parquet_0.json", redactTestDbName, redactTestDbName)
+var redactTestSnapshot = snapshotDest(redactTestDbName, 0, redactTestDate)
+var redactTestSnapshotMeta = snapshotMeta(redactTestDbName, 0, redactTestDate)
+
+type redactRepoMock struct {
+	mock.Mock
+	reds []*models.Redaction
+}
+
+func (r *redactRepoMock) Find(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, _ ...interface{}) error {
+	switch model := model.(type) {
+	case *[]*models.Redaction:
+		*model = append(*model, r.reds...)
+	}
+
+	return r.Called(model).Error(0)
+}
+
+func (r *redactRepoMock) Update(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, _ ...interface{}) (orm.Result, error) {
+	return nil, r.Called(model).Error(0)
+}
+
+// redactRemoteMock remote storage that lists keys in pages of redactTestPageSize, like s3 does with max keys
+type redactRemoteMock struct {
+	files map[string][]byte
+	pages int
+}
+
+func (s *redactRemoteMock) List(path string, _ ...map[string]interface{}) ([]string, error) {
+	names := map[string]bool{}
+	res := []string{}
+
+	for key := range s.files {
+		if strings.HasPrefix(key, path) {
+			name := strings.Split(strings.TrimPrefix(key, path), "/")[0]
+
+			if !names[name] {
+				names[name] = true
+				res = append(res, name)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (s *redactRemoteMock) WalkAll(prefix string, callback func(key string)) error {
+	keys := []string{}
+
+	for key := range s.files {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for i := 0; i < len(keys); i += redactTestPageSize {
+		s.pages++
+
+		for j := i; j < len(keys) && j < i+redactTestPageSize; j++ {
+			callback(keys[j])
+		}
+	}
+
+	return nil
+}
+
+func (s *redactRemoteMock) Get(path string) (io.ReadCloser, error) {
+	if data, ok := s.files[path]; ok {
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	return nil, errors.New("file not found")
+}
+
+func (s *redactRemoteMock) Put(path string, body io.Reader) error {
+	data, err := ioutil.ReadAll(body)
+	s.files[path] = data
+	return err
+}
+
+func createRedactTestArchive(revs ...int) []byte {
+	buf := new(bytes.Buffer)
+	gzw := gzip.NewWriter(buf)
+	tarbal := tar.NewWriter(gzw)
+	lines := []string{}
+
+	for _, rev := range revs {
+		lines = append(lines, fmt.Sprintf(`{"name":"Page %d","version":{"identifier":%d}}`, rev, rev))
+	}
+
+	data := []byte(strings.Join(lines, "\n") + "\n")
+	_ = tarbal.WriteHeader(&tar.Header{
+		Name: fmt.Sprintf("%s_0.ndjson", redactTestDbName),
+		Size: int64(len(data)),
+		Mode: 0766,
+	})
+	_, _ = tarbal.Write(data)
+	_ = tarbal.Close()
+	_ = gzw.Close()
+
+	return buf.Bytes()
+}
+
+func createRedactTestParquet(t *testing.T, revs ...int) []byte {
+	buf := new(bytes.Buffer)
+	pw, err := writer.NewParquetWriterFromWriter(buf, new(ExportParquetPage), 1)
+	assert.NoError(t, err)
+
+	for _, rev := range revs {
+		assert.NoError(t, pw.Write(&ExportParquetPage{
+			Name:    fmt.Sprintf("Page %d", rev),
+			Version: &ExportParquetVersion{Identifier: int64(rev)},
+		}))
+	}
+
+	assert.NoError(t, pw.WriteStop())
+	return buf.Bytes()
+}
+
+func readRedactTestParquet(t *testing.T, data []byte) []int64 {
+	pr, err := reader.NewParquetReader(&exportParquetTestFile{bytes.NewReader(data), data}, new(ExportParquetPage), 1)
+	assert.NoError(t, err)
+	defer pr.ReadStop()
+
+	rows := make([]ExportParquetPage, pr.GetNumRows())
+	assert.NoError(t, pr.Read(&rows))
+	revs := []int64{}
+
+	for _, row := range rows {
+		revs = append(revs, row.Version.Identifier)
+	}
+
+	return revs
+}
+
+func readRedactTestArchive(t *testing.T, data []byte) string {
+	gzr, err := gzip.NewReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	tarr := tar.NewReader(gzr)
+	_, err = tarr.Next()
+	assert.NoError(t, err)
+
+	body, err := ioutil.ReadAll(tarr)
+	assert.NoError(t, err)
+
+	return string(body)
+}
+
+func TestRedact(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	req := &pb.RedactRequest{DbName: redactTestDbName}
+
+	newRemote := func() *redactRemoteMock {
+		return &redactRemoteMock{
+			files: map[string][]byte{
+				redactTestExport:     createRedactTestArchive(1, redactTestRev, 3),
+				redactTestExportMeta: []byte(`{"name":"Wikipedia","identifier":"enwiki","version":"old"}`),
+				redactTestDiff:       createRedactTestArchive(3),
+				redactTestDiffMeta:   []byte(`{"name":"Wikipedia","identifier":"enwiki","version":"old"}`),
+			},
+		}
+	}
+
+	t.Run("redact success", func(t *testing.T) {
+		red := &models.Redaction{DbName: redactTestDbName, Title: "Page_2", Revision: redactTestRev}
+		repo := &redactRepoMock{reds: []*models.Redaction{red}}
+		repo.On("Find", mock.Anything).Return(nil)
+		repo.On("Update", red).Return(nil)
+		remote := newRemote()
+		diff := remote.files[redactTestDiff]
+
+		res, err := Redact(ctx, req, repo, &RedactStorage{Local: fs.NewStorage(t.TempDir()), Remote: remote})
+		assert.NoError(err)
+		assert.Equal(int32(2), res.Total)
+		assert.Zero(res.Errors)
+		assert.Len(res.Archives, 1)
+		assert.Equal(redactTestExport, res.Archives[0].Path)
+		assert.Equal([]int32{redactTestRev}, res.Archives[0].Revisions)
+		assert.Equal(fmt.Sprintf("%x", md5.Sum(remote.files[redactTestExport])), res.Archives[0].Version) // #nosec G401
+
+		body := readRedactTestArchive(t, remote.files[redactTestExport])
+		assert.Contains(body, `"identifier":1}`)
+		assert.Contains(body, `"identifier":3}`)
+		assert.NotContains(body, fmt.Sprintf(`"identifier":%d}`, redactTestRev))
+		assert.Equal(diff, remote.files[redactTestDiff])
+
+		meta := new(schema.Project)
+		assert.NoError(json.Unmarshal(remote.files[redactTestExportMeta], meta))
+		assert.Equal(res.Archives[0].Version, *meta.Version)
+		assert.NotNil(meta.Size)
+		assert.NotNil(meta.Manifest)
+		assert.Equal(fmt.Sprintf("%x", sha256.Sum256(remote.files[redactTestExport])), meta.Manifest.SHA256)
+		assert.Len(meta.Manifest.Chunks, 1)
+		assert.Equal(fmt.Sprintf("%s_0.ndjson", redactTestDbName), meta.Manifest.Chunks[0].Name)
+		assert.Equal(fmt.Sprintf("%x", sha256.Sum256([]byte(body))), meta.Manifest.Chunks[0].SHA256)
+		assert.Equal(int64(len(body)), meta.Manifest.Chunks[0].Size)
+		assert.Equal(2, meta.Manifest.Chunks[0].Lines)
+
+		assert.NotNil(red.AppliedAt)
+		assert.Len(red.Artifacts, 1)
+		assert.Equal(redactTestExport, red.Artifacts[0].Path)
+		assert.Equal(res.Archives[0].Version, red.Artifacts[0].Version)
+		repo.AssertNumberOfCalls(t, "Update", 1)
+	})
+
+	t.Run("redact snapshot", func(t *testing.T) {
+		red := &models.Redaction{DbName: redactTestDbName, Title: "Page_2", Revision: redactTestRev}
+		repo := &redactRepoMock{reds: []*models.Redaction{red}}
+		repo.On("Find", mock.Anything).Return(nil)
+		repo.On("Update", red).Return(nil)
+		remote := newRemote()
+		remote.files[redactTestSnapshot] = createRedactTestArchive(redactTestRev, 3)
+		remote.files[redactTestSnapshotMeta] = []byte(`{"name":"Wikipedia","identifier":"enwiki","version":"old"}`)
+
+		res, err := Redact(ctx, req, repo, &RedactStorage{Local: fs.NewStorage(t.TempDir()), Remote: remote})
+		assert.NoError(err)
+		assert.Equal(int32(3), res.Total)
+		assert.Zero(res.Errors)
+		assert.Len(res.Archives, 2)
+		assert.NotContains(readRedactTestArchive(t, remote.files[redactTestSnapshot]), fmt.Sprintf(`"identifier":%d}`, redactTestRev))
+
+		meta := new(schema.Project)
+		assert.NoError(json.Unmarshal(remote.files[redactTestSnapshotMeta], meta))
+		assert.Equal(fmt.Sprintf("%x", md5.Sum(remote.files[redactTestSnapshot])), *meta.Version) // #nosec G401
+		assert.Len(red.Artifacts, 2)
+	})
+
+	t.Run("redact parquet", func(t *testing.T) {
+		red := &models.Redaction{DbName: redactTestDbName, Title: "Page_2", Revision: redactTestRev}
+		repo := &redactRepoMock{reds: []*models.Redaction{red}}
+		repo.On("Find", mock.Anything).Return(nil)
+		repo.On("Update", red).Return(nil)
+		remote := newRemote()
+		remote.files[redactTestParquet] = createRedactTestParquet(t, 1, redactTestRev, 3)
+		remote.files[redactTestParquetMeta] = []byte(`{"name":"Wikipedia","identifier":"enwiki","version":"old","encoding_format":"parquet"}`)
+
+		res, err := Redact(ctx, req, repo, &RedactStorage{Local: fs.NewStorage(t.TempDir()), Remote: remote})
+		assert.NoError(err)
+		assert.Equal(int32(3), res.Total)
+		assert.Zero(res.Errors)
+		assert.Len(res.Archives, 2)
+		assert.Equal([]int64{1, 3}, readRedactTestParquet(t, remote.files[redactTestParquet]))
+
+		meta := new(schema.Project)
+		assert.NoError(json.Unmarshal(remote.files[redactTestParquetMeta], meta))
+		assert.Equal(fmt.Sprintf("%x", md5.Sum(remote.files[redactTestParquet])), *meta.Version) // #nosec G401
+		assert.Equal(fmt.Sprintf("%x", sha256.Sum256(remote.files[redactTestParquet])), meta.Manifest.SHA256)
+		assert.Empty(meta.Manifest.Chunks)
+		assert.Equal("parquet", meta.EncodingFormat)
+	})
+
+	t.Run("redact more than one listing page", func(t *testing.T) {
+		red := &models.Redaction{DbName: redactTestDbName, Title: "Page_2", Revision: redactTestRev}
+		repo := &redactRepoMock{reds: []*models.Redaction{red}}
+		repo.On("Find", mock.Anything).Return(nil)
+		repo.On("Update", red).Return(nil)
+		remote := newRemote()
+
+		for num := 1; num <= 3; num++ {
+			remote.files[fmt.Sprintf("export/%s/%s_json_%d.tar.gz", redactTestDbName, redactTestDbName, num)] = createRedactTestArchive(redactTestRev)
+			remote.files[fmt.Sprintf("export/%s/%s_%d.json", redactTestDbName, redactTestDbName, num)] = []byte(`{"name":"Wikipedia","identifier":"enwiki","version":"old"}`)
+		}
+
+		res, err := Redact(ctx, req, repo, &RedactStorage{Local: fs.NewStorage(t.TempDir()), Remote: remote})
+		assert.NoError(err)
+		assert.Zero(res.Errors)
+		assert.Len(res.Archives, 4)
+		assert.Len(red.Artifacts, 4)
+		assert.Greater(remote.pages, 2)
+
+		for num := 1; num <= 3; num++ {
+			path := fmt.Sprintf("export/%s/%s_json_%d.tar.gz", redactTestDbName, redactTestDbName, num)
+			assert.NotContains(readRedactTestArchive(t, remote.files[path]), fmt.Sprintf(`"identifier":%d}`, redactTestRev))
+		}
+	})
+
+	t.Run("redact nothing to apply", func(t *testing.T) {
+		repo := new(redactRepoMock)
+		repo.On("Find", mock.Anything).Return(nil)
+
+		res, err := Redact(ctx, req, repo, &RedactStorage{Local: fs.NewStorage(t.TempDir()), Remote: newRemote()})
+		assert.NoError(err)
+		assert.Zero(res.Total)
+		repo.AssertNumberOfCalls(t, "Update", 0)
+	})
+
+	t.Run("redact archive error", func(t *testing.T) {
+		red := &models.Redaction{DbName: redactTestDbName, Title: "Page_2", Revision: redactTestRev}
+		repo := &redactRepoMock{reds: []*models.Redaction{red}}
+		repo.On("Find", mock.Anything).Return(nil)
+		repo.On("Update", red).Return(nil)
+		remote := newRemote()
+		delete(remote.files, redactTestExportMeta)
+
+		res, err := Redact(ctx, req, repo, &RedactStorage{Local: fs.NewStorage(t.TempDir()), Remote: remote})
+		assert.NoError(err)
+		assert.Equal(int32(1), res.Errors)
+		assert.Nil(red.AppliedAt)
+		repo.AssertNumberOfCalls(t, "Update", 1)
+	})
+
+	t.Run("redact find error", func(t *testing.T) {
+		errFind := errors.New("can't find redactions")
+		repo := new(redactRepoMock)
+		repo.On("Find", mock.Anything).Return(errFind)
+
+		_, err := Redact(ctx, req, repo, &RedactStorage{Local: fs.NewStorage(t.TempDir()), Remote: newRemote()})
+		assert.Equal(errFind, err)
+	})
+}
+
+func TestRedactMetaPath(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("export/enwiki/enwiki_0.json", redactMetaPath("export/enwiki/enwiki_json_0.tar.gz"))
+	assert.Equal("export/enwiki/enwiki_group_1_14.json", redactMetaPath("export/enwiki/enwiki_group_1_json_14.tar.gz"))
+	assert.Equal("snapshot/enwiki/2026-10-18/enwiki_0.json", redactMetaPath("snapshot/enwiki/2026-10-18/enwiki_json_0.tar.gz"))
+	assert.Equal("export/enwiki/enwiki_parquet_0.json", redactMetaPath("export/enwiki/enwiki_parquet_0.parquet"))
+	assert.Equal("diff/2026-10-18/enwiki/enwiki_json_0.json", redactMetaPath("diff/2026-10-18/enwiki/enwiki_json_0.tar.gz"))
+}
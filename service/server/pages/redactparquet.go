@@ -0,0 +1,171 @@
+package pages
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"okapi-data-service/models"
+	"okapi-data-service/schema/v3"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// redactParquetBatch number of rows read from the parquet export at once
+const redactParquetBatch = 1000
+
+// ErrRedactFileNotSeekable parquet file has to be read from the local storage that supports seeking
+var ErrRedactFileNotSeekable = errors.New("redact file is not seekable")
+
+// ErrRedactFileReadOnly original parquet file can't be written to
+var ErrRedactFileReadOnly = errors.New("redact file is read only")
+
+// redactParquetFile read only parquet file in the local storage
+type redactParquetFile struct {
+	io.ReadSeeker
+	io.Closer
+	store *RedactStorage
+	path  string
+}
+
+// Open new handle of the file, parquet reader opens one per column
+func (f *redactParquetFile) Open(_ string) (source.ParquetFile, error) {
+	rc, err := f.store.Local.Get(f.path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rs, ok := rc.(io.ReadSeeker)
+
+	if !ok {
+		_ = rc.Close()
+		return nil, ErrRedactFileNotSeekable
+	}
+
+	return &redactParquetFile{ReadSeeker: rs, Closer: rc, store: f.store, path: f.path}, nil
+}
+
+// Create is not supported, the file is read only
+func (f *redactParquetFile) Create(_ string) (source.ParquetFile, error) {
+	return nil, ErrRedactFileReadOnly
+}
+
+// Read fill the whole buffer unless the file ends, like parquet reader expects
+func (f *redactParquetFile) Read(p []byte) (int, error) {
+	n, err := io.ReadFull(f.ReadSeeker, p)
+
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+
+	return n, err
+}
+
+// Write is not supported, the file is read only
+func (f *redactParquetFile) Write(_ []byte) (int, error) {
+	return 0, ErrRedactFileReadOnly
+}
+
+// redactParquet copy parquet export into local storage without rows that belong to suppressed revisions,
+// parquet export has no files inside of it, so there are no chunks in its manifest
+func redactParquet(store *RedactStorage, dest string, src io.Reader, revs map[int]*models.Redaction) (map[int]bool, []*schema.Chunk, error) {
+	orig := fmt.Sprintf("%s.orig", dest)
+	defer func() {
+		if err := store.Local.Delete(orig); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	if err := redactDownload(store, orig, src); err != nil {
+		return nil, nil, err
+	}
+
+	pf, err := (&redactParquetFile{store: store, path: orig}).Open(orig)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer pf.Close()
+
+	pr, err := reader.NewParquetReader(pf, new(ExportParquetPage), 1)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer pr.ReadStop()
+
+	file, err := store.Local.Create(dest)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if file == nil {
+		return nil, nil, ErrExportFileIsNil
+	}
+
+	defer file.Close()
+
+	pw, err := writer.NewParquetWriterFromWriter(file, new(ExportParquetPage), 1)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pw.RowGroupSize = exportParquetRowGroupSize
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+	found := map[int]bool{}
+
+	for total, read := pr.GetNumRows(), int64(0); read < total; read += redactParquetBatch {
+		size := total - read
+
+		if size > redactParquetBatch {
+			size = redactParquetBatch
+		}
+
+		rows := make([]ExportParquetPage, size)
+
+		if err := pr.Read(&rows); err != nil {
+			return nil, nil, err
+		}
+
+		for i := range rows {
+			if rows[i].Version != nil {
+				if _, ok := revs[int(rows[i].Version.Identifier)]; ok {
+					found[int(rows[i].Version.Identifier)] = true
+					continue
+				}
+			}
+
+			if err := pw.Write(&rows[i]); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return found, nil, pw.WriteStop()
+}
+
+// redactDownload save remote file into local storage
+func redactDownload(store *RedactStorage, path string, src io.Reader) error {
+	file, err := store.Local.Create(path)
+
+	if err != nil {
+		return err
+	}
+
+	if file == nil {
+		return ErrExportFileIsNil
+	}
+
+	defer file.Close()
+
+	_, err = io.Copy(file, src)
+	return err
+}
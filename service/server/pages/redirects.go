@@ -0,0 +1,235 @@
+package pages
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/md5" // #nosec G501
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"okapi-data-service/models"
+	"okapi-data-service/schema/v3"
+	pb "okapi-data-service/server/pages/protos"
+	"time"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/klauspost/pgzip"
+	"github.com/protsack-stephan/dev-toolkit/pkg/repository"
+	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
+)
+
+// redirectsBatch number of redirect records loaded from the database at once
+const redirectsBatch = 10000
+
+// RedirectsStorage storage to build redirects export in and to upload it to
+type RedirectsStorage struct {
+	Dest     string // path to the archive
+	MetaDest string // path to the archive metadata
+	Local    interface {
+		storage.Getter
+		storage.Stater
+		storage.Creator
+		storage.Deleter
+	}
+	Remote storage.Putter
+}
+
+// ExportRedirects bundle redirects of the project into ndjson archive so they can be resolved without calling mediawiki
+func ExportRedirects(ctx context.Context, req *pb.ExportRedirectsRequest, repo repository.Finder, store *RedirectsStorage) (*pb.ExportRedirectsResponse, error) {
+	proj := new(models.Project)
+	err := repo.Find(ctx, proj, func(q *orm.Query) *orm.Query {
+		return q.
+			ColumnExpr("project.*, language.local_name as language__local_name, language.code as language__code").
+			Join("left join languages as language").
+			JoinOn("project.lang = language.code").
+			Where("db_name = ?", req.DbName)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(pb.ExportRedirectsResponse)
+	entry := fmt.Sprintf("tmp/redirects/%s_redirects.ndjson", req.DbName)
+
+	defer func() {
+		if err := store.Local.Delete(entry); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	if err := redirectsEntry(ctx, req, repo, store, proj, entry, res); err != nil {
+		return nil, err
+	}
+
+	if res.Total == 0 {
+		return res, nil
+	}
+
+	defer func() {
+		if err := store.Local.Delete(store.Dest); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	if err := redirectsArchive(store, entry, fmt.Sprintf("%s_redirects.ndjson", req.DbName)); err != nil {
+		return nil, err
+	}
+
+	archive, err := store.Local.Get(store.Dest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer archive.Close()
+
+	// generate body md5 hash while uploading
+	h := md5.New() // #nosec G401
+	if err := store.Remote.Put(store.Dest, io.TeeReader(archive, h)); err != nil {
+		return nil, err
+	}
+
+	info, err := store.Local.Stat(store.Dest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	version := fmt.Sprintf("%x", h.Sum(nil))
+	datetime := time.Now().UTC()
+	meta := schema.Project{
+		Name:         proj.SiteName,
+		Identifier:   proj.DbName,
+		URL:          proj.SiteURL,
+		Version:      &version,
+		DateModified: &datetime,
+		Size: &schema.Size{
+			Value:    math.Round((((float64)(info.Size())/1024)/1024)*100) / 100,
+			UnitText: "MB",
+		},
+	}
+
+	if proj.Language != nil {
+		meta.InLanguage = &schema.Language{
+			Name:       proj.Language.LocalName,
+			Identifier: proj.Language.Code,
+		}
+	}
+
+	data, err := json.Marshal(meta)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res, store.Remote.Put(store.MetaDest, bytes.NewReader(data))
+}
+
+// redirectsEntry write all redirects of the project into local ndjson file
+func redirectsEntry(ctx context.Context, req *pb.ExportRedirectsRequest, repo repository.Finder, store *RedirectsStorage, proj *models.Project, path string, res *pb.ExportRedirectsResponse) error {
+	file, err := store.Local.Create(path)
+
+	if err != nil {
+		return err
+	}
+
+	if file == nil {
+		return ErrExportFileIsNil
+	}
+
+	defer file.Close()
+
+	pointer := 0
+
+	for {
+		reds := []*models.Redirect{}
+		err := repo.Find(ctx, &reds, func(q *orm.Query) *orm.Query {
+			return q.
+				Where("db_name = ? and id > ?", req.DbName, pointer).
+				Order("id asc").
+				Limit(redirectsBatch)
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if len(reds) == 0 {
+			return nil
+		}
+
+		for _, red := range reds {
+			data, err := json.Marshal(red.Schema(proj))
+
+			if err != nil {
+				log.Printf("title: %s, err: %v", red.Title, err)
+				res.Errors++
+				continue
+			}
+
+			if _, err := file.Write(append(data, '\n')); err != nil {
+				return err
+			}
+
+			res.Total++
+		}
+
+		pointer = reds[len(reds)-1].ID
+	}
+}
+
+// redirectsArchive pack local ndjson file into tar.gz archive
+func redirectsArchive(store *RedirectsStorage, path string, name string) error {
+	info, err := store.Local.Stat(path)
+
+	if err != nil {
+		return err
+	}
+
+	entry, err := store.Local.Get(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer entry.Close()
+
+	file, err := store.Local.Create(store.Dest)
+
+	if err != nil {
+		return err
+	}
+
+	if file == nil {
+		return ErrExportFileIsNil
+	}
+
+	defer file.Close()
+
+	gzw := pgzip.NewWriter(file)
+	tarbal := tar.NewWriter(gzw)
+	header := &tar.Header{
+		Name:    name,
+		Size:    info.Size(),
+		Mode:    0766,
+		ModTime: time.Now().UTC(),
+	}
+
+	if err := tarbal.WriteHeader(header); err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(tarbal, entry); err != nil {
+		return err
+	}
+
+	if err := tarbal.Close(); err != nil {
+		return err
+	}
+
+	return gzw.Close()
+}
@@ -0,0 +1,111 @@
+package pages
+
+import (
+	"context"
+	"crypto/md5" // #nosec G501
+	"encoding/json"
+	"errors"
+	"fmt"
+	"okapi-data-service/models"
+	"okapi-data-service/schema/v3"
+	pb "okapi-data-service/server/pages/protos"
+	"strings"
+	"testing"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/protsack-stephan/dev-toolkit/lib/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const redirectsTestDbName = "enwiki"
+
+var redirectsTestDest = fmt.Sprintf("export/%s/%s_json_redirects.tar.gz", redirectsTestDbName, redirectsTestDbName)
+var redirectsTestMetaDest = fmt.Sprintf("export/%s/%s_redirects.json", redirectsTestDbName, redirectsTestDbName)
+
+type redirectsRepoMock struct {
+	mock.Mock
+	reds []*models.Redirect
+}
+
+func (r *redirectsRepoMock) Find(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, _ ...interface{}) error {
+	switch model := model.(type) {
+	case *models.Project:
+		model.DbName = redirectsTestDbName
+		model.SiteName = "Wikipedia"
+		model.SiteURL = "https://en.wikipedia.org"
+	case *[]*models.Redirect:
+		*model = append(*model, r.reds...)
+		r.reds = nil
+	}
+
+	return r.Called(model).Error(0)
+}
+
+func TestExportRedirects(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	req := &pb.ExportRedirectsRequest{DbName: redirectsTestDbName}
+
+	newStorage := func(remote *redactRemoteMock) *RedirectsStorage {
+		return &RedirectsStorage{
+			Dest:     redirectsTestDest,
+			MetaDest: redirectsTestMetaDest,
+			Local:    fs.NewStorage(t.TempDir()),
+			Remote:   remote,
+		}
+	}
+
+	t.Run("export redirects success", func(t *testing.T) {
+		repo := &redirectsRepoMock{
+			reds: []*models.Redirect{
+				{ID: 1, DbName: redirectsTestDbName, Title: "Planet Earth", Target: "Earth"},
+				{ID: 2, DbName: redirectsTestDbName, Title: "Earth orbit", Target: "Earth", Fragment: "Orbit"},
+			},
+		}
+		repo.On("Find", mock.Anything).Return(nil)
+		remote := &redactRemoteMock{files: map[string][]byte{}}
+
+		res, err := ExportRedirects(ctx, req, repo, newStorage(remote))
+		assert.NoError(err)
+		assert.Equal(int32(2), res.Total)
+		assert.Zero(res.Errors)
+
+		lines := strings.Split(strings.TrimSpace(readRedactTestArchive(t, remote.files[redirectsTestDest])), "\n")
+		assert.Len(lines, 2)
+
+		red := new(schema.Redirect)
+		assert.NoError(json.Unmarshal([]byte(lines[1]), red))
+		assert.Equal("Earth orbit", red.Name)
+		assert.Equal("Earth", red.Target.Name)
+		assert.Equal("https://en.wikipedia.org/wiki/Earth", red.Target.URL)
+		assert.Equal("Orbit", red.Fragment)
+		assert.Equal(redirectsTestDbName, red.IsPartOf.Identifier)
+
+		meta := new(schema.Project)
+		assert.NoError(json.Unmarshal(remote.files[redirectsTestMetaDest], meta))
+		assert.Equal(fmt.Sprintf("%x", md5.Sum(remote.files[redirectsTestDest])), *meta.Version) // #nosec G401
+		assert.Equal(redirectsTestDbName, meta.Identifier)
+	})
+
+	t.Run("export redirects empty", func(t *testing.T) {
+		repo := new(redirectsRepoMock)
+		repo.On("Find", mock.Anything).Return(nil)
+		remote := &redactRemoteMock{files: map[string][]byte{}}
+
+		res, err := ExportRedirects(ctx, req, repo, newStorage(remote))
+		assert.NoError(err)
+		assert.Zero(res.Total)
+		assert.Empty(remote.files)
+	})
+
+	t.Run("export redirects find error", func(t *testing.T) {
+		errFind := errors.New("can't find redirects")
+		repo := new(redirectsRepoMock)
+		repo.On("Find", mock.AnythingOfType("*models.Project")).Return(nil)
+		repo.On("Find", &[]*models.Redirect{}).Return(errFind)
+
+		_, err := ExportRedirects(ctx, req, repo, newStorage(&redactRemoteMock{files: map[string][]byte{}}))
+		assert.Equal(errFind, err)
+	})
+}
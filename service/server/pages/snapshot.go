@@ -0,0 +1,91 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	pb "okapi-data-service/server/pages/protos"
+
+	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
+)
+
+// SnapshotsRetention number of dated snapshots kept for the project namespace when request doesn't specify it
+const SnapshotsRetention = 7
+
+type snapshotStorage interface {
+	storage.CopierWithContext
+	storage.Lister
+	storage.Stater
+	storage.Deleter
+}
+
+// snapshotMeta path of the snapshot metadata for the date
+func snapshotMeta(dbName string, ns int32, date string) string {
+	return fmt.Sprintf("snapshot/%s/%s/%s_%d.json", dbName, date, dbName, ns)
+}
+
+// snapshotDest path of the snapshot archive for the date
+func snapshotDest(dbName string, ns int32, date string) string {
+	return fmt.Sprintf("snapshot/%s/%s/%s_%s_%d.tar.gz", dbName, date, dbName, "json", ns)
+}
+
+// Snapshot copies current project export and its metadata into dated snapshot that diffs can reference.
+// e.g., export/enwiki/enwiki_json_0.tar.gz -> snapshot/enwiki/2021-09-01/enwiki_json_0.tar.gz
+// export/enwiki/enwiki_0.json -> snapshot/enwiki/2021-09-01/enwiki_0.json
+// Only the latest `keep` snapshots of the namespace are retained, older ones are deleted.
+func Snapshot(ctx context.Context, req *pb.SnapshotRequest, store snapshotStorage, date string) (*pb.SnapshotResponse, error) {
+	if req.Keep <= 0 {
+		req.Keep = SnapshotsRetention
+	}
+
+	res := new(pb.SnapshotResponse)
+
+	// archive goes first, so the snapshot is listed (by its metadata) only when it can be downloaded
+	copies := [][2]string{
+		{fmt.Sprintf("export/%s/%s_%s_%d.tar.gz", req.DbName, req.DbName, "json", req.Ns), snapshotDest(req.DbName, req.Ns, date)},
+		{fmt.Sprintf("export/%s/%s_%d.json", req.DbName, req.DbName, req.Ns), snapshotMeta(req.DbName, req.Ns, date)},
+	}
+
+	for _, paths := range copies {
+		res.Total++
+
+		if err := store.CopyWithContext(ctx, paths[0], paths[1]); err != nil {
+			res.Errors++
+			return res, err
+		}
+	}
+
+	dates, err := store.List(fmt.Sprintf("snapshot/%s/", req.DbName), map[string]interface{}{"delimiter": "/"})
+
+	if err != nil {
+		return res, err
+	}
+
+	// dates sort as strings, newest first
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+	kept := 0
+
+	for _, snap := range dates {
+		if _, err := store.Stat(snapshotMeta(req.DbName, req.Ns, snap)); err != nil {
+			continue
+		}
+
+		if kept++; kept <= int(req.Keep) {
+			continue
+		}
+
+		for _, path := range []string{snapshotMeta(req.DbName, req.Ns, snap), snapshotDest(req.DbName, req.Ns, snap)} {
+			if err := store.Delete(path); err != nil {
+				res.Errors++
+				log.Printf("path: %s, err: %v", path, err)
+				continue
+			}
+
+			res.Deleted++
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,116 @@
+package pages
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	pb "okapi-data-service/server/pages/protos"
+
+	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+const snapshotTestDbName = "enwiki"
+const snapshotTestDate = "2021-09-08"
+
+// snapshotStorageMock remote storage that keeps the set of existing paths
+type snapshotStorageMock struct {
+	paths   map[string]bool
+	copyErr error
+}
+
+func (s *snapshotStorageMock) CopyWithContext(_ context.Context, src string, dst string, _ ...map[string]interface{}) error {
+	if s.copyErr != nil {
+		return s.copyErr
+	}
+
+	s.paths[dst] = true
+	return nil
+}
+
+func (s *snapshotStorageMock) List(path string, _ ...map[string]interface{}) ([]string, error) {
+	dirs := map[string]bool{}
+
+	for key := range s.paths {
+		if strings.HasPrefix(key, path) {
+			dirs[strings.Split(strings.TrimPrefix(key, path), "/")[0]] = true
+		}
+	}
+
+	list := []string{}
+
+	for dir := range dirs {
+		list = append(list, dir)
+	}
+
+	sort.Strings(list)
+	return list, nil
+}
+
+func (s *snapshotStorageMock) Stat(path string) (storage.FileInfo, error) {
+	if !s.paths[path] {
+		return nil, os.ErrNotExist
+	}
+
+	return nil, nil
+}
+
+func (s *snapshotStorageMock) Delete(path string) error {
+	delete(s.paths, path)
+	return nil
+}
+
+func TestSnapshot(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	t.Run("snapshot with retention", func(t *testing.T) {
+		store := &snapshotStorageMock{paths: map[string]bool{}}
+
+		// older snapshots of the namespace and one of the other namespace
+		for _, date := range []string{"2021-09-05", "2021-09-06", "2021-09-07"} {
+			store.paths[snapshotMeta(snapshotTestDbName, 0, date)] = true
+			store.paths[snapshotDest(snapshotTestDbName, 0, date)] = true
+		}
+
+		store.paths[snapshotMeta(snapshotTestDbName, 6, "2021-09-01")] = true
+
+		res, err := Snapshot(ctx, &pb.SnapshotRequest{DbName: snapshotTestDbName, Keep: 2}, store, snapshotTestDate)
+		assert.NoError(err)
+		assert.Equal(int32(2), res.Total)
+		assert.Equal(int32(4), res.Deleted)
+		assert.Zero(res.Errors)
+		assert.Equal(map[string]bool{
+			"snapshot/enwiki/2021-09-08/enwiki_0.json":        true,
+			"snapshot/enwiki/2021-09-08/enwiki_json_0.tar.gz": true,
+			"snapshot/enwiki/2021-09-07/enwiki_0.json":        true,
+			"snapshot/enwiki/2021-09-07/enwiki_json_0.tar.gz": true,
+			"snapshot/enwiki/2021-09-01/enwiki_6.json":        true,
+		}, store.paths)
+	})
+
+	t.Run("snapshot default retention", func(t *testing.T) {
+		store := &snapshotStorageMock{paths: map[string]bool{}}
+		req := &pb.SnapshotRequest{DbName: snapshotTestDbName}
+
+		res, err := Snapshot(ctx, req, store, snapshotTestDate)
+		assert.NoError(err)
+		assert.Equal(int32(SnapshotsRetention), req.Keep)
+		assert.Zero(res.Deleted)
+		assert.Len(store.paths, 2)
+	})
+
+	t.Run("snapshot copy error", func(t *testing.T) {
+		errCopy := errors.New("copy failed")
+		store := &snapshotStorageMock{paths: map[string]bool{}, copyErr: errCopy}
+
+		res, err := Snapshot(ctx, &pb.SnapshotRequest{DbName: snapshotTestDbName}, store, snapshotTestDate)
+		assert.Equal(errCopy, err)
+		assert.Equal(int32(1), res.Errors)
+		assert.Empty(store.paths)
+	})
+}
@@ -0,0 +1,271 @@
+package pages
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"okapi-data-service/models"
+	"okapi-data-service/pkg/compress"
+	"okapi-data-service/pkg/page"
+	"okapi-data-service/queues/pagefetch"
+	pb "okapi-data-service/server/pages/protos"
+	"sort"
+	"strings"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/go-redis/redis/v8"
+	"github.com/protsack-stephan/dev-toolkit/pkg/repository"
+	"github.com/protsack-stephan/dev-toolkit/pkg/storage"
+)
+
+// verifyBatch number of pages loaded from the database at once
+const verifyBatch = 10000
+
+// verifyMaxIssues max number of issues listed in the response (counters include all of them)
+const verifyMaxIssues = 1000
+
+// ErrVerifyRemote remote storage can't list all the page files
+var ErrVerifyRemote = errors.New("remote storage doesn't support listing of all keys")
+
+// Problems reported by verify
+const (
+	VerifyMissingLocal   = "missing_local"
+	VerifyMissingRemote  = "missing_remote"
+	VerifyOrphanedLocal  = "orphaned_local"
+	VerifyOrphanedRemote = "orphaned_remote"
+	VerifyStale          = "stale"
+)
+
+// VerifyStorage local json storage and remote page storage to compare against the database
+type VerifyStorage struct {
+	Local interface {
+		storage.Getter
+		storage.Walker
+		storage.Deleter
+	}
+	Remote verifyRemote
+}
+
+type verifyRemote interface {
+	page.Walker
+	storage.Deleter
+}
+
+type verifyRepo interface {
+	repository.Finder
+	repository.Updater
+}
+
+type verifyRecord struct {
+	page   *models.Page
+	local  bool
+	remote bool
+}
+
+// Verify compare pages table with local json files and S3 copies of the project,
+// with repair flag orphaned files are deleted and missing or stale pages are sent to the pagefetch queue.
+// Only local files are checked for stale revisions, reading every S3 copy is too slow and costly,
+// both copies are written from the same data by the page storage, so a stale local file is a good sign of a stale S3 one.
+func Verify(ctx context.Context, req *pb.VerifyRequest, repo verifyRepo, store *VerifyStorage, cache redis.Cmdable) (*pb.VerifyResponse, error) {
+	proj := new(models.Project)
+	err := repo.Find(ctx, proj, func(q *orm.Query) *orm.Query {
+		return q.Where("db_name = ?", req.DbName)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	records := map[string]*verifyRecord{}
+	pointer := 0
+
+	for {
+		pages := []*models.Page{}
+		err := repo.Find(ctx, &pages, func(q *orm.Query) *orm.Query {
+			return q.
+				Column("id", "title", "ns_id", "revision", "path").
+				Where("db_name = ? and id > ? and path != ''", req.DbName, pointer).
+				Order("id asc").
+				Limit(verifyBatch)
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(pages) == 0 {
+			break
+		}
+
+		for _, page := range pages {
+			records[page.Path] = &verifyRecord{page: page}
+		}
+
+		pointer = pages[len(pages)-1].ID
+	}
+
+	res := &pb.VerifyResponse{Total: int32(len(records))}
+	refetch := map[string]*models.Page{}
+	report := func(title string, path string, problem string) {
+		if len(res.Issues) < verifyMaxIssues {
+			res.Issues = append(res.Issues, &pb.VerifyIssue{Title: title, Path: path, Problem: problem})
+		}
+	}
+	orphan := func(path string, key string, problem string, deleter storage.Deleter) {
+		res.Orphaned++
+		report(pathTitle(req.DbName, path), path, problem)
+
+		if !req.Repair {
+			return
+		}
+
+		if err := deleter.Delete(key); err != nil {
+			log.Printf("path: %s, err: %v", key, err)
+			res.Errors++
+			return
+		}
+
+		res.Repaired++
+	}
+
+	err = store.Local.Walk(fmt.Sprintf("json/%s", req.DbName), func(path string) {
+		rec, ok := records[path]
+
+		if !ok {
+			orphan(path, path, VerifyOrphanedLocal, store.Local)
+			return
+		}
+
+		rec.local = true
+
+		if rev, err := verifyRevision(store.Local, path); err != nil || rev != rec.page.Revision {
+			res.Stale++
+			report(rec.page.Title, path, VerifyStale)
+			refetch[rec.page.Title] = rec.page
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// orphans are deleted after the listing, so the listing pages don't shift under it
+	orphans := []string{}
+	err = store.Remote.WalkAll(fmt.Sprintf("page/json/%s/", req.DbName), func(key string) {
+		if rec, ok := records[strings.TrimPrefix(key, "page/")]; ok {
+			rec.remote = true
+		} else {
+			orphans = append(orphans, key)
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range orphans {
+		orphan(strings.TrimPrefix(key, "page/"), key, VerifyOrphanedRemote, store.Remote)
+	}
+
+	paths := make([]string, 0, len(records))
+
+	for path := range records {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		rec := records[path]
+
+		if !rec.local {
+			res.Missing++
+			report(rec.page.Title, path, VerifyMissingLocal)
+			refetch[rec.page.Title] = rec.page
+		}
+
+		if !rec.remote {
+			res.Missing++
+			report(rec.page.Title, path, VerifyMissingRemote)
+			refetch[rec.page.Title] = rec.page
+		}
+	}
+
+	if !req.Repair {
+		return res, nil
+	}
+
+	for _, page := range refetch {
+		if err := verifyRefetch(ctx, repo, cache, proj, page); err != nil {
+			log.Printf("title: %s, err: %v", page.Title, err)
+			res.Errors++
+			continue
+		}
+
+		res.Repaired++
+	}
+
+	return res, nil
+}
+
+// verifyRevision get revision of the page stored in the json file
+func verifyRevision(store storage.Getter, path string) (int, error) {
+	file, err := store.Get(path)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer file.Close()
+	rc, err := compress.NewReader(file)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+
+	if err != nil {
+		return 0, err
+	}
+
+	page := new(struct {
+		Version *struct {
+			Identifier int `json:"identifier"`
+		} `json:"version"`
+	})
+
+	if err := json.Unmarshal(data, page); err != nil {
+		return 0, err
+	}
+
+	if page.Version == nil {
+		return 0, nil
+	}
+
+	return page.Version.Identifier, nil
+}
+
+// verifyRefetch drop content hash so the fetch saves the page even if the content didn't change and enqueue the fetch
+func verifyRefetch(ctx context.Context, repo repository.Updater, cache redis.Cmdable, proj *models.Project, page *models.Page) error {
+	_, err := repo.Update(ctx, new(models.Page), func(q *orm.Query) *orm.Query {
+		return q.Set("content_hash = ''").Where("id = ?", page.ID)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return pagefetch.Enqueue(ctx, cache, &pagefetch.Data{
+		Title:     page.Title,
+		Revision:  page.Revision,
+		DbName:    proj.DbName,
+		Lang:      proj.Lang,
+		SiteURL:   proj.SiteURL,
+		Namespace: page.NsID,
+	})
+}
@@ -0,0 +1,156 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"okapi-data-service/models"
+	pb "okapi-data-service/server/pages/protos"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/go-redis/redis/v8"
+	"github.com/protsack-stephan/dev-toolkit/lib/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const verifyTestDbName = "enwiki"
+
+type verifyRepoMock struct {
+	mock.Mock
+	pages []*models.Page
+}
+
+func (r *verifyRepoMock) Find(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, _ ...interface{}) error {
+	switch model := model.(type) {
+	case *models.Project:
+		model.DbName = verifyTestDbName
+		model.Lang = "en"
+		model.SiteURL = "https://en.wikipedia.org"
+	case *[]*models.Page:
+		*model = append(*model, r.pages...)
+		r.pages = nil
+	}
+
+	return r.Called(model).Error(0)
+}
+
+func (r *verifyRepoMock) Update(_ context.Context, model interface{}, _ func(*orm.Query) *orm.Query, _ ...interface{}) (orm.Result, error) {
+	return nil, r.Called(model).Error(0)
+}
+
+type verifyRemoteMock struct {
+	mock.Mock
+	keys []string
+}
+
+func (s *verifyRemoteMock) WalkAll(prefix string, callback func(key string)) error {
+	for _, key := range s.keys {
+		callback(key)
+	}
+
+	return s.Called(prefix).Error(0)
+}
+
+func (s *verifyRemoteMock) Delete(path string) error {
+	return s.Called(path).Error(0)
+}
+
+type verifyCacheMock struct {
+	redis.Cmdable
+	mock.Mock
+}
+
+func (c *verifyCacheMock) RPush(_ context.Context, key string, _ ...interface{}) *redis.IntCmd {
+	c.Called(key)
+	return redis.NewIntCmd(context.Background())
+}
+
+func TestVerify(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	path := func(title string) string {
+		return fmt.Sprintf("json/%s/%s.json", verifyTestDbName, title)
+	}
+
+	newStorage := func(remote *verifyRemoteMock) *VerifyStorage {
+		vol := t.TempDir()
+		loc := filepath.Join(vol, "json", verifyTestDbName)
+		assert.NoError(os.MkdirAll(loc, 0766))
+
+		for title, rev := range map[string]int{"Earth": 1, "Moon": 1, "Mars": 3} {
+			body := fmt.Sprintf(`{"name":"%s","version":{"identifier":%d}}`, title, rev)
+			assert.NoError(ioutil.WriteFile(filepath.Join(loc, fmt.Sprintf("%s.json", title)), []byte(body), 0644))
+		}
+
+		return &VerifyStorage{Local: fs.NewStorage(vol), Remote: remote}
+	}
+
+	newRepo := func() *verifyRepoMock {
+		repo := &verifyRepoMock{
+			pages: []*models.Page{
+				{ID: 1, Title: "Earth", Revision: 1, Path: path("Earth")},
+				{ID: 2, Title: "Moon", Revision: 2, Path: path("Moon")},
+				{ID: 3, Title: "Venus", Revision: 1, NsID: 0, Path: path("Venus")},
+			},
+		}
+		repo.On("Find", mock.AnythingOfType("*models.Project")).Return(nil)
+		repo.On("Find", mock.AnythingOfType("*[]*models.Page")).Return(nil)
+		repo.On("Update", new(models.Page)).Return(nil)
+
+		return repo
+	}
+
+	remoteKeys := []string{
+		fmt.Sprintf("page/%s", path("Earth")),
+		fmt.Sprintf("page/%s", path("Moon")),
+		fmt.Sprintf("page/%s", path("Jupiter")),
+	}
+
+	t.Run("verify report", func(t *testing.T) {
+		repo := newRepo()
+		remote := &verifyRemoteMock{keys: remoteKeys}
+		remote.On("WalkAll", fmt.Sprintf("page/json/%s/", verifyTestDbName)).Return(nil)
+		cache := new(verifyCacheMock)
+
+		res, err := Verify(ctx, &pb.VerifyRequest{DbName: verifyTestDbName}, repo, newStorage(remote), cache)
+		assert.NoError(err)
+		assert.Equal(int32(3), res.Total)
+		assert.Equal(int32(2), res.Missing)
+		assert.Equal(int32(2), res.Orphaned)
+		assert.Equal(int32(1), res.Stale)
+		assert.Zero(res.Repaired)
+		assert.Len(res.Issues, 5)
+		assert.Contains(res.Issues, &pb.VerifyIssue{Title: "Moon", Path: path("Moon"), Problem: VerifyStale})
+		assert.Contains(res.Issues, &pb.VerifyIssue{Title: "Mars", Path: path("Mars"), Problem: VerifyOrphanedLocal})
+		assert.Contains(res.Issues, &pb.VerifyIssue{Title: "Jupiter", Path: path("Jupiter"), Problem: VerifyOrphanedRemote})
+		assert.Contains(res.Issues, &pb.VerifyIssue{Title: "Venus", Path: path("Venus"), Problem: VerifyMissingLocal})
+		assert.Contains(res.Issues, &pb.VerifyIssue{Title: "Venus", Path: path("Venus"), Problem: VerifyMissingRemote})
+		repo.AssertNotCalled(t, "Update", new(models.Page))
+		remote.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("verify repair", func(t *testing.T) {
+		repo := newRepo()
+		remote := &verifyRemoteMock{keys: remoteKeys}
+		remote.On("WalkAll", fmt.Sprintf("page/json/%s/", verifyTestDbName)).Return(nil)
+		remote.On("Delete", fmt.Sprintf("page/%s", path("Jupiter"))).Return(nil)
+		cache := new(verifyCacheMock)
+		cache.On("RPush", "queue/pagefetch")
+		store := newStorage(remote)
+
+		res, err := Verify(ctx, &pb.VerifyRequest{DbName: verifyTestDbName, Repair: true}, repo, store, cache)
+		assert.NoError(err)
+		assert.Equal(int32(4), res.Repaired)
+		assert.Zero(res.Errors)
+		repo.AssertNumberOfCalls(t, "Update", 2)
+		cache.AssertNumberOfCalls(t, "RPush", 2)
+		remote.AssertCalled(t, "Delete", fmt.Sprintf("page/%s", path("Jupiter")))
+
+		_, err = store.Local.Get(path("Mars"))
+		assert.Error(err)
+	})
+}
@@ -12,7 +12,39 @@ import (
 	"github.com/protsack-stephan/mediawiki-api-client"
 )
 
-const partitionQuery = `CREATE TABLE IF NOT EXISTS  "pages_%s" PARTITION OF pages FOR VALUES in ('%s')`
+const partitionQuery = `CREATE TABLE IF NOT EXISTS  "%s_%s" PARTITION OF %s FOR VALUES in ('%s')`
+
+// partitionTables list of the tables partitioned by project
+var partitionTables = []string{"pages", "revisions"}
+
+// licenseCCBYSA license of the most of the project families
+var licenseCCBYSA = models.License{
+	Name:       "Creative Commons Attribution Share Alike 4.0 International",
+	Identifier: "CC-BY-SA-4.0",
+	URL:        "https://creativecommons.org/licenses/by-sa/4.0/",
+}
+
+// familyLicenses licenses by site code (project family),
+// projects of the families that are not listed are left without license until it's configured
+var familyLicenses = map[string]models.License{
+	"wiki":        licenseCCBYSA,
+	"wiktionary":  licenseCCBYSA,
+	"wikibooks":   licenseCCBYSA,
+	"wikiquote":   licenseCCBYSA,
+	"wikisource":  licenseCCBYSA,
+	"wikiversity": licenseCCBYSA,
+	"wikivoyage":  licenseCCBYSA,
+	"wikinews": {
+		Name:       "Attribution 2.5 Generic",
+		Identifier: "CC-BY-2.5",
+		URL:        "https://creativecommons.org/licenses/by/2.5/",
+	},
+	"wikidata": {
+		Name:       "Creative Commons Zero v1.0 Universal",
+		Identifier: "CC0-1.0",
+		URL:        "https://creativecommons.org/publicdomain/zero/1.0/",
+	},
+}
 
 type fetchRepo interface {
 	repository.SelectOrCreator
@@ -72,10 +104,23 @@ func Fetch(ctx context.Context, req *pb.FetchRequest, mWiki *mediawiki.Client, r
 				return nil, err
 			}
 
-			_, err = repo.Exec(ctx, fmt.Sprintf(partitionQuery, project.DbName, project.DbName))
+			if license, ok := familyLicenses[project.SiteCode]; ok {
+				license.DbName = project.DbName
+				_, err = repo.SelectOrCreate(ctx, &license, func(q *orm.Query) *orm.Query {
+					return q.Where("db_name = ? and ns is null", project.DbName)
+				})
 
-			if err != nil {
-				return nil, err
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			for _, table := range partitionTables {
+				_, err = repo.Exec(ctx, fmt.Sprintf(partitionQuery, table, project.DbName, table, project.DbName))
+
+				if err != nil {
+					return nil, err
+				}
 			}
 		}
 	}
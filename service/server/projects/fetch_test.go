@@ -41,7 +41,7 @@ func (r *fetchRepoMock) Exec(_ context.Context, query string, _ ...interface{})
 	return nil, r.Called(query).Error(0)
 }
 
-func createTestProjectsServer() http.Handler {
+func createTestProjectsServer(siteCode string) http.Handler {
 	router := http.NewServeMux()
 
 	router.HandleFunc(fetchTestSitematrixURL, func(w http.ResponseWriter, r *http.Request) {
@@ -50,7 +50,7 @@ func createTestProjectsServer() http.Handler {
 			fetchTestLangName,
 			fetchTestSiteURL,
 			fetchTestDbName,
-			fetchTestSiteCode,
+			siteCode,
 			fetchTestSiteName,
 			!fetchTestActive,
 			fetchTestLangDir,
@@ -70,11 +70,15 @@ func createTestMWikiClient(url string) *mediawiki.Client {
 }
 
 func TestFetch(t *testing.T) {
-	srv := httptest.NewServer(createTestProjectsServer())
+	srv := httptest.NewServer(createTestProjectsServer(fetchTestSiteCode))
 	defer srv.Close()
 
 	repo := new(fetchRepoMock)
-	repo.On("Exec", fmt.Sprintf(partitionQuery, fetchTestDbName, fetchTestDbName)).Return(nil)
+
+	for _, table := range partitionTables {
+		repo.On("Exec", fmt.Sprintf(partitionQuery, table, fetchTestDbName, table, fetchTestDbName)).Return(nil)
+	}
+
 	repo.On("SelectOrCreate", &models.Project{
 		DbName:   fetchTestDbName,
 		Lang:     fetchTestLangCode,
@@ -83,6 +87,9 @@ func TestFetch(t *testing.T) {
 		SiteCode: fetchTestSiteCode,
 		SiteName: fetchTestSiteName,
 	}).Return(true, nil)
+	license := familyLicenses[fetchTestSiteCode]
+	license.DbName = fetchTestDbName
+	repo.On("SelectOrCreate", &license).Return(true, nil)
 	repo.On("SelectOrCreate", &models.Language{
 		Code:      fetchTestLangCode,
 		Name:      fetchTestLangName,
@@ -90,6 +97,25 @@ func TestFetch(t *testing.T) {
 		LocalName: fetchTestLangLocalName,
 	}).Return(true, nil)
 
+	_, err := Fetch(
+		context.Background(),
+		new(pb.FetchRequest),
+		createTestMWikiClient(srv.URL),
+		repo)
+	assert.NoError(t, err)
+	repo.AssertNumberOfCalls(t, "SelectOrCreate", 3)
+	repo.AssertNumberOfCalls(t, "Exec", len(partitionTables))
+}
+
+func TestFetchUnknownFamily(t *testing.T) {
+	srv := httptest.NewServer(createTestProjectsServer("wikifoo"))
+	defer srv.Close()
+
+	repo := new(fetchRepoMock)
+	repo.On("Exec", mock.Anything).Return(nil)
+	repo.On("SelectOrCreate", mock.AnythingOfType("*models.Project")).Return(true, nil)
+	repo.On("SelectOrCreate", mock.AnythingOfType("*models.Language")).Return(true, nil)
+
 	_, err := Fetch(
 		context.Background(),
 		new(pb.FetchRequest),
@@ -97,5 +123,5 @@ func TestFetch(t *testing.T) {
 		repo)
 	assert.NoError(t, err)
 	repo.AssertNumberOfCalls(t, "SelectOrCreate", 2)
-	repo.AssertNumberOfCalls(t, "Exec", 1)
+	repo.AssertNotCalled(t, "SelectOrCreate", mock.AnythingOfType("*models.License"))
 }
@@ -40,7 +40,7 @@ func createProjectDialer(url string) func(context.Context, string) (net.Conn, er
 
 func TestProjects(t *testing.T) {
 	assert := assert.New(t)
-	srv := httptest.NewServer(createTestProjectsServer())
+	srv := httptest.NewServer(createTestProjectsServer(fetchTestSiteCode))
 	defer srv.Close()
 
 	ctx := context.Background()
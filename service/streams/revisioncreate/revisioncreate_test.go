@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io/ioutil"
 	"log"
+	"okapi-data-service/pkg/editors"
 	"okapi-data-service/queues/pagefetch"
 	"okapi-data-service/schema/v3"
 	"okapi-data-service/streams/utils"
@@ -95,16 +96,32 @@ func TestRevisioncreate(t *testing.T) {
 	t.Run("revisioncreate success", func(t *testing.T) {
 		cmdable := new(revisioncreateRedisMock)
 		cmdable.On("RPush", revisioncreateTestQueueName, data).Return(nil)
+		cmdable.On("Set", editors.Key(revisioncreateTestDbName, revisioncreateTestUserID), mock.Anything, editors.Expire).Return(nil)
 		cmdable.On("Set", revisioncreateTestName, date, revisioncreateTestExpire).Return(nil)
 
 		Handler(ctx, cmdable, revisioncreateTestExpire)(evt)
 		cmdable.AssertCalled(t, "RPush", revisioncreateTestQueueName, data)
 		cmdable.AssertCalled(t, "Set", revisioncreateTestName, date, revisioncreateTestExpire)
+		cmdable.AssertCalled(t, "Set", editors.Key(revisioncreateTestDbName, revisioncreateTestUserID), mock.Anything, editors.Expire)
+	})
+
+	t.Run("revisioncreate redirect", func(t *testing.T) {
+		cmdable := new(revisioncreateRedisMock)
+		cmdable.On("RPush", revisioncreateTestQueueName, data).Return(nil)
+		cmdable.On("Set", editors.Key(revisioncreateTestDbName, revisioncreateTestUserID), mock.Anything, editors.Expire).Return(nil)
+		cmdable.On("Set", revisioncreateTestName, date, revisioncreateTestExpire).Return(nil)
+
+		redirect := *evt
+		redirect.Data.PageIsRedirect = true
+
+		Handler(ctx, cmdable, revisioncreateTestExpire)(&redirect)
+		cmdable.AssertCalled(t, "RPush", revisioncreateTestQueueName, data)
 	})
 
 	t.Run("revisioncreate push error", func(t *testing.T) {
 		cmdable := new(revisioncreateRedisMock)
 		cmdable.On("RPush", revisioncreateTestQueueName, data).Return(errors.New("redis not available"))
+		cmdable.On("Set", editors.Key(revisioncreateTestDbName, revisioncreateTestUserID), mock.Anything, editors.Expire).Return(nil)
 		cmdable.On("Set", revisioncreateTestName, date, revisioncreateTestExpire).Return(nil)
 
 		Handler(ctx, cmdable, revisioncreateTestExpire)(evt)
@@ -115,6 +132,7 @@ func TestRevisioncreate(t *testing.T) {
 	t.Run("revisioncreate set error", func(t *testing.T) {
 		cmdable := new(revisioncreateRedisMock)
 		cmdable.On("RPush", revisioncreateTestQueueName, data).Return(nil)
+		cmdable.On("Set", editors.Key(revisioncreateTestDbName, revisioncreateTestUserID), mock.Anything, editors.Expire).Return(nil)
 		cmdable.On("Set", revisioncreateTestName, date, revisioncreateTestExpire).Return(errors.New("offline"))
 
 		Handler(ctx, cmdable, revisioncreateTestExpire)(evt)
@@ -3,6 +3,7 @@ package revisionscore
 import (
 	"context"
 	"log"
+	"okapi-data-service/pkg/editors"
 	"okapi-data-service/queues/pagefetch"
 	"okapi-data-service/schema/v3"
 	"okapi-data-service/streams/utils"
@@ -22,7 +23,7 @@ func Handler(ctx context.Context, store redis.Cmdable, expire time.Duration) fun
 	return func(evt *eventstream.RevisionScore) {
 		var err error
 
-		if !evt.Data.PageIsRedirect && ores.ModelDamaging.Supports(evt.Data.Database) && !utils.Exclude(evt.Data.Database) && utils.FilterNs(evt.Data.PageNamespace) {
+		if ores.ModelDamaging.Supports(evt.Data.Database) && !utils.Exclude(evt.Data.Database) && utils.FilterNs(evt.Data.PageNamespace) {
 			editor := &schema.Editor{
 				Identifier: evt.Data.Performer.UserID,
 				Name:       evt.Data.Performer.UserText,
@@ -35,6 +36,10 @@ func Handler(ctx context.Context, store redis.Cmdable, expire time.Duration) fun
 				editor.DateStarted = &evt.Data.Performer.UserRegistrationDt
 			}
 
+			if err := editors.Set(ctx, store, evt.Data.Database, editor); err != nil {
+				log.Printf("%s: %v\n", Name, err)
+			}
+
 			data := &pagefetch.Data{
 				Title:     evt.Data.PageTitle,
 				DbName:    evt.Data.Database,
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io/ioutil"
 	"log"
+	"okapi-data-service/pkg/editors"
 	"okapi-data-service/queues/pagefetch"
 	"okapi-data-service/schema/v3"
 	"okapi-data-service/streams/utils"
@@ -128,16 +129,19 @@ func TestRevisionscore(t *testing.T) {
 	t.Run("revisionscore success", func(t *testing.T) {
 		cmdable := new(revisionscoreRedisMock)
 		cmdable.On("RPush", revisionscoreTestQueueName, data).Return(nil)
+		cmdable.On("Set", editors.Key(revisionscoreTestDbName, revisionscoreTestUserID), mock.Anything, editors.Expire).Return(nil)
 		cmdable.On("Set", revisionscoreTestName, date, revisionscoreTestExpire).Return(nil)
 
 		Handler(ctx, cmdable, revisionscoreTestExpire)(evt)
 		cmdable.AssertCalled(t, "RPush", revisionscoreTestQueueName, data)
 		cmdable.AssertCalled(t, "Set", revisionscoreTestName, date, revisionscoreTestExpire)
+		cmdable.AssertCalled(t, "Set", editors.Key(revisionscoreTestDbName, revisionscoreTestUserID), mock.Anything, editors.Expire)
 	})
 
 	t.Run("revisionscore push error", func(t *testing.T) {
 		cmdable := new(revisionscoreRedisMock)
 		cmdable.On("RPush", revisionscoreTestQueueName, data).Return(errors.New("redis not available"))
+		cmdable.On("Set", editors.Key(revisionscoreTestDbName, revisionscoreTestUserID), mock.Anything, editors.Expire).Return(nil)
 		cmdable.On("Set", revisionscoreTestName, date, revisionscoreTestExpire).Return(nil)
 
 		Handler(ctx, cmdable, revisionscoreTestExpire)(evt)
@@ -148,6 +152,7 @@ func TestRevisionscore(t *testing.T) {
 	t.Run("revisionscore set error", func(t *testing.T) {
 		cmdable := new(revisionscoreRedisMock)
 		cmdable.On("RPush", revisionscoreTestQueueName, data).Return(nil)
+		cmdable.On("Set", editors.Key(revisionscoreTestDbName, revisionscoreTestUserID), mock.Anything, editors.Expire).Return(nil)
 		cmdable.On("Set", revisionscoreTestName, date, revisionscoreTestExpire).Return(errors.New("offline"))
 
 		Handler(ctx, cmdable, revisionscoreTestExpire)(evt)
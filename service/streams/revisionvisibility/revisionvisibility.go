@@ -3,6 +3,7 @@ package revisionvisibility
 import (
 	"context"
 	"log"
+	"okapi-data-service/pkg/editors"
 	"okapi-data-service/queues/pagevisibility"
 	"okapi-data-service/schema/v3"
 	"okapi-data-service/streams/utils"
@@ -34,6 +35,10 @@ func Handler(ctx context.Context, store redis.Cmdable, expire time.Duration) fun
 				editor.DateStarted = &evt.Data.Performer.UserRegistrationDt
 			}
 
+			if err := editors.Set(ctx, store, evt.Data.Database, editor); err != nil {
+				log.Printf("%s: %v\n", Name, err)
+			}
+
 			err = pagevisibility.Enqueue(ctx, store, &pagevisibility.Data{
 				ID:         evt.Data.PageID,
 				Title:      evt.Data.PageTitle,
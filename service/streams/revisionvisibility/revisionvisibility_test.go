@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"okapi-data-service/pkg/editors"
 	"okapi-data-service/queues/pagevisibility"
 	"okapi-data-service/schema/v3"
 	"testing"
@@ -108,16 +109,19 @@ func TestRevisionvisibility(t *testing.T) {
 	t.Run("revisionvisibility success", func(t *testing.T) {
 		cmdable := new(revisionvisibilityRedisMock)
 		cmdable.On("RPush", revisionvisibilityTestQueueName, data).Return(nil)
+		cmdable.On("Set", editors.Key(revisionvisibilityTestDbName, revisionvisibilityTestUserID), mock.Anything, editors.Expire).Return(nil)
 		cmdable.On("Set", revisionvisibilityTestName, date, revisionvisibilityTestExpire).Return(nil)
 
 		Handler(ctx, cmdable, revisionvisibilityTestExpire)(evt)
 		cmdable.AssertCalled(t, "RPush", revisionvisibilityTestQueueName, data)
 		cmdable.AssertCalled(t, "Set", revisionvisibilityTestName, date, revisionvisibilityTestExpire)
+		cmdable.AssertCalled(t, "Set", editors.Key(revisionvisibilityTestDbName, revisionvisibilityTestUserID), mock.Anything, editors.Expire)
 	})
 
 	t.Run("revisionvisibility push error", func(t *testing.T) {
 		cmdable := new(revisionvisibilityRedisMock)
 		cmdable.On("RPush", revisionvisibilityTestQueueName, data).Return(errors.New("redis not available"))
+		cmdable.On("Set", editors.Key(revisionvisibilityTestDbName, revisionvisibilityTestUserID), mock.Anything, editors.Expire).Return(nil)
 		cmdable.On("Set", revisionvisibilityTestName, date, revisionvisibilityTestExpire).Return(nil)
 
 		Handler(ctx, cmdable, revisionvisibilityTestExpire)(evt)
@@ -128,6 +132,7 @@ func TestRevisionvisibility(t *testing.T) {
 	t.Run("revisionvisibility set error", func(t *testing.T) {
 		cmdable := new(revisionvisibilityRedisMock)
 		cmdable.On("RPush", revisionvisibilityTestQueueName, data).Return(nil)
+		cmdable.On("Set", editors.Key(revisionvisibilityTestDbName, revisionvisibilityTestUserID), mock.Anything, editors.Expire).Return(nil)
 		cmdable.On("Set", revisionvisibilityTestName, date, revisionvisibilityTestExpire).Return(errors.New("offline"))
 
 		Handler(ctx, cmdable, revisionvisibilityTestExpire)(evt)